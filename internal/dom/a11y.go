@@ -0,0 +1,508 @@
+package dom
+
+import "strings"
+
+// Politeness tells screen readers how urgently to read an announcement
+type Politeness string
+
+// Politeness levels. Polite announcements wait until the screen reader is
+// idle; assertive ones interrupt it and suit errors only
+const (
+	Polite    Politeness = "polite"
+	Assertive Politeness = "assertive"
+)
+
+// announceDelay is how long in milliseconds a live region stays empty
+// before a message is placed in it. Screen readers only read changes, so
+// clearing the region first makes a repeated message heard again
+const announceDelay = 100
+
+// visuallyHidden hides an element from sight but not from screen readers
+const visuallyHidden = "position: absolute; width: 1px; height: 1px; margin: -1px; padding: 0; " +
+	"overflow: hidden; clip: rect(0 0 0 0); white-space: nowrap; border: 0;"
+
+// Announcer reads messages to screen reader users through ARIA live
+// regions, visually hidden elements whose changes are spoken aloud:
+//
+//	dom.Announce("Todo added", dom.Polite)
+//
+// The regions are appended to the body on first use
+type Announcer struct {
+	regions map[Politeness]Element
+	timers  map[Politeness]*Timer
+}
+
+// NewAnnouncer creates an announcer with its own live regions
+func NewAnnouncer() *Announcer {
+	return &Announcer{regions: map[Politeness]Element{}, timers: map[Politeness]*Timer{}}
+}
+
+// defaultAnnouncer backs Announce
+var defaultAnnouncer = NewAnnouncer()
+
+// Announce reads message to screen reader users through the default announcer
+func Announce(message string, politeness Politeness) {
+	defaultAnnouncer.Announce(message, politeness)
+}
+
+// Announce reads message to screen reader users. A message announced before
+// the previous one of the same politeness was placed replaces it
+func (a *Announcer) Announce(message string, politeness Politeness) {
+	if politeness != Assertive {
+		politeness = Polite
+	}
+
+	region := a.region(politeness)
+	region.SetText("")
+
+	a.timers[politeness].Clear()
+	a.timers[politeness] = GetWindow().SetTimeout(func() {
+		region.SetText(message)
+	}, announceDelay)
+}
+
+// region returns the live region for politeness, creating it when it is
+// missing or was removed from the document
+func (a *Announcer) region(politeness Politeness) Element {
+	if region, ok := a.regions[politeness]; ok && region.El.Get("isConnected").Bool() {
+		return region
+	}
+
+	role := "status"
+	if politeness == Assertive {
+		role = "alert"
+	}
+
+	region := Document().CreateElement("div")
+	region.SetAttribute("role", role)
+	region.SetAttribute("aria-live", string(politeness))
+	region.SetAttribute("aria-atomic", "true")
+	region.SetAttribute("style", visuallyHidden)
+	Document().QuerySelector("body").AppendChild(region)
+
+	a.regions[politeness] = region
+	return region
+}
+
+// focusableSelector matches the elements that can take focus from the
+// keyboard, before hidden, disabled and inert ones are left out
+const focusableSelector = `a[href], area[href], button:not([disabled]), input:not([disabled]):not([type="hidden"]), ` +
+	`select:not([disabled]), textarea:not([disabled]), iframe, [contenteditable], [tabindex]:not([tabindex="-1"])`
+
+// Focusable returns the elements inside container that Tab moves through,
+// in document order. Radio buttons count once per group, as the checked one
+// when there is one
+func Focusable(container Element) []Element {
+	var elements []Element
+	for _, element := range container.QuerySelectorAll(focusableSelector) {
+		if element.GetAttribute("tabindex") == "-1" || element.Closest("[hidden], [inert]").Exists() || !visible(element) {
+			continue
+		}
+
+		if element.GetAttribute("type") == "radio" && !element.El.Get("checked").Bool() {
+			name := element.GetAttribute("name")
+			if name != "" && container.QuerySelector(`input[type="radio"][name="`+name+`"]:checked`).Exists() {
+				continue
+			}
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// visible reports whether the element is rendered, for browsers that can
+// tell. Elements are taken as visible otherwise
+func visible(element Element) bool {
+	if element.El.Get("checkVisibility").Type() != TypeFunction {
+		return true
+	}
+	return element.El.Call("checkVisibility", map[string]interface{}{"visibilityProperty": true}).Bool()
+}
+
+// ActiveElement returns the element that has focus, or an empty Element
+// when nothing but the body has
+func ActiveElement() Element {
+	active := wrapElement(Global().Get("document").Get("activeElement"))
+	if active.Exists() && active.El.Equal(Global().Get("document").Get("body")) {
+		return Element{}
+	}
+	return active
+}
+
+// FocusTrap keeps keyboard focus inside a dialog or panel while it is open.
+// Tab and Shift+Tab wrap around the focusable elements of the container and
+// focus moved outside, by a click or a script, is brought back. Once
+// deactivated, focus returns to where it was before:
+//
+//	trap := dom.NewFocusTrap(panel)
+//	trap.Activate()   // when the panel opens
+//	trap.Deactivate() // when it closes
+//
+// Traps nest: activating a trap pauses the active one until it is deactivated
+type FocusTrap struct {
+	// Initial matches the element focused on activation, the first focusable
+	// element by default. The container itself is focused when it has none
+	Initial string
+
+	container Element
+	previous  Element // focused before activation
+	scope     *Scope
+}
+
+// focusTraps are the active traps, the innermost last
+var focusTraps []*FocusTrap
+
+// NewFocusTrap creates an inactive trap for container. Releasing the
+// container deactivates the trap
+func NewFocusTrap(container Element) *FocusTrap {
+	t := &FocusTrap{container: container}
+	container.Scope().OnRelease(t.Deactivate)
+	return t
+}
+
+// Active reports whether the trap is active
+func (t *FocusTrap) Active() bool {
+	return t.scope != nil
+}
+
+// Activate moves focus into the container and keeps it there
+func (t *FocusTrap) Activate() {
+	if t.Active() || !t.container.Exists() {
+		return
+	}
+
+	t.previous = ActiveElement()
+	focusTraps = append(focusTraps, t)
+
+	document := Global().Get("document")
+	t.scope = NewScope()
+	t.scope.Listen(newListener(document, "keydown", func(value Value) {
+		event := Event{EventObj: value}
+		if t.paused() || event.Keyboard().Key() != "Tab" {
+			return
+		}
+		t.tab(event)
+	}))
+	t.scope.Listen(newListener(document, "focusin", func(value Value) {
+		target := Event{EventObj: value}.Target()
+		if !t.paused() && target.Exists() && !t.container.El.Call("contains", target.El).Bool() {
+			t.focusFirst()
+		}
+	}))
+
+	if t.Initial != "" {
+		if initial := t.container.QuerySelector(t.Initial); initial.Exists() {
+			initial.Focus()
+			return
+		}
+	}
+	t.focusFirst()
+}
+
+// Deactivate stops trapping focus and focuses the element that had focus
+// before activation, if it is still in the document
+func (t *FocusTrap) Deactivate() {
+	if !t.Active() {
+		return
+	}
+
+	t.scope.Release()
+	t.scope = nil
+	for i, trap := range focusTraps {
+		if trap == t {
+			focusTraps = append(focusTraps[:i:i], focusTraps[i+1:]...)
+			break
+		}
+	}
+
+	previous := t.previous
+	t.previous = Element{}
+	if previous.Exists() && previous.El.Get("isConnected").Bool() {
+		previous.Focus()
+	}
+}
+
+// paused reports whether a trap activated later is in charge
+func (t *FocusTrap) paused() bool {
+	return len(focusTraps) > 0 && focusTraps[len(focusTraps)-1] != t
+}
+
+// tab wraps focus around the ends of the container
+func (t *FocusTrap) tab(event Event) {
+	elements := Focusable(t.container)
+	if len(elements) == 0 {
+		event.PreventDefault()
+		t.focusContainer()
+		return
+	}
+
+	first, last := elements[0], elements[len(elements)-1]
+	active := ActiveElement()
+	inside := active.Exists() && t.container.El.Call("contains", active.El).Bool()
+
+	if event.Keyboard().ShiftKey() {
+		if !inside || active.El.Equal(first.El) || active.El.Equal(t.container.El) {
+			event.PreventDefault()
+			last.Focus()
+		}
+	} else if !inside || active.El.Equal(last.El) {
+		event.PreventDefault()
+		first.Focus()
+	}
+}
+
+// focusFirst focuses the first focusable element, or the container
+func (t *FocusTrap) focusFirst() {
+	if elements := Focusable(t.container); len(elements) > 0 {
+		elements[0].Focus()
+		return
+	}
+	t.focusContainer()
+}
+
+// focusContainer focuses the container itself, making it focusable by
+// scripts first if needed
+func (t *FocusTrap) focusContainer() {
+	if !t.container.HasAttribute("tabindex") {
+		t.container.SetAttribute("tabindex", "-1")
+	}
+	t.container.Focus()
+}
+
+// FocusState records the focused element inside a part of the page so that
+// focus can be put back after the part is rendered again. Elements are found
+// again by identity when they survived, and otherwise through the nearest id
+// or key attribute, which rendered lists carry
+type FocusState struct {
+	root     Element
+	element  Element
+	anchor   string // selector of the nearest element with an id or key
+	relative string // selector of the element inside the anchor, if not the anchor
+	start    Value  // selection of a text control
+	end      Value
+}
+
+// focusKey is the attribute identifying list items, as used by the reconciler
+const focusKey = "data-id"
+
+// SaveFocus records the focused element if it is inside root. It returns
+// nil otherwise
+func SaveFocus(root Element) *FocusState {
+	active := ActiveElement()
+	if !root.Exists() || !active.Exists() || !root.El.Call("contains", active.El).Bool() {
+		return nil
+	}
+
+	state := &FocusState{root: root, element: active}
+	if kind := active.GetAttribute("type"); active.El.Get("tagName").String() == "TEXTAREA" ||
+		active.El.Get("tagName").String() == "INPUT" && (kind == "" || kind == "text" || kind == "search") {
+		state.start = active.El.Get("selectionStart")
+		state.end = active.El.Get("selectionEnd")
+	}
+
+	for element := active; element.Exists(); element = wrapElement(element.El.Get("parentElement")) {
+		if id := element.GetAttribute("id"); id != "" {
+			state.anchor = "#" + id
+		} else if key := element.GetAttribute(focusKey); key != "" {
+			state.anchor = strings.ToLower(element.El.Get("tagName").String()) + "[" + focusKey + `="` + key + `"]`
+		}
+		if state.anchor != "" {
+			if !element.El.Equal(active.El) {
+				state.relative = elementSelector(active)
+			}
+			break
+		}
+		if element.El.Equal(root.El) {
+			break
+		}
+	}
+	return state
+}
+
+// elementSelector describes element by its tag and classes
+func elementSelector(element Element) string {
+	selector := strings.ToLower(element.El.Get("tagName").String())
+	for _, class := range strings.Fields(element.GetAttribute("class")) {
+		selector += "." + class
+	}
+	return selector
+}
+
+// Restore focuses the recorded element again, or the element that took its
+// place, if focus was lost since. It reports whether the recorded element or
+// its replacement has focus
+func (f *FocusState) Restore() bool {
+	if f == nil {
+		return false
+	}
+
+	if active := ActiveElement(); active.Exists() {
+		return active.El.Equal(f.element.El)
+	}
+
+	target := f.element
+	if !target.El.Get("isConnected").Bool() {
+		if f.anchor == "" {
+			return false
+		}
+		target = f.root.QuerySelector(f.anchor)
+		if !target.Exists() && f.root.Matches(f.anchor) {
+			target = f.root
+		}
+		if f.relative != "" {
+			target = target.QuerySelector(f.relative)
+		}
+	}
+	if !target.Exists() {
+		return false
+	}
+
+	target.Focus()
+	if f.start != nil && f.start.Type() == TypeNumber {
+		target.El.Call("setSelectionRange", f.start, f.end)
+	}
+	return true
+}
+
+// RovingOptions configures a RovingTabindex. Empty fields use the defaults
+type RovingOptions struct {
+	// Key is the attribute identifying an item across renders, "data-id" by
+	// default
+	Key string
+	// Orientation is the direction of the arrow keys that move focus,
+	// "vertical" (the default), "horizontal" or "both"
+	Orientation string
+	// Wrap moves focus from the last item to the first and back
+	Wrap bool
+}
+
+// RovingTabindex makes a list a single stop in the Tab order: only the
+// current item has tabindex 0 and the others -1, while the arrow keys, Home
+// and End move focus between the items and make the focused one current.
+// Items added later are kept in step, so the list may re-render freely. Keys
+// pressed with a modifier or inside a control of an item are left alone
+type RovingTabindex struct {
+	container Element
+	selector  string
+	options   RovingOptions
+	current   string // key of the current item
+	scope     *Scope
+	observer  *MutationObserver
+}
+
+// NewRovingTabindex manages the tab stops of the items of container matching
+// selector. The first item is current until another one is focused
+func NewRovingTabindex(container Element, selector string, options RovingOptions) *RovingTabindex {
+	if options.Key == "" {
+		options.Key = "data-id"
+	}
+	if options.Orientation == "" {
+		options.Orientation = "vertical"
+	}
+
+	r := &RovingTabindex{container: container, selector: selector, options: options, scope: NewScope()}
+
+	r.scope.Listen(container.On("focusin", selector, func(item Element, _ Event) {
+		r.current = item.GetAttribute(r.options.Key)
+		r.Refresh()
+	}))
+	r.scope.Listen(container.On("keydown", selector, r.keydown))
+
+	r.observer = NewMutationObserver(func([]MutationRecord, *MutationObserver) {
+		r.Refresh()
+	})
+	r.observer.Observe(container, MutationObserverOptions{ChildList: true, Subtree: true})
+	r.scope.OnRelease(r.observer.Disconnect)
+	container.Scope().OnRelease(r.Release)
+
+	r.Refresh()
+	return r
+}
+
+// Current returns the item in the Tab order, or an empty Element when the
+// list is empty
+func (r *RovingTabindex) Current() Element {
+	items := r.container.QuerySelectorAll(r.selector)
+	for _, item := range items {
+		if item.GetAttribute(r.options.Key) == r.current {
+			return item
+		}
+	}
+	if len(items) > 0 {
+		return items[0]
+	}
+	return Element{}
+}
+
+// Focus makes item current and focuses it
+func (r *RovingTabindex) Focus(item Element) {
+	if !item.Exists() {
+		return
+	}
+	r.current = item.GetAttribute(r.options.Key)
+	r.Refresh()
+	item.Focus()
+}
+
+// Refresh gives the current item tabindex 0 and every other item -1. It
+// runs by itself when items are added or removed
+func (r *RovingTabindex) Refresh() {
+	current := r.Current()
+	for _, item := range r.container.QuerySelectorAll(r.selector) {
+		tabindex := "-1"
+		if item.El.Equal(current.El) {
+			tabindex = "0"
+		}
+		if item.GetAttribute("tabindex") != tabindex {
+			item.SetAttribute("tabindex", tabindex)
+		}
+	}
+}
+
+// Release stops managing the tab stops
+func (r *RovingTabindex) Release() {
+	r.scope.Release()
+}
+
+// keydown moves focus with the arrow keys, Home and End
+func (r *RovingTabindex) keydown(item Element, event Event) {
+	keyboard := event.Keyboard()
+	if keyboard.AltKey() || keyboard.CtrlKey() || keyboard.MetaKey() || keyboard.ShiftKey() || !event.Target().El.Equal(item.El) {
+		return
+	}
+
+	items := r.container.QuerySelectorAll(r.selector)
+	index := -1
+	for i, candidate := range items {
+		if candidate.El.Equal(item.El) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return
+	}
+
+	vertical := r.options.Orientation != "horizontal"
+	horizontal := r.options.Orientation != "vertical"
+	next := index
+	switch key := keyboard.Key(); {
+	case key == "ArrowDown" && vertical, key == "ArrowRight" && horizontal:
+		next++
+	case key == "ArrowUp" && vertical, key == "ArrowLeft" && horizontal:
+		next--
+	case key == "Home":
+		next = 0
+	case key == "End":
+		next = len(items) - 1
+	default:
+		return
+	}
+	event.PreventDefault()
+
+	if r.options.Wrap {
+		next = (next + len(items)) % len(items)
+	} else if next < 0 || next >= len(items) {
+		return
+	}
+	r.Focus(items[next])
+}
@@ -0,0 +1,201 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"testing"
+	"time"
+)
+
+// newRovingList fills a fresh container with one item per key and manages
+// its tab stops
+func newRovingList(t *testing.T, options RovingOptions, keys ...string) *RovingTabindex {
+	t.Helper()
+	list := newContainer(t)
+	for _, key := range keys {
+		list.AppendChild(Document().CreateElement("li").SetAttribute("data-id", key).SetText(key))
+	}
+	r := NewRovingTabindex(list, "li", options)
+	t.Cleanup(r.Release)
+	return r
+}
+
+// focused returns the data-id of the focused element
+func focused() string {
+	return ActiveElement().GetAttribute("data-id")
+}
+
+// tabStops returns the keys of the items with tabindex 0
+func tabStops(r *RovingTabindex) []string {
+	var stops []string
+	for _, item := range r.container.QuerySelectorAll("li") {
+		if item.GetAttribute("tabindex") == "0" {
+			stops = append(stops, item.GetAttribute("data-id"))
+		}
+	}
+	return stops
+}
+
+func TestRovingTabindexArrowKeys(t *testing.T) {
+	r := newRovingList(t, RovingOptions{}, "a", "b", "c")
+	if stops := tabStops(r); len(stops) != 1 || stops[0] != "a" {
+		t.Fatalf("tab stops %v, want [a]", stops)
+	}
+
+	r.Focus(r.Current())
+	for _, step := range []struct{ key, want string }{
+		{"ArrowDown", "b"},
+		{"ArrowRight", "b"}, // vertical lists ignore left and right
+		{"End", "c"},
+		{"ArrowDown", "c"}, // no wrapping
+		{"Home", "a"},
+		{"ArrowUp", "a"},
+	} {
+		press(ActiveElement().El, step.key)
+		if focused() != step.want {
+			t.Fatalf("%s focused %q, want %q", step.key, focused(), step.want)
+		}
+	}
+	press(ActiveElement().El, "ctrl+ArrowDown")
+	if stops := tabStops(r); focused() != "a" || len(stops) != 1 || stops[0] != "a" {
+		t.Errorf("Ctrl+ArrowDown moved focus to %q, tab stops %v", focused(), stops)
+	}
+}
+
+func TestRovingTabindexWrapsAndKeepsNewItemsInStep(t *testing.T) {
+	r := newRovingList(t, RovingOptions{Orientation: "horizontal", Wrap: true}, "a", "b")
+
+	r.Focus(r.Current())
+	press(ActiveElement().El, "ArrowLeft")
+	if focused() != "b" {
+		t.Errorf("ArrowLeft from the first item focused %q, want b", focused())
+	}
+
+	item := Document().CreateElement("li").SetAttribute("data-id", "c")
+	r.container.AppendChild(item)
+	runFrames()
+	if item.GetAttribute("tabindex") != "-1" {
+		t.Errorf("new item tabindex %q, want -1", item.GetAttribute("tabindex"))
+	}
+}
+
+func TestRovingTabindexRelease(t *testing.T) {
+	r := newRovingList(t, RovingOptions{}, "a", "b")
+	r.Focus(r.Current())
+	r.Release()
+
+	press(ActiveElement().El, "ArrowDown")
+	item := Document().CreateElement("li").SetAttribute("data-id", "c")
+	r.container.AppendChild(item)
+	runFrames()
+
+	if focused() != "a" || item.HasAttribute("tabindex") {
+		t.Errorf("released list moved focus to %q and managed the new item", focused())
+	}
+}
+
+func TestFocusTrapWrapsTab(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<button data-id="outside"></button><div><button data-id="first"></button>` +
+		`<button data-id="hidden" hidden></button><button data-id="last"></button></div>`)
+	outside := root.QuerySelector(`[data-id="outside"]`)
+	outside.Focus()
+
+	trap := NewFocusTrap(root.QuerySelector("div"))
+	trap.Activate()
+	t.Cleanup(trap.Deactivate)
+	if focused() != "first" {
+		t.Fatalf("activation focused %q, want first", focused())
+	}
+
+	press(ActiveElement().El, "shift+Tab")
+	if focused() != "last" {
+		t.Errorf("Shift+Tab from the first element focused %q, want last", focused())
+	}
+	press(ActiveElement().El, "Tab")
+	if focused() != "first" {
+		t.Errorf("Tab from the last element focused %q, want first", focused())
+	}
+
+	outside.Focus()
+	if focused() != "first" {
+		t.Errorf("focus moved out to %q", focused())
+	}
+
+	trap.Deactivate()
+	if focused() != "outside" {
+		t.Errorf("deactivation focused %q, want the element focused before", focused())
+	}
+}
+
+func TestFocusTrapsNest(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<div id="outer"><button data-id="outer"></button></div><div id="inner"><button data-id="inner"></button></div>`)
+	outer, inner := NewFocusTrap(root.QuerySelector("#outer")), NewFocusTrap(root.QuerySelector("#inner"))
+	outer.Activate()
+	t.Cleanup(outer.Deactivate)
+
+	inner.Activate()
+	if focused() != "inner" {
+		t.Fatalf("inner trap focused %q", focused())
+	}
+	inner.Deactivate()
+	if focused() != "outer" {
+		t.Errorf("focus went back to %q, want the outer trap", focused())
+	}
+}
+
+func TestSaveFocusRestoresAcrossRenders(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<ul><li data-id="a"><button class="edit"></button></li></ul>`)
+	root.QuerySelector(".edit").Focus()
+
+	state := SaveFocus(root)
+	root.SetHTML(`<ul><li data-id="b"><button class="edit"></button></li><li data-id="a"><button class="edit"></button></li></ul>`)
+
+	if !state.Restore() {
+		t.Fatal("focus not restored")
+	}
+	if got := ActiveElement().Closest("li").GetAttribute("data-id"); got != "a" || !ActiveElement().Matches(".edit") {
+		t.Errorf("focused the edit button of %q, want a", got)
+	}
+	if SaveFocus(newContainer(t)) != nil {
+		t.Error("saved focus outside the root")
+	}
+}
+
+func TestAnnouncerRepeatsMessages(t *testing.T) {
+	memory := backend.(*MemoryBackend)
+	a := NewAnnouncer()
+	t.Cleanup(func() {
+		for _, region := range a.regions {
+			region.Remove()
+		}
+	})
+
+	a.Announce("Todo added", Polite)
+	region := a.regions[Polite]
+	if region.GetAttribute("aria-live") != "polite" || region.GetAttribute("role") != "status" || region.GetText() != "" {
+		t.Fatalf("region %s %s holds %q before the delay", region.GetAttribute("role"), region.GetAttribute("aria-live"), region.GetText())
+	}
+	memory.Advance(announceDelay * time.Millisecond)
+	if region.GetText() != "Todo added" {
+		t.Errorf("region holds %q", region.GetText())
+	}
+
+	a.Announce("Todo added", Polite)
+	if region.GetText() != "" {
+		t.Error("region not cleared for the repeated message")
+	}
+	memory.Advance(announceDelay * time.Millisecond)
+	if region.GetText() != "Todo added" {
+		t.Errorf("region holds %q after repeating", region.GetText())
+	}
+
+	a.Announce("Could not save", Assertive)
+	if alert := a.regions[Assertive]; alert.GetAttribute("role") != "alert" || alert.El.Equal(region.El) {
+		t.Error("assertive message not given its own alert region")
+	}
+	runFrames()
+}
@@ -0,0 +1,426 @@
+package dom
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Keyframe is one step of an animation
+type Keyframe struct {
+	// Properties maps CSS properties, such as "opacity" or "transform", to
+	// their values at this step
+	Properties map[string]string
+	// Offset places the keyframe between 0 and 1. Keyframes without an
+	// offset are spaced evenly
+	Offset float64
+	// Easing is the timing function up to the next keyframe
+	Easing string
+}
+
+// AnimationOptions controls the timing of an animation. Empty fields use the
+// browser defaults
+type AnimationOptions struct {
+	Duration   int     // milliseconds
+	Delay      int     // milliseconds before the animation starts
+	Easing     string  // such as "ease-in-out" or "cubic-bezier(...)"
+	Fill       string  // "none", "forwards", "backwards" or "both"
+	Direction  string  // "normal", "reverse", "alternate" or "alternate-reverse"
+	Iterations float64 // number of repetitions, math.Inf(1) to repeat forever
+}
+
+// AnimationPreset is a named animation that can be played on any element
+type AnimationPreset struct {
+	Keyframes []Keyframe
+	// Options are the defaults; a duration passed to AnimatePreset replaces
+	// the preset's own
+	Options AnimationOptions
+}
+
+// Animation represents a CSS animation controller
+type Animation struct {
+	AnimObj Value
+}
+
+// ErrUnknownAnimation is returned when a preset name has not been registered
+var ErrUnknownAnimation = errors.New("dom: unknown animation preset")
+
+// presetDefaults are the options of the built-in presets
+var presetDefaults = AnimationOptions{Easing: "ease-in-out", Fill: "forwards"}
+
+// animationPresets holds the registered presets by name
+var animationPresets = map[string]AnimationPreset{
+	"fadeIn":       {fade("0", "1"), presetDefaults},
+	"fadeOut":      {fade("1", "0"), presetDefaults},
+	"slideIn":      {slide("translateX(-20px)", "0", "translateX(0)", "1"), presetDefaults},
+	"slideOut":     {slide("translateX(0)", "1", "translateX(20px)", "0"), presetDefaults},
+	"slideInUp":    {slide("translateY(20px)", "0", "translateY(0)", "1"), presetDefaults},
+	"slideOutDown": {slide("translateY(0)", "1", "translateY(20px)", "0"), presetDefaults},
+	"shake": {transforms(
+		"translateX(0)", "translateX(-5px)", "translateX(5px)",
+		"translateX(-5px)", "translateX(5px)", "translateX(0)",
+	), presetDefaults},
+}
+
+// fade returns opacity keyframes
+func fade(from, to string) []Keyframe {
+	return []Keyframe{
+		{Properties: map[string]string{"opacity": from}},
+		{Properties: map[string]string{"opacity": to}},
+	}
+}
+
+// slide returns keyframes that move and fade at the same time
+func slide(fromTransform, fromOpacity, toTransform, toOpacity string) []Keyframe {
+	return []Keyframe{
+		{Properties: map[string]string{"transform": fromTransform, "opacity": fromOpacity}},
+		{Properties: map[string]string{"transform": toTransform, "opacity": toOpacity}},
+	}
+}
+
+// transforms returns one keyframe per transform
+func transforms(values ...string) []Keyframe {
+	keyframes := make([]Keyframe, len(values))
+	for i, value := range values {
+		keyframes[i] = Keyframe{Properties: map[string]string{"transform": value}}
+	}
+	return keyframes
+}
+
+// RegisterAnimation adds a preset, replacing any preset with the same name
+func RegisterAnimation(name string, preset AnimationPreset) {
+	animationPresets[name] = preset
+}
+
+// LookupAnimation returns the preset registered under name
+func LookupAnimation(name string) (AnimationPreset, bool) {
+	preset, ok := animationPresets[name]
+	return preset, ok
+}
+
+// AnimationNames returns the names of the registered presets in sorted order
+func AnimationNames() []string {
+	names := make([]string, 0, len(animationPresets))
+	for name := range animationPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Animate creates a CSS animation from raw keyframe and option objects and
+// returns the animation object
+func (e Element) Animate(keyframes []map[string]interface{}, options map[string]interface{}) Animation {
+	if !e.Exists() {
+		return Animation{}
+	}
+
+	// Convert Go maps to JS objects
+	jsKeyframes := Global().Get("Array").New(len(keyframes))
+	for i, keyframe := range keyframes {
+		jsKeyframe := Global().Get("Object").New()
+		for key, value := range keyframe {
+			jsKeyframe.Set(key, value)
+		}
+		jsKeyframes.SetIndex(i, jsKeyframe)
+	}
+
+	jsOptions := Global().Get("Object").New()
+	for key, value := range options {
+		jsOptions.Set(key, value)
+	}
+
+	return Animation{
+		AnimObj: e.El.Call("animate", jsKeyframes, jsOptions),
+	}
+}
+
+// AnimateKeyframes animates the element through keyframes
+func (e Element) AnimateKeyframes(keyframes []Keyframe, options AnimationOptions) Animation {
+	raw := make([]map[string]interface{}, len(keyframes))
+	for i, keyframe := range keyframes {
+		frame := make(map[string]interface{}, len(keyframe.Properties)+2)
+		for property, value := range keyframe.Properties {
+			frame[property] = value
+		}
+		if keyframe.Offset > 0 {
+			frame["offset"] = keyframe.Offset
+		}
+		if keyframe.Easing != "" {
+			frame["easing"] = keyframe.Easing
+		}
+		raw[i] = frame
+	}
+
+	return e.Animate(raw, options.object())
+}
+
+// object converts the options to the form Element.animate takes
+func (o AnimationOptions) object() map[string]interface{} {
+	options := map[string]interface{}{}
+	if o.Duration > 0 {
+		options["duration"] = o.Duration
+	}
+	if o.Delay > 0 {
+		options["delay"] = o.Delay
+	}
+	if o.Easing != "" {
+		options["easing"] = o.Easing
+	}
+	if o.Fill != "" {
+		options["fill"] = o.Fill
+	}
+	if o.Direction != "" {
+		options["direction"] = o.Direction
+	}
+	if o.Iterations > 0 {
+		options["iterations"] = o.Iterations
+	}
+	return options
+}
+
+// AnimatePreset plays the named preset for duration milliseconds. It returns
+// ErrUnknownAnimation if no preset has that name
+func (e Element) AnimatePreset(name string, duration int) (Animation, error) {
+	preset, ok := animationPresets[name]
+	if !ok {
+		return Animation{}, fmt.Errorf("%w %q", ErrUnknownAnimation, name)
+	}
+
+	options := preset.Options
+	if duration > 0 {
+		options.Duration = duration
+	}
+	return e.AnimateKeyframes(preset.Keyframes, options), nil
+}
+
+// AnimateWithOptions plays the named preset for duration milliseconds. An
+// unknown name is handed to the reporters and nothing is played
+func (e Element) AnimateWithOptions(animationType string, duration int) Animation {
+	animation, err := e.AnimatePreset(animationType, duration)
+	if err != nil {
+		ReportError(err, "animation")
+		return Animation{}
+	}
+	return animation
+}
+
+// valid reports whether the animation wraps a real animation object
+func (a Animation) valid() bool {
+	return !isNullish(a.AnimObj)
+}
+
+// Play starts the animation
+func (a Animation) Play() {
+	if !a.valid() {
+		return
+	}
+
+	a.AnimObj.Call("play")
+}
+
+// Pause pauses the animation
+func (a Animation) Pause() {
+	if !a.valid() {
+		return
+	}
+
+	a.AnimObj.Call("pause")
+}
+
+// Cancel cancels the animation
+func (a Animation) Cancel() {
+	if !a.valid() {
+		return
+	}
+
+	a.AnimObj.Call("cancel")
+}
+
+// Finish finishes the animation
+func (a Animation) Finish() {
+	if !a.valid() {
+		return
+	}
+
+	a.AnimObj.Call("finish")
+}
+
+// PlayState returns "idle", "running", "paused" or "finished"
+func (a Animation) PlayState() string {
+	if !a.valid() {
+		return "finished"
+	}
+
+	return a.AnimObj.Get("playState").String()
+}
+
+// OnFinish adds a callback to be executed when the animation finishes. Every
+// callback added runs; a cancelled animation runs none of them
+func (a Animation) OnFinish(fn func()) {
+	a.onSettle(func(finished bool) {
+		if finished {
+			fn()
+		}
+	})
+}
+
+// OnSettle adds a callback to be executed when the animation finishes or is
+// cancelled, reporting which of the two happened
+func (a Animation) OnSettle(fn func(finished bool)) {
+	a.onSettle(fn)
+}
+
+// Done returns a channel that is closed once the animation has finished or
+// been cancelled
+func (a Animation) Done() <-chan struct{} {
+	done := make(chan struct{})
+	a.onSettle(func(bool) {
+		close(done)
+	})
+	return done
+}
+
+// Wait blocks until the animation has finished or been cancelled. The
+// animation's events are delivered by the event loop, so Wait must be called
+// from a goroutine of its own and never from an event handler
+func (a Animation) Wait() {
+	<-a.Done()
+}
+
+// onSettle calls fn once with whether the animation finished (true) or was
+// cancelled (false). Animations on missing elements finish immediately
+func (a Animation) onSettle(fn func(finished bool)) {
+	switch a.PlayState() {
+	case "finished":
+		fn(true)
+		return
+	case "idle":
+		fn(false)
+		return
+	}
+
+	// Listen for whichever comes first and drop both listeners after it
+	var finish, cancel *Listener
+	settle := func(finished bool) {
+		finish.Remove()
+		cancel.Remove()
+		fn(finished)
+	}
+
+	finish = newListener(a.AnimObj, "finish", func(Value) { settle(true) })
+	cancel = newListener(a.AnimObj, "cancel", func(Value) { settle(false) })
+}
+
+// Step is a piece of animated work that has not started yet. Calling it
+// starts the work, which calls done exactly once when it has completed.
+// Steps are combined with Sequence and Parallel:
+//
+//	dom.Sequence(
+//		list.Preset("fadeOut", 150),
+//		dom.Do(render),
+//		list.Preset("fadeIn", 150),
+//	).Then(func() { input.Focus() })
+type Step func(done func())
+
+// AnimationStep returns a step that calls start and completes when the
+// animation it returns finishes or is cancelled
+func AnimationStep(start func() Animation) Step {
+	return func(done func()) {
+		start().OnSettle(func(bool) { done() })
+	}
+}
+
+// Preset returns a step playing the named preset on the element. With an
+// unknown name the error is reported and the step completes at once
+func (e Element) Preset(name string, duration int) Step {
+	return AnimationStep(func() Animation {
+		return e.AnimateWithOptions(name, duration)
+	})
+}
+
+// Keyframes returns a step animating the element through keyframes
+func (e Element) Keyframes(keyframes []Keyframe, options AnimationOptions) Step {
+	return AnimationStep(func() Animation {
+		return e.AnimateKeyframes(keyframes, options)
+	})
+}
+
+// Do returns a step that runs fn and completes straight away, such as a
+// state change between a fade out and a fade in
+func Do(fn func()) Step {
+	return func(done func()) {
+		fn()
+		done()
+	}
+}
+
+// Delay returns a step that completes after ms milliseconds
+func Delay(ms int) Step {
+	return func(done func()) {
+		GetWindow().SetTimeout(done, ms)
+	}
+}
+
+// Sequence returns a step that runs steps one after another
+func Sequence(steps ...Step) Step {
+	return func(done func()) {
+		var next func(i int)
+		next = func(i int) {
+			if i == len(steps) {
+				done()
+				return
+			}
+			if steps[i] == nil {
+				next(i + 1)
+				return
+			}
+			steps[i](func() { next(i + 1) })
+		}
+		next(0)
+	}
+}
+
+// Parallel returns a step that starts every step at once and completes when
+// the last of them does
+func Parallel(steps ...Step) Step {
+	return func(done func()) {
+		remaining := len(steps) + 1
+		finish := func() {
+			remaining--
+			if remaining == 0 {
+				done()
+			}
+		}
+
+		for _, step := range steps {
+			if step == nil {
+				finish()
+				continue
+			}
+			step(finish)
+		}
+
+		// Account for steps that completed synchronously
+		finish()
+	}
+}
+
+// Start starts the step without waiting for it
+func (s Step) Start() {
+	s(func() {})
+}
+
+// Then starts the step and calls fn once it has completed
+func (s Step) Then(fn func()) {
+	s(fn)
+}
+
+// Play starts the step and returns a channel that is closed once it has
+// completed. Like Animation.Wait, receiving from the channel must happen on a
+// goroutine other than the event loop
+func (s Step) Play() <-chan struct{} {
+	done := make(chan struct{})
+	s(func() { close(done) })
+	return done
+}
@@ -0,0 +1,55 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnknownPresetIsReported(t *testing.T) {
+	reports := captureReports(t)
+	element := newContainer(t)
+
+	if _, err := element.AnimatePreset("wobble", 100); !errors.Is(err, ErrUnknownAnimation) {
+		t.Errorf("AnimatePreset err = %v, want ErrUnknownAnimation", err)
+	}
+
+	animation := element.AnimateWithOptions("wobble", 100)
+	if animation.valid() {
+		t.Error("unknown preset returned an animation")
+	}
+	if len(*reports) != 1 || !errors.Is((*reports)[0].Err, ErrUnknownAnimation) || (*reports)[0].Source != "animation" || (*reports)[0].Panic {
+		t.Errorf("reports = %+v, want one unknown animation", *reports)
+	}
+}
+
+func TestSequenceCompletesPastAnUnknownPreset(t *testing.T) {
+	captureReports(t)
+	element := newContainer(t)
+
+	var order []string
+	Sequence(
+		element.Preset("wobble", 100),
+		Do(func() { order = append(order, "do") }),
+		Parallel(element.Preset("fadeIn", 100), element.Preset("wobble", 100)),
+	).Then(func() { order = append(order, "done") })
+	runFrames()
+
+	if len(order) != 2 || order[0] != "do" || order[1] != "done" {
+		t.Errorf("order = %v, want [do done]", order)
+	}
+}
+
+func TestRegisterAnimation(t *testing.T) {
+	RegisterAnimation("test-pulse", AnimationPreset{Keyframes: fade("1", "0.5")})
+	t.Cleanup(func() { delete(animationPresets, "test-pulse") })
+
+	if _, ok := LookupAnimation("test-pulse"); !ok {
+		t.Fatal("registered preset not found")
+	}
+	if _, err := newContainer(t).AnimatePreset("test-pulse", 50); err != nil {
+		t.Error(err)
+	}
+}
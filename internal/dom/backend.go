@@ -0,0 +1,151 @@
+package dom
+
+// Type identifies the JavaScript type of a Value
+type Type int
+
+// JavaScript value types, mirroring syscall/js
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeSymbol
+	TypeObject
+	TypeFunction
+)
+
+// String returns the JavaScript name of the type
+func (t Type) String() string {
+	switch t {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeSymbol:
+		return "symbol"
+	case TypeObject:
+		return "object"
+	case TypeFunction:
+		return "function"
+	}
+	return "unknown"
+}
+
+// Value is a JavaScript value as seen by this package. It mirrors the API of
+// syscall/js.Value so the same code can drive the browser or the in-memory
+// document. Arguments may be Go primitives, Values, Funcs, []interface{} or
+// map[string]interface{}
+type Value interface {
+	Get(name string) Value
+	Set(name string, value interface{})
+	Delete(name string)
+	Call(method string, args ...interface{}) Value
+	Invoke(args ...interface{}) Value
+	New(args ...interface{}) Value
+	Index(i int) Value
+	SetIndex(i int, value interface{})
+	Length() int
+	Type() Type
+	IsNull() bool
+	IsUndefined() bool
+	Truthy() bool
+	String() string
+	Int() int
+	Float() float64
+	Bool() bool
+	Equal(other Value) bool
+}
+
+// Func is a Go function that can be called from JavaScript. It must be
+// released once it is no longer needed
+type Func interface {
+	Value
+	Release()
+}
+
+// Backend provides the JavaScript environment the package operates on
+type Backend interface {
+	// Global returns the global object (window in the browser)
+	Global() Value
+	// FuncOf wraps a Go function so it can be called from JavaScript
+	FuncOf(fn func(this Value, args []Value) interface{}) Func
+	// Null returns the JavaScript null value
+	Null() Value
+	// Undefined returns the JavaScript undefined value
+	Undefined() Value
+	// ValueOf converts a Go value to a JavaScript value
+	ValueOf(x interface{}) Value
+	// CopyBytesToGo copies bytes from a Uint8Array into dst and returns the
+	// number copied
+	CopyBytesToGo(dst []byte, src Value) int
+	// CopyBytesToJS copies bytes from src into a Uint8Array and returns the
+	// number copied
+	CopyBytesToJS(dst Value, src []byte) int
+}
+
+// backend is the environment used by every wrapper in this package. It is the
+// browser inside a GOOS=js GOARCH=wasm build and an in-memory document otherwise
+var backend Backend
+
+// UseBackend replaces the backend used by the package and returns the previous one
+func UseBackend(b Backend) Backend {
+	previous := backend
+	backend = b
+	return previous
+}
+
+// CurrentBackend returns the backend in use
+func CurrentBackend() Backend {
+	return backend
+}
+
+// Global returns the global object of the current backend
+func Global() Value {
+	return backend.Global()
+}
+
+// FuncOf wraps fn so it can be called from JavaScript on the current backend.
+// A panic in fn is handed to the reporters and JavaScript receives undefined,
+// so one faulty callback cannot bring down the program
+func FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	return backend.FuncOf(guardCallback(fn))
+}
+
+// Null returns the JavaScript null value of the current backend
+func Null() Value {
+	return backend.Null()
+}
+
+// Undefined returns the JavaScript undefined value of the current backend
+func Undefined() Value {
+	return backend.Undefined()
+}
+
+// ValueOf converts a Go value to a JavaScript value on the current backend
+func ValueOf(x interface{}) Value {
+	return backend.ValueOf(x)
+}
+
+// CopyBytesToGo copies bytes from the Uint8Array src into dst. It returns
+// the number of bytes copied, the smaller of the two lengths
+func CopyBytesToGo(dst []byte, src Value) int {
+	return backend.CopyBytesToGo(dst, src)
+}
+
+// CopyBytesToJS copies bytes from src into the Uint8Array dst. It returns
+// the number of bytes copied, the smaller of the two lengths
+func CopyBytesToJS(dst Value, src []byte) int {
+	return backend.CopyBytesToJS(dst, src)
+}
+
+// isNullish reports whether v is missing, null or undefined
+func isNullish(v Value) bool {
+	return v == nil || v.IsNull() || v.IsUndefined()
+}
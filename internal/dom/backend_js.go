@@ -0,0 +1,193 @@
+//go:build js && wasm
+// +build js,wasm
+
+package dom
+
+import (
+	"syscall/js"
+)
+
+func init() {
+	backend = jsBackend{}
+}
+
+// jsBackend drives the browser through syscall/js
+type jsBackend struct{}
+
+// jsValue adapts a js.Value to the Value interface
+type jsValue struct {
+	v js.Value
+}
+
+// jsFunc adapts a js.Func to the Func interface
+type jsFunc struct {
+	jsValue
+	fn js.Func
+}
+
+// WrapJS converts a syscall/js value into a Value
+func WrapJS(v js.Value) Value {
+	return jsValue{v}
+}
+
+// UnwrapJS converts a Value created by the browser backend back into a js.Value
+func UnwrapJS(v Value) js.Value {
+	switch value := v.(type) {
+	case jsValue:
+		return value.v
+	case *jsFunc:
+		return value.fn.Value
+	}
+	return js.Undefined()
+}
+
+// toJS converts arguments into something syscall/js accepts
+func toJS(x interface{}) interface{} {
+	switch value := x.(type) {
+	case jsValue:
+		return value.v
+	case *jsFunc:
+		return value.fn
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, item := range value {
+			converted[i] = toJS(item)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[key] = toJS(item)
+		}
+		return converted
+	case []string:
+		converted := make([]interface{}, len(value))
+		for i, item := range value {
+			converted[i] = item
+		}
+		return converted
+	}
+	return x
+}
+
+// toJSArgs converts a list of arguments with toJS
+func toJSArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		converted[i] = toJS(arg)
+	}
+	return converted
+}
+
+func (b jsBackend) Global() Value {
+	return jsValue{js.Global()}
+}
+
+func (b jsBackend) FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		wrapped := make([]Value, len(args))
+		for i, arg := range args {
+			wrapped[i] = jsValue{arg}
+		}
+		return toJS(fn(jsValue{this}, wrapped))
+	})
+
+	return &jsFunc{jsValue: jsValue{f.Value}, fn: f}
+}
+
+func (b jsBackend) Null() Value {
+	return jsValue{js.Null()}
+}
+
+func (b jsBackend) Undefined() Value {
+	return jsValue{js.Undefined()}
+}
+
+func (b jsBackend) ValueOf(x interface{}) Value {
+	return jsValue{js.ValueOf(toJS(x))}
+}
+
+func (b jsBackend) CopyBytesToGo(dst []byte, src Value) int {
+	return js.CopyBytesToGo(dst, UnwrapJS(src))
+}
+
+func (b jsBackend) CopyBytesToJS(dst Value, src []byte) int {
+	return js.CopyBytesToJS(UnwrapJS(dst), src)
+}
+
+func (j jsValue) Get(name string) Value {
+	return jsValue{j.v.Get(name)}
+}
+
+func (j jsValue) Set(name string, value interface{}) {
+	j.v.Set(name, toJS(value))
+}
+
+func (j jsValue) Delete(name string) {
+	j.v.Delete(name)
+}
+
+func (j jsValue) Call(method string, args ...interface{}) Value {
+	return jsValue{j.v.Call(method, toJSArgs(args)...)}
+}
+
+func (j jsValue) Invoke(args ...interface{}) Value {
+	return jsValue{j.v.Invoke(toJSArgs(args)...)}
+}
+
+func (j jsValue) New(args ...interface{}) Value {
+	return jsValue{j.v.New(toJSArgs(args)...)}
+}
+
+func (j jsValue) Index(i int) Value {
+	return jsValue{j.v.Index(i)}
+}
+
+func (j jsValue) SetIndex(i int, value interface{}) {
+	j.v.SetIndex(i, toJS(value))
+}
+
+func (j jsValue) Length() int {
+	return j.v.Length()
+}
+
+func (j jsValue) Type() Type {
+	return Type(j.v.Type())
+}
+
+func (j jsValue) IsNull() bool {
+	return j.v.IsNull()
+}
+
+func (j jsValue) IsUndefined() bool {
+	return j.v.IsUndefined()
+}
+
+func (j jsValue) Truthy() bool {
+	return j.v.Truthy()
+}
+
+func (j jsValue) String() string {
+	return j.v.String()
+}
+
+func (j jsValue) Int() int {
+	return j.v.Int()
+}
+
+func (j jsValue) Float() float64 {
+	return j.v.Float()
+}
+
+func (j jsValue) Bool() bool {
+	return j.v.Bool()
+}
+
+func (j jsValue) Equal(other Value) bool {
+	return j.v.Equal(UnwrapJS(other))
+}
+
+// Release frees the underlying js.Func
+func (f *jsFunc) Release() {
+	f.fn.Release()
+}
@@ -0,0 +1,8 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+func init() {
+	backend = NewMemoryBackend()
+}
@@ -0,0 +1,253 @@
+package dom
+
+import (
+	"reflect"
+)
+
+// Component is a reusable piece of UI. Render describes its DOM as a virtual
+// node tree; Mount runs once that DOM is in the document and Unmount right
+// before it is removed. Implementations embed Base, which supplies props,
+// default hooks and Update:
+//
+//	type Counter struct {
+//		dom.Base[CounterProps]
+//		count int // local state
+//	}
+//
+//	func (c *Counter) Render() *dom.VNode { ... }
+//
+// Components are used as pointers, either inside another tree through
+// ComponentNode or on their own through Mount.
+type Component interface {
+	Render() *VNode
+	Mount()
+	Unmount()
+
+	base() *componentState
+	adopt(next Component)
+}
+
+// componentState is the bookkeeping shared by every component
+type componentState struct {
+	self     Component
+	rendered *VNode // tree returned by the last Render
+	mounted  bool
+	scope    *Scope
+}
+
+// Base implements the parts of Component that don't depend on the UI being
+// described. P is the type of the props a parent passes in
+type Base[P any] struct {
+	Props P
+
+	state componentState
+}
+
+func (b *Base[P]) base() *componentState {
+	return &b.state
+}
+
+func (b *Base[P]) props() *P {
+	return &b.Props
+}
+
+// adopt takes the props of a freshly created instance of the same component
+// so that a re-rendering parent updates the existing instance and its local
+// state survives
+func (b *Base[P]) adopt(next Component) {
+	if other, ok := next.(interface{ props() *P }); ok {
+		b.Props = *other.props()
+	}
+}
+
+// Mount is called after the component's DOM has been inserted
+func (b *Base[P]) Mount() {}
+
+// Unmount is called before the component's DOM is removed
+func (b *Base[P]) Unmount() {}
+
+// Mounted reports whether the component is currently in the document
+func (b *Base[P]) Mounted() bool {
+	return b.state.mounted
+}
+
+// Element returns the root element of the component's DOM
+func (b *Base[P]) Element() Element {
+	if b.state.rendered == nil {
+		return Element{}
+	}
+	return b.state.rendered.Element()
+}
+
+// Scope returns a scope that is released when the component unmounts. Use it
+// for listeners and timers registered in Mount
+func (b *Base[P]) Scope() *Scope {
+	if b.state.scope == nil {
+		b.state.scope = NewScope()
+	}
+	return b.state.scope
+}
+
+// Effect runs fn as an Effect that stops when the component unmounts
+func (b *Base[P]) Effect(fn func()) *Effect {
+	effect := NewEffect(fn)
+	b.Scope().OnRelease(effect.Stop)
+	return effect
+}
+
+// Update re-renders the component after its props or local state changed
+// and patches its DOM in place
+func (b *Base[P]) Update() {
+	b.state.update()
+}
+
+// Invalidate schedules Update for the next animation frame, so that several
+// changes in a row re-render the component once
+func (b *Base[P]) Invalidate() {
+	Invalidate(b)
+}
+
+// ComponentNode creates a virtual node rendered by a component. The key
+// identifies the component among its siblings; when a parent re-renders, an
+// existing instance with the same key and type takes the new props instead
+// of being recreated
+func ComponentNode(key string, component Component) *VNode {
+	return &VNode{Key: key, Component: component}
+}
+
+// Mount renders component in place of target, which is removed from the
+// document, and runs the component's Mount hooks
+func Mount(target Element, component Component) Element {
+	if !target.Exists() {
+		return Element{}
+	}
+
+	parent := wrapElement(target.El.Get("parentNode"))
+	if !parent.Exists() {
+		return Element{}
+	}
+
+	node := ComponentNode("", component)
+	var created []*VNode
+	el := createNode(node, &created)
+
+	parent.El.Call("replaceChild", el.El, target.El)
+	target.Release()
+
+	runCreated(created)
+	return el
+}
+
+// Unmount removes a component attached with Mount from the document after
+// running its Unmount hooks
+func Unmount(component Component) {
+	state := component.base()
+	if state.rendered == nil {
+		return
+	}
+
+	el := state.rendered.Element()
+	unmountNode(&VNode{Component: component})
+	el.Remove()
+}
+
+// render calls the component's Render, standing in an empty text node for nil
+func render(component Component) *VNode {
+	node := component.Render()
+	if node == nil {
+		node = TextNode("")
+	}
+	return node
+}
+
+// update re-renders a mounted component
+func (s *componentState) update() {
+	if !s.mounted || s.rendered == nil {
+		return
+	}
+
+	parent := wrapElement(s.rendered.Element().El.Get("parentNode"))
+	if !parent.Exists() {
+		return
+	}
+
+	// Moving or replacing the focused element loses focus, which would
+	// leave keyboard users at the top of the page
+	focus := SaveFocus(s.rendered.Element())
+
+	var created []*VNode
+	rendered := render(s.self)
+	patchNode(parent, s.rendered, rendered, &created)
+	s.rendered = rendered
+
+	runCreated(created)
+	focus.Restore()
+}
+
+// createComponent builds the DOM of a component node
+func createComponent(node *VNode, created *[]*VNode) Element {
+	state := node.Component.base()
+	state.self = node.Component
+	state.rendered = render(node.Component)
+
+	el := createNode(state.rendered, created)
+	*created = append(*created, node)
+	return el
+}
+
+// patchComponent hands the new props to the existing instance and patches
+// what it renders
+func patchComponent(parent Element, old, node *VNode, created *[]*VNode) {
+	instance := old.Component
+	if instance != node.Component {
+		instance.adopt(node.Component)
+		node.Component = instance
+	}
+
+	state := instance.base()
+	rendered := render(instance)
+	patchNode(parent, state.rendered, rendered, created)
+	state.rendered = rendered
+}
+
+// unmountNode runs the Unmount hooks of every component in the tree, parents
+// before their children
+func unmountNode(node *VNode) {
+	if node.Component != nil {
+		state := node.Component.base()
+		if state.mounted {
+			state.mounted = false
+			node.Component.Unmount()
+			if state.scope != nil {
+				state.scope.Release()
+				state.scope = nil
+			}
+		}
+		if state.rendered != nil {
+			unmountNode(state.rendered)
+		}
+		return
+	}
+
+	for _, child := range node.Children {
+		unmountNode(child)
+	}
+}
+
+// runCreated runs the creation hooks collected while building DOM, children
+// before their parents
+func runCreated(created []*VNode) {
+	for _, node := range created {
+		if node.Component != nil {
+			node.Component.base().mounted = true
+			node.Component.Mount()
+		} else {
+			node.OnCreate(node.el)
+		}
+	}
+}
+
+// sameKind reports whether old can be patched into node rather than replaced
+func sameKind(old, node *VNode) bool {
+	return old.Tag == node.Tag && reflect.TypeOf(old.Component) == reflect.TypeOf(node.Component)
+}
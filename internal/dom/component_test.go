@@ -0,0 +1,156 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// counterProps configures a counter
+type counterProps struct {
+	Label string
+	Log   *[]string
+}
+
+// counter is a button counting its clicks in local state
+type counter struct {
+	Base[counterProps]
+	count    int
+	listener *Listener
+}
+
+func (c *counter) Render() *VNode {
+	return H("button", Text(c.Props.Label+" "+strconv.Itoa(c.count)))
+}
+
+func (c *counter) Mount() {
+	*c.Props.Log = append(*c.Props.Log, "mount "+c.Props.Label)
+	c.listener = c.Scope().Listen(newListener(c.Element().El, "click", func(Value) {
+		c.count++
+		c.Invalidate()
+	}))
+}
+
+func (c *counter) Unmount() {
+	*c.Props.Log = append(*c.Props.Log, "unmount "+c.Props.Label)
+}
+
+// counterListProps configures a counterList
+type counterListProps struct {
+	Keys   []string
+	Labels map[string]string
+	Log    *[]string
+}
+
+// counterList renders a counter per key
+type counterList struct {
+	Base[counterListProps]
+}
+
+func (l *counterList) Render() *VNode {
+	var children Children
+	for _, key := range l.Props.Keys {
+		children = append(children, ComponentNode(key, &counter{Base: Base[counterProps]{
+			Props: counterProps{Label: l.Props.Labels[key], Log: l.Props.Log},
+		}}))
+	}
+	return H("div", children)
+}
+
+// mountCounters mounts a counterList for keys labelled by themselves
+func mountCounters(t *testing.T, keys ...string) (*counterList, *[]string) {
+	t.Helper()
+	var log []string
+	labels := map[string]string{}
+	for _, key := range keys {
+		labels[key] = key
+	}
+	list := &counterList{Base: Base[counterListProps]{Props: counterListProps{Keys: keys, Labels: labels, Log: &log}}}
+	placeholder := Document().CreateElement("div")
+	newContainer(t).AppendChild(placeholder)
+	Mount(placeholder, list)
+	t.Cleanup(func() { Unmount(list) })
+	return list, &log
+}
+
+func TestComponentKeepsLocalStateAcrossParentRenders(t *testing.T) {
+	list, _ := mountCounters(t, "a", "b")
+	button := list.Element().QuerySelector("button")
+
+	button.El.Call("click")
+	runFrames()
+	list.Props.Labels = map[string]string{"a": "A", "b": "B"}
+	list.Update()
+
+	if got := list.Element().GetText(); got != "A 1B 0" {
+		t.Errorf("text %q, want the new labels with the old counts", got)
+	}
+	if !list.Element().QuerySelector("button").El.Equal(button.El) {
+		t.Error("counter re-created instead of updated")
+	}
+}
+
+func TestComponentHooksAndScope(t *testing.T) {
+	list, log := mountCounters(t, "a", "b")
+	first := list.Element().QuerySelector("button")
+	var removed *counter
+	for _, child := range list.state.rendered.Children {
+		if child.Key == "a" {
+			removed = child.Component.(*counter)
+		}
+	}
+
+	if !removed.Mounted() || !removed.Element().El.Get("isConnected").Bool() {
+		t.Fatal("counter not mounted in the document")
+	}
+
+	list.Props.Keys = []string{"b"}
+	list.Update()
+	first.El.Call("click")
+	runFrames()
+
+	want := []string{"mount a", "mount b", "unmount a"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("hooks ran %q, want %q", *log, want)
+	}
+	if removed.Mounted() || removed.listener.Active() || removed.count != 0 {
+		t.Error("removed counter kept its listener")
+	}
+}
+
+func TestUnmountRunsEveryHook(t *testing.T) {
+	list, log := mountCounters(t, "a", "b")
+	element := list.Element()
+
+	Unmount(list)
+	Unmount(list)
+
+	want := []string{"mount a", "mount b", "unmount a", "unmount b"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("hooks ran %q, want %q", *log, want)
+	}
+	if element.El.Get("isConnected").Bool() || list.Mounted() {
+		t.Error("unmounted component left in the document")
+	}
+}
+
+func TestComponentEffectStopsOnUnmount(t *testing.T) {
+	list, _ := mountCounters(t, "a")
+	signal := NewSignal(0)
+	runs := 0
+	list.Effect(func() {
+		signal.Get()
+		runs++
+	})
+
+	signal.Set(1)
+	Unmount(list)
+	signal.Set(2)
+
+	if runs != 2 {
+		t.Errorf("effect ran %d times, want 2", runs)
+	}
+}
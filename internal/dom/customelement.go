@@ -0,0 +1,305 @@
+package dom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CustomElement is a Go type backing a custom element such as
+// <go-todo-list>. Implementations embed ElementBase, which supplies the
+// host, the shadow root and default hooks:
+//
+//	type Badge struct {
+//		dom.ElementBase
+//	}
+//
+//	func (b *Badge) AttributeChanged(name, oldValue, newValue string) {
+//		b.Shadow().QuerySelector(".count").SetText(newValue)
+//	}
+//
+// Connected runs when the element is inserted into the document,
+// Disconnected when it is removed and AttributeChanged when one of the
+// observed attributes is set or removed. A removed attribute is reported as
+// "", like one set to the empty string; HasAttribute on the host tells them
+// apart
+type CustomElement interface {
+	Connected()
+	Disconnected()
+	AttributeChanged(name, oldValue, newValue string)
+
+	element() *ElementBase
+}
+
+// ElementOptions configures a custom element defined with DefineElement
+type ElementOptions struct {
+	// Observed lists the attributes reported to AttributeChanged
+	Observed []string
+	// Shadow is the mode of the shadow root attached to every instance,
+	// "open" or "closed". No shadow root is attached when empty
+	Shadow string
+	// Styles is CSS placed at the top of the shadow root, where it applies
+	// to the shadow tree only
+	Styles string
+}
+
+// ErrElementDefined is returned by DefineElement for a name already in use
+var ErrElementDefined = errors.New("dom: custom element already defined")
+
+// ErrElementName is returned by DefineElement for a name that custom
+// elements cannot have: it must start with a lowercase letter, contain a
+// hyphen and have no uppercase letters
+var ErrElementName = errors.New("dom: invalid custom element name")
+
+// elementProperty is the expando property linking a custom element to the
+// Go value backing it
+const elementProperty = "__gorgasmElement"
+
+// elementInstances holds the Go values backing custom elements, keyed by the
+// id stored on the element under elementProperty
+var (
+	elementInstances = map[int]CustomElement{}
+	nextElementID    = 1
+)
+
+// elementClassSource is the body of the function creating the JavaScript
+// class of a custom element. The class hands every reaction to hooks
+const elementClassSource = `return class extends HTMLElement {
+	static get observedAttributes() { return observed; }
+	constructor() { super(); hooks.created(this); }
+	connectedCallback() { hooks.connected(this); }
+	disconnectedCallback() { hooks.disconnected(this); }
+	attributeChangedCallback(name, oldValue, newValue) { hooks.attributeChanged(this, name, oldValue, newValue); }
+};`
+
+// elementClassMaker is implemented by backends that build custom element
+// classes themselves instead of evaluating elementClassSource
+type elementClassMaker interface {
+	elementClass(observed []string, hooks Value) Value
+}
+
+// DefineElement registers a custom element whose instances are backed by
+// the values create returns, one per element. Elements of that name already
+// in the document are upgraded straight away:
+//
+//	err := dom.DefineElement("go-badge", dom.ElementOptions{
+//		Observed: []string{"count"},
+//		Shadow:   "open",
+//		Styles:   ":host { display: inline-block; }",
+//	}, func() dom.CustomElement { return &Badge{} })
+//
+// The Go value lives until the element is released with Element.Release or
+// Element.Remove. In the browser the class is compiled with the Function
+// constructor, so a Content-Security-Policy must allow 'unsafe-eval'
+func DefineElement(name string, options ElementOptions, create func() CustomElement) error {
+	if !validElementName(name) {
+		return fmt.Errorf("%w %q", ErrElementName, name)
+	}
+
+	registry := Global().Get("customElements")
+	if !registry.Call("get", name).IsUndefined() {
+		return fmt.Errorf("%w %q", ErrElementDefined, name)
+	}
+
+	hooks := map[string]interface{}{
+		"created": FuncOf(func(_ Value, args []Value) interface{} {
+			createElement(args[0], options, create)
+			return nil
+		}),
+		"connected": FuncOf(func(_ Value, args []Value) interface{} {
+			if instance, ok := instanceOf(args[0]); ok {
+				base := instance.element()
+				base.scope = NewScope()
+				base.connected = true
+				instance.Connected()
+			}
+			return nil
+		}),
+		"disconnected": FuncOf(func(_ Value, args []Value) interface{} {
+			if instance, ok := instanceOf(args[0]); ok {
+				base := instance.element()
+				base.connected = false
+				instance.Disconnected()
+				base.scope.Release()
+			}
+			return nil
+		}),
+		"attributeChanged": FuncOf(func(_ Value, args []Value) interface{} {
+			if instance, ok := instanceOf(args[0]); ok {
+				instance.AttributeChanged(args[1].String(), attributeString(args[2]), attributeString(args[3]))
+			}
+			return nil
+		}),
+	}
+
+	var class Value
+	if maker, ok := backend.(elementClassMaker); ok {
+		class = maker.elementClass(options.Observed, ValueOf(hooks))
+	} else {
+		observed := make([]interface{}, len(options.Observed))
+		for i, attr := range options.Observed {
+			observed[i] = attr
+		}
+		class = Global().Get("Function").New("observed", "hooks", elementClassSource).Invoke(observed, hooks)
+	}
+
+	registry.Call("define", name, class)
+	return nil
+}
+
+// ElementOf returns the Go value backing a custom element
+func ElementOf(e Element) (CustomElement, bool) {
+	if !e.Exists() {
+		return nil, false
+	}
+	return instanceOf(e.El)
+}
+
+// createElement creates the Go value for a newly created or upgraded element
+func createElement(host Value, options ElementOptions, create func() CustomElement) {
+	instance := create()
+	base := instance.element()
+	base.host = wrapElement(host)
+	base.scope = &Scope{released: true}
+
+	if options.Shadow != "" {
+		base.shadow = wrapElement(host.Call("attachShadow", map[string]interface{}{"mode": options.Shadow}))
+		if options.Styles != "" {
+			style := Document().CreateElement("style").SetText(options.Styles)
+			base.shadow.AppendChild(style)
+		}
+	}
+
+	id := nextElementID
+	nextElementID++
+	elementInstances[id] = instance
+	host.Set(elementProperty, id)
+
+	base.host.Scope().OnRelease(func() {
+		delete(elementInstances, id)
+		host.Delete(elementProperty)
+		base.release()
+	})
+}
+
+// instanceOf returns the Go value stored on a custom element
+func instanceOf(host Value) (CustomElement, bool) {
+	id := host.Get(elementProperty)
+	if id.Type() != TypeNumber {
+		return nil, false
+	}
+	instance, ok := elementInstances[id.Int()]
+	return instance, ok
+}
+
+// attributeString converts an attribute value, null when absent, to a string
+func attributeString(value Value) string {
+	if isNullish(value) {
+		return ""
+	}
+	return value.String()
+}
+
+// validElementName reports whether name can be used for a custom element
+func validElementName(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' || !strings.Contains(name, "-") {
+		return false
+	}
+	return name == strings.ToLower(name)
+}
+
+// ElementBase implements the parts of CustomElement that don't depend on the
+// element being backed
+type ElementBase struct {
+	host      Element
+	shadow    Element // empty without a shadow root
+	scope     *Scope  // released on disconnect
+	component Component
+	connected bool
+}
+
+func (b *ElementBase) element() *ElementBase {
+	return b
+}
+
+// Connected is called after the element has been inserted into the document
+func (b *ElementBase) Connected() {}
+
+// Disconnected is called after the element has been removed from the document
+func (b *ElementBase) Disconnected() {}
+
+// AttributeChanged is called after an observed attribute was set or removed
+func (b *ElementBase) AttributeChanged(name, oldValue, newValue string) {}
+
+// Host returns the custom element itself
+func (b *ElementBase) Host() Element {
+	return b.host
+}
+
+// Shadow returns the shadow root, or an empty Element when the definition
+// has none
+func (b *ElementBase) Shadow() Element {
+	return b.shadow
+}
+
+// IsConnected reports whether the element is in the document
+func (b *ElementBase) IsConnected() bool {
+	return b.connected
+}
+
+// Scope returns a scope that is released when the element is disconnected.
+// Use it for listeners and timers registered in Connected
+func (b *ElementBase) Scope() *Scope {
+	return b.scope
+}
+
+// Dispatch fires a CustomEvent carrying detail from the element. The event
+// bubbles, is cancelable and crosses shadow roots, so listeners anywhere
+// above the element receive it; it reports whether no listener prevented
+// the default action
+func (b *ElementBase) Dispatch(eventType string, detail interface{}) bool {
+	event := Global().Get("CustomEvent").New(eventType, map[string]interface{}{
+		"detail":     detail,
+		"bubbles":    true,
+		"cancelable": true,
+		"composed":   true,
+	})
+	return b.host.El.Call("dispatchEvent", event).Bool()
+}
+
+// MountComponent renders component into the shadow root, or into the host
+// when there is none, replacing a component mounted before. Call it from
+// Connected: browsers don't let a custom element gain children while it is
+// being created
+func (b *ElementBase) MountComponent(component Component) Element {
+	if b.component != nil {
+		Unmount(b.component)
+	}
+
+	root := b.shadow
+	if !root.Exists() {
+		root = b.host
+	}
+
+	placeholder := Document().CreateElement("div")
+	root.AppendChild(placeholder)
+	b.component = component
+	return Mount(placeholder, component)
+}
+
+// release frees what the element holds once it is released
+func (b *ElementBase) release() {
+	if b.component != nil {
+		Unmount(b.component)
+		b.component = nil
+	}
+	b.scope.Release()
+
+	// Element.Release does not walk into shadow trees
+	if b.shadow.Exists() {
+		children := b.shadow.El.Get("children")
+		for i := children.Length() - 1; i >= 0; i-- {
+			wrapElement(children.Index(i)).Release()
+		}
+	}
+}
@@ -0,0 +1,134 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// loggedElement records the callbacks it receives
+type loggedElement struct {
+	ElementBase
+	log *[]string
+}
+
+func (e *loggedElement) Connected() {
+	*e.log = append(*e.log, "connected")
+}
+
+func (e *loggedElement) Disconnected() {
+	*e.log = append(*e.log, "disconnected")
+}
+
+func (e *loggedElement) AttributeChanged(name, oldValue, newValue string) {
+	*e.log = append(*e.log, name+" "+oldValue+"->"+newValue)
+}
+
+// defineLogged defines name as a loggedElement observing count, with an open
+// shadow root, and returns the log its instances share
+func defineLogged(t *testing.T, name string) *[]string {
+	t.Helper()
+	var log []string
+	err := DefineElement(name, ElementOptions{
+		Observed: []string{"count"},
+		Shadow:   "open",
+		Styles:   ":host { display: block; }",
+	}, func() CustomElement { return &loggedElement{log: &log} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &log
+}
+
+func TestDefineElementChecksTheName(t *testing.T) {
+	for _, name := range []string{"badge", "Go-badge", "go-Badge", "1-badge", ""} {
+		if err := DefineElement(name, ElementOptions{}, nil); !errors.Is(err, ErrElementName) {
+			t.Errorf("DefineElement(%q) err = %v, want ErrElementName", name, err)
+		}
+	}
+
+	defineLogged(t, "test-defined")
+	if err := DefineElement("test-defined", ElementOptions{}, nil); !errors.Is(err, ErrElementDefined) {
+		t.Errorf("second definition err = %v, want ErrElementDefined", err)
+	}
+}
+
+func TestCustomElementCallbacks(t *testing.T) {
+	log := defineLogged(t, "test-callbacks")
+	root := newContainer(t)
+
+	host := Document().CreateElement("test-callbacks")
+	host.SetAttribute("count", "1")
+	host.SetAttribute("label", "ignored")
+	root.AppendChild(host)
+	host.SetAttribute("count", "2")
+	host.RemoveAttribute("count")
+	root.RemoveChild(host)
+
+	want := []string{"count ->1", "connected", "count 1->2", "count 2->", "disconnected"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("callbacks %q, want %q", *log, want)
+	}
+}
+
+func TestCustomElementShadowAndScope(t *testing.T) {
+	defineLogged(t, "test-shadow")
+	root := newContainer(t)
+	root.SetHTML(`<test-shadow></test-shadow>`)
+
+	instance, ok := ElementOf(root.QuerySelector("test-shadow"))
+	if !ok {
+		t.Fatal("no Go value behind the element")
+	}
+	base := instance.element()
+	if style := base.Shadow().QuerySelector("style"); style.GetText() != ":host { display: block; }" {
+		t.Errorf("shadow root styles %q", style.GetText())
+	}
+	if !base.IsConnected() || base.Scope().released {
+		t.Error("connected element has no live scope")
+	}
+
+	scope := base.Scope()
+	base.Host().Remove()
+	if !scope.released {
+		t.Error("scope kept after the element was removed")
+	}
+	if _, ok := ElementOf(base.Host()); ok {
+		t.Error("Go value kept after the element was released")
+	}
+}
+
+func TestCustomElementUpgradesExistingElements(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<test-upgrade count="3"></test-upgrade>`)
+
+	log := defineLogged(t, "test-upgrade")
+
+	want := []string{"count ->3", "connected"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("callbacks %q, want %q", *log, want)
+	}
+}
+
+func TestCustomElementDispatch(t *testing.T) {
+	defineLogged(t, "test-dispatch")
+	root := newContainer(t)
+	root.SetHTML(`<test-dispatch></test-dispatch>`)
+	instance, _ := ElementOf(root.QuerySelector("test-dispatch"))
+
+	var details []string
+	root.AddEventListenerWithEvent("todo-delete", func(event Event) {
+		details = append(details, event.EventObj.Get("detail").String())
+		event.PreventDefault()
+	})
+
+	if instance.element().Dispatch("todo-delete", "a") {
+		t.Error("Dispatch reported the default action as allowed")
+	}
+	if !reflect.DeepEqual(details, []string{"a"}) {
+		t.Errorf("listener got %q", details)
+	}
+}
@@ -0,0 +1,68 @@
+package dom
+
+// nonBubblingEvents are delegated by listening in the capture phase
+var nonBubblingEvents = map[string]bool{
+	"focus":      true,
+	"blur":       true,
+	"mouseenter": true,
+	"mouseleave": true,
+	"load":       true,
+	"error":      true,
+	"scroll":     true,
+}
+
+// On installs a single delegated listener on the element that calls handler
+// whenever an event of the given type originates inside a descendant matching
+// selector. The handler receives the matched descendant and the event
+func (e Element) On(eventType, selector string, handler func(match Element, event Event)) *Listener {
+	if !e.Exists() {
+		return nil
+	}
+
+	container := e.El
+
+	return e.Scope().Listen(newCaptureListener(container, eventType, func(event Value) {
+		target := event.Get("target")
+
+		// Events can originate from text nodes, which have no closest()
+		if !target.IsNull() && !target.IsUndefined() && target.Get("nodeType").Int() != 1 {
+			target = target.Get("parentElement")
+		}
+		if target.IsNull() || target.IsUndefined() {
+			return
+		}
+
+		match := target.Call("closest", selector)
+		if match.IsNull() || !container.Call("contains", match).Bool() {
+			return
+		}
+
+		handler(Element{El: match}, Event{EventObj: event})
+	}, nonBubblingEvents[eventType]))
+}
+
+// Closest returns the nearest ancestor (or the element itself) matching
+// selector, or an empty Element if there is none
+func (e Element) Closest(selector string) Element {
+	element, _ := e.TryClosest(selector)
+	return element
+}
+
+// TryClosest returns the nearest ancestor matching selector and whether one was found
+func (e Element) TryClosest(selector string) (Element, bool) {
+	if !e.Exists() {
+		return Element{}, false
+	}
+
+	element := wrapElement(e.El.Call("closest", selector))
+	return element, element.Exists()
+}
+
+// Matches reports whether the element matches selector
+func (e Element) Matches(selector string) bool {
+	if !e.Exists() {
+		return false
+	}
+
+	return e.El.Call("matches", selector).Bool()
+}
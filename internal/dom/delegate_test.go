@@ -0,0 +1,69 @@
+package dom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnPassesTheMatchedDescendant(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<ul><li data-id="a"><span>one</span><button class="delete">x</button></li></ul>`)
+	var got []string
+	root.On("click", "li", func(item Element, event Event) {
+		got = append(got, item.GetAttribute("data-id")+" "+event.Target().El.Get("tagName").String())
+	})
+	root.On("click", ".delete", func(button Element, _ Event) {
+		got = append(got, "delete "+button.Closest("li").GetAttribute("data-id"))
+	})
+
+	root.QuerySelector("span").El.Call("click")
+	root.QuerySelector(".delete").El.Call("click")
+
+	want := []string{"a SPAN", "a BUTTON", "delete a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("handled %q, want %q", got, want)
+	}
+}
+
+func TestOnIgnoresMatchesOutsideTheContainer(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<p>text</p>`)
+	calls := 0
+	root.On("click", "body", func(Element, Event) { calls++ })
+
+	root.QuerySelector("p").El.Call("click")
+	if calls != 0 {
+		t.Errorf("handler ran %d times for an ancestor of the container", calls)
+	}
+}
+
+func TestOnStartsFromTheParentOfTextNodes(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<b>bold</b>`)
+	var matched Element
+	root.On("custom", "b", func(match Element, _ Event) { matched = match })
+
+	text := root.QuerySelector("b").El.Get("firstChild")
+	text.Call("dispatchEvent", Global().Get("Event").New("custom", map[string]interface{}{"bubbles": true}))
+	if !matched.Exists() || matched.GetText() != "bold" {
+		t.Error("event from a text node not delegated to its element")
+	}
+}
+
+func TestOnDelegatesNonBubblingEvents(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<input class="edit"><input class="other">`)
+	var focused []string
+	listener := root.On("focus", ".edit", func(input Element, _ Event) {
+		focused = append(focused, input.GetAttribute("class"))
+	})
+
+	root.QuerySelector(".edit").Focus()
+	root.QuerySelector(".other").Focus()
+	listener.Remove()
+	root.QuerySelector(".edit").Focus()
+
+	if !reflect.DeepEqual(focused, []string{"edit"}) {
+		t.Errorf("focus handled for %q, want [edit]", focused)
+	}
+}
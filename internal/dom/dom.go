@@ -1,13 +1,8 @@
-//go:build js && wasm
-// +build js,wasm
-
 // Package dom provides an enhanced JavaScript-like DOM manipulation interface for Go WebAssembly
 package dom
 
 import (
-	"fmt"
 	"strconv"
-	"syscall/js"
 	"time"
 )
 
@@ -21,41 +16,48 @@ func Document() DOM {
 
 // Element represents a DOM element with JS-like methods
 type Element struct {
-	El js.Value
+	El Value
 }
 
 // Style represents a DOM element's style object
 type Style struct {
-	StyleObj js.Value
+	StyleObj Value
 }
 
 // ClassList represents a DOM element's classList object
 type ClassList struct {
-	ClassListObj js.Value
+	ClassListObj Value
 }
 
-// Animation represents a CSS animation controller
-type Animation struct {
-	AnimObj js.Value
+// wrapElement wraps a JS value, mapping null and undefined to the empty Element
+func wrapElement(value Value) Element {
+	if value.IsNull() || value.IsUndefined() {
+		return Element{}
+	}
+	return Element{El: value}
 }
 
-// QuerySelector mimics JS document.querySelector
+// QuerySelector mimics JS document.querySelector. It returns an empty Element
+// when nothing matches; use TryQuerySelector to detect that case
 func (d DOM) QuerySelector(selector string) Element {
-	return Element{
-		El: js.Global().Get("document").Call("querySelector", selector),
-	}
+	element, _ := d.TryQuerySelector(selector)
+	return element
+}
+
+// TryQuerySelector returns the first element matching selector and whether one was found
+func (d DOM) TryQuerySelector(selector string) (Element, bool) {
+	element := wrapElement(Global().Get("document").Call("querySelector", selector))
+	return element, element.Exists()
 }
 
 // QuerySelectorAll mimics JS document.querySelectorAll
 func (d DOM) QuerySelectorAll(selector string) []Element {
-	nodeList := js.Global().Get("document").Call("querySelectorAll", selector)
+	nodeList := Global().Get("document").Call("querySelectorAll", selector)
 	length := nodeList.Get("length").Int()
 	elements := make([]Element, length)
 
 	for i := 0; i < length; i++ {
-		elements[i] = Element{
-			El: nodeList.Call("item", i),
-		}
+		elements[i] = wrapElement(nodeList.Call("item", i))
 	}
 
 	return elements
@@ -64,25 +66,73 @@ func (d DOM) QuerySelectorAll(selector string) []Element {
 // CreateElement creates a new DOM element
 func (d DOM) CreateElement(tag string) Element {
 	return Element{
-		El: js.Global().Get("document").Call("createElement", tag),
+		El: Global().Get("document").Call("createElement", tag),
 	}
 }
 
-// GetElementById returns an element by its ID
+// GetElementById returns an element by its ID, or an empty Element if there is none
 func (d DOM) GetElementById(id string) Element {
-	return Element{
-		El: js.Global().Get("document").Call("getElementById", id),
-	}
+	element, _ := d.TryGetElementById(id)
+	return element
+}
+
+// TryGetElementById returns the element with the given ID and whether it exists
+func (d DOM) TryGetElementById(id string) (Element, bool) {
+	element := wrapElement(Global().Get("document").Call("getElementById", id))
+	return element, element.Exists()
 }
 
+// ElementFromPoint returns the topmost element at the given point of the
+// viewport, or an empty Element if there is none
+func (d DOM) ElementFromPoint(x, y float64) Element {
+	return wrapElement(Global().Get("document").Call("elementFromPoint", x, y))
+}
+
+// Exists reports whether the element refers to an actual DOM node. Lookups
+// that find nothing return an empty Element on which every method is a no-op
+func (e Element) Exists() bool {
+	return !isNullish(e.El)
+}
+
+// QuerySelector returns the first descendant matching selector, or an empty Element
 func (e Element) QuerySelector(selector string) Element {
-	return Element{
-		El: e.El.Call("querySelector", selector),
+	element, _ := e.TryQuerySelector(selector)
+	return element
+}
+
+// TryQuerySelector returns the first descendant matching selector and whether one was found
+func (e Element) TryQuerySelector(selector string) (Element, bool) {
+	if !e.Exists() {
+		return Element{}, false
 	}
+
+	element := wrapElement(e.El.Call("querySelector", selector))
+	return element, element.Exists()
+}
+
+// QuerySelectorAll returns every descendant matching selector
+func (e Element) QuerySelectorAll(selector string) []Element {
+	if !e.Exists() {
+		return []Element{}
+	}
+
+	nodeList := e.El.Call("querySelectorAll", selector)
+	length := nodeList.Get("length").Int()
+	elements := make([]Element, length)
+
+	for i := 0; i < length; i++ {
+		elements[i] = wrapElement(nodeList.Call("item", i))
+	}
+
+	return elements
 }
 
 // Style returns the element's style object
 func (e Element) Style() Style {
+	if !e.Exists() {
+		return Style{}
+	}
+
 	return Style{
 		StyleObj: e.El.Get("style"),
 	}
@@ -90,6 +140,10 @@ func (e Element) Style() Style {
 
 // ClassList returns the element's classList object
 func (e Element) ClassList() ClassList {
+	if !e.Exists() {
+		return ClassList{}
+	}
+
 	return ClassList{
 		ClassListObj: e.El.Get("classList"),
 	}
@@ -97,298 +151,440 @@ func (e Element) ClassList() ClassList {
 
 // SetText sets the element's textContent property
 func (e Element) SetText(content string) Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Set("textContent", content)
 	return e
 }
 
 // GetText gets the element's textContent
 func (e Element) GetText() string {
+	if !e.Exists() {
+		return ""
+	}
+
 	return e.El.Get("textContent").String()
 }
 
 // SetHTML sets the element's innerHTML property
 func (e Element) SetHTML(content string) Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Set("innerHTML", content)
 	return e
 }
 
 // GetHTML gets the element's innerHTML
 func (e Element) GetHTML() string {
+	if !e.Exists() {
+		return ""
+	}
+
 	return e.El.Get("innerHTML").String()
 }
 
 // SetAttribute sets an attribute on the element
 func (e Element) SetAttribute(name, value string) Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Call("setAttribute", name, value)
 	return e
 }
 
-// GetAttribute gets an attribute from the element
+// GetAttribute gets an attribute from the element, or "" if it is not set
 func (e Element) GetAttribute(name string) string {
-	return e.El.Call("getAttribute", name).String()
+	if !e.Exists() {
+		return ""
+	}
+
+	value := e.El.Call("getAttribute", name)
+	if value.IsNull() {
+		return ""
+	}
+	return value.String()
 }
 
 // HasAttribute checks if the element has an attribute
 func (e Element) HasAttribute(name string) bool {
+	if !e.Exists() {
+		return false
+	}
+
 	return e.El.Call("hasAttribute", name).Bool()
 }
 
 // RemoveAttribute removes an attribute from the element
 func (e Element) RemoveAttribute(name string) Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Call("removeAttribute", name)
 	return e
 }
 
 // GetValue gets the value of an input element
 func (e Element) GetValue() string {
+	if !e.Exists() {
+		return ""
+	}
+
 	return e.El.Get("value").String()
 }
 
 // SetValue sets the value of an input element
 func (e Element) SetValue(value string) Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Set("value", value)
 	return e
 }
 
 // Focus focuses the element
 func (e Element) Focus() Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Call("focus")
 	return e
 }
 
 // Blur removes focus from the element
 func (e Element) Blur() Element {
+	if !e.Exists() {
+		return e
+	}
+
 	e.El.Call("blur")
 	return e
 }
 
 // AppendChild appends a child element
 func (e Element) AppendChild(child Element) Element {
+	if !e.Exists() || !child.Exists() {
+		return e
+	}
+
 	e.El.Call("appendChild", child.El)
 	return e
 }
 
 // RemoveChild removes a child element
 func (e Element) RemoveChild(child Element) Element {
+	if !e.Exists() || !child.Exists() {
+		return e
+	}
+
 	e.El.Call("removeChild", child.El)
 	return e
 }
 
-// GetRect gets the element's bounding rectangle
-func (e Element) GetRect() map[string]float64 {
-	rect := e.El.Call("getBoundingClientRect")
-	return map[string]float64{
-		"top":    rect.Get("top").Float(),
-		"right":  rect.Get("right").Float(),
-		"bottom": rect.Get("bottom").Float(),
-		"left":   rect.Get("left").Float(),
-		"width":  rect.Get("width").Float(),
-		"height": rect.Get("height").Float(),
+// Rect is a box in CSS pixels, relative to the viewport when it comes from
+// GetRect
+type Rect struct {
+	X, Y          float64
+	Width, Height float64
+	Top, Right    float64
+	Bottom, Left  float64
+}
+
+// rectOf converts a DOMRect
+func rectOf(value Value) Rect {
+	if isNullish(value) {
+		return Rect{}
+	}
+
+	return Rect{
+		X:      value.Get("x").Float(),
+		Y:      value.Get("y").Float(),
+		Width:  value.Get("width").Float(),
+		Height: value.Get("height").Float(),
+		Top:    value.Get("top").Float(),
+		Right:  value.Get("right").Float(),
+		Bottom: value.Get("bottom").Float(),
+		Left:   value.Get("left").Float(),
 	}
 }
 
-// AddEventListener adds an event listener to the element with a callback
-func (e Element) AddEventListener(event string, fn func()) Element {
-	callback := js.FuncOf(func(_ js.Value, _ []js.Value) interface{} {
-		fn()
-		return nil
-	})
+// Contains reports whether the point is inside the box
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.Left && x < r.Right && y >= r.Top && y < r.Bottom
+}
 
-	// Store callback to prevent garbage collection
-	// This is a simplified approach - in a real app you'd need a way to
-	// manage and remove these callbacks to prevent memory leaks
-	e.El.Call("addEventListener", event, callback)
-	return e
+// Intersects reports whether the boxes overlap
+func (r Rect) Intersects(other Rect) bool {
+	return r.Left < other.Right && other.Left < r.Right && r.Top < other.Bottom && other.Top < r.Bottom
 }
 
-// AddEventListenerWithEvent adds an event listener with the event object
-func (e Element) AddEventListenerWithEvent(event string, fn func(js.Value)) Element {
-	callback := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
-		if len(args) > 0 {
-			fn(args[0])
-		}
-		return nil
-	})
+// GetRect gets the element's bounding rectangle relative to the viewport
+func (e Element) GetRect() Rect {
+	if !e.Exists() {
+		return Rect{}
+	}
 
-	// Store callback to prevent garbage collection
-	e.El.Call("addEventListener", event, callback)
-	return e
+	return rectOf(e.El.Call("getBoundingClientRect"))
 }
 
-// RemoveEventListener removes an event listener (simplified without callback reference)
-func (e Element) RemoveEventListener(event string) Element {
-	// Note: This is simplified and won't actually work as expected
-	// because we need the original callback reference
-	return e
+// ScrollIntoViewOptions controls how ScrollIntoViewWithOptions aligns the
+// element. Empty fields use the browser defaults
+type ScrollIntoViewOptions struct {
+	Behavior string // "auto", "smooth" or "instant"
+	Block    string // vertical alignment: "start", "center", "end" or "nearest"
+	Inline   string // horizontal alignment, with the same values as Block
 }
 
-// Animate creates a CSS animation and returns the animation object
-func (e Element) Animate(keyframes []map[string]interface{}, options map[string]interface{}) Animation {
-	// Convert Go maps to JS objects
-	jsKeyframes := js.Global().Get("Array").New(len(keyframes))
-	for i, keyframe := range keyframes {
-		jsKeyframe := js.Global().Get("Object").New()
-		for key, value := range keyframe {
-			jsKeyframe.Set(key, value)
-		}
-		jsKeyframes.SetIndex(i, jsKeyframe)
+// ScrollIntoView scrolls the element's ancestors so its top is visible
+func (e Element) ScrollIntoView() {
+	if e.Exists() {
+		e.El.Call("scrollIntoView")
 	}
+}
 
-	jsOptions := js.Global().Get("Object").New()
-	for key, value := range options {
-		jsOptions.Set(key, value)
+// ScrollIntoViewWithOptions scrolls the element's ancestors so it is visible
+// with the given alignment
+func (e Element) ScrollIntoViewWithOptions(options ScrollIntoViewOptions) {
+	if !e.Exists() {
+		return
 	}
 
-	return Animation{
-		AnimObj: e.El.Call("animate", jsKeyframes, jsOptions),
+	jsOptions := Global().Get("Object").New()
+	if options.Behavior != "" {
+		jsOptions.Set("behavior", options.Behavior)
+	}
+	if options.Block != "" {
+		jsOptions.Set("block", options.Block)
 	}
+	if options.Inline != "" {
+		jsOptions.Set("inline", options.Inline)
+	}
+	e.El.Call("scrollIntoView", jsOptions)
 }
 
-// AnimateWithOptions animates with preset options
-func (e Element) AnimateWithOptions(animationType string, duration int) Animation {
-	var keyframes []map[string]interface{}
-	var options map[string]interface{}
+// ScrollTop gets the vertical scroll position of the element's content
+func (e Element) ScrollTop() float64 {
+	return e.floatProperty("scrollTop")
+}
 
-	switch animationType {
-	case "fadeIn":
-		keyframes = []map[string]interface{}{
-			{"opacity": 0},
-			{"opacity": 1},
-		}
-	case "fadeOut":
-		keyframes = []map[string]interface{}{
-			{"opacity": 1},
-			{"opacity": 0},
-		}
-	case "slideIn":
-		keyframes = []map[string]interface{}{
-			{"transform": "translateX(-20px)", "opacity": 0},
-			{"transform": "translateX(0)", "opacity": 1},
-		}
-	case "slideOut":
-		keyframes = []map[string]interface{}{
-			{"transform": "translateX(0)", "opacity": 1},
-			{"transform": "translateX(20px)", "opacity": 0},
-		}
-	case "slideInUp":
-		keyframes = []map[string]interface{}{
-			{"transform": "translateY(20px)", "opacity": 0},
-			{"transform": "translateY(0)", "opacity": 1},
-		}
-	case "slideOutDown":
-		keyframes = []map[string]interface{}{
-			{"transform": "translateY(0)", "opacity": 1},
-			{"transform": "translateY(20px)", "opacity": 0},
-		}
-	case "shake":
-		keyframes = []map[string]interface{}{
-			{"transform": "translateX(0)"},
-			{"transform": "translateX(-5px)"},
-			{"transform": "translateX(5px)"},
-			{"transform": "translateX(-5px)"},
-			{"transform": "translateX(5px)"},
-			{"transform": "translateX(0)"},
-		}
+// SetScrollTop scrolls the element's content vertically
+func (e Element) SetScrollTop(top float64) Element {
+	if e.Exists() {
+		e.El.Set("scrollTop", top)
 	}
+	return e
+}
+
+// ScrollLeft gets the horizontal scroll position of the element's content
+func (e Element) ScrollLeft() float64 {
+	return e.floatProperty("scrollLeft")
+}
 
-	options = map[string]interface{}{
-		"duration": duration,
-		"easing":   "ease-in-out",
-		"fill":     "forwards",
+// SetScrollLeft scrolls the element's content horizontally
+func (e Element) SetScrollLeft(left float64) Element {
+	if e.Exists() {
+		e.El.Set("scrollLeft", left)
 	}
+	return e
+}
 
-	return e.Animate(keyframes, options)
+// ScrollWidth gets the width of the element's content, including the part
+// scrolled out of view
+func (e Element) ScrollWidth() float64 {
+	return e.floatProperty("scrollWidth")
 }
 
-// Play starts the animation
-func (a Animation) Play() {
-	a.AnimObj.Call("play")
+// ScrollHeight gets the height of the element's content, including the part
+// scrolled out of view
+func (e Element) ScrollHeight() float64 {
+	return e.floatProperty("scrollHeight")
 }
 
-// Pause pauses the animation
-func (a Animation) Pause() {
-	a.AnimObj.Call("pause")
+// OffsetWidth gets the layout width of the element, including borders
+func (e Element) OffsetWidth() float64 {
+	return e.floatProperty("offsetWidth")
 }
 
-// Cancel cancels the animation
-func (a Animation) Cancel() {
-	a.AnimObj.Call("cancel")
+// OffsetHeight gets the layout height of the element, including borders
+func (e Element) OffsetHeight() float64 {
+	return e.floatProperty("offsetHeight")
 }
 
-// Finish finishes the animation
-func (a Animation) Finish() {
-	a.AnimObj.Call("finish")
+// OffsetTop gets the distance from the top of the offset parent
+func (e Element) OffsetTop() float64 {
+	return e.floatProperty("offsetTop")
 }
 
-// OnFinish adds a callback to be executed when the animation finishes
-func (a Animation) OnFinish(fn func()) {
-	// Check if AnimObj is valid first
-	if a.AnimObj.IsNull() || a.AnimObj.IsUndefined() {
-		fmt.Println("Warning: Animation object is null or undefined")
-		// Execute the function immediately as a fallback
-		fn()
-		return
+// OffsetLeft gets the distance from the left of the offset parent
+func (e Element) OffsetLeft() float64 {
+	return e.floatProperty("offsetLeft")
+}
+
+// ClientWidth gets the inner width of the element, without borders and
+// scrollbars
+func (e Element) ClientWidth() float64 {
+	return e.floatProperty("clientWidth")
+}
+
+// ClientHeight gets the inner height of the element, without borders and
+// scrollbars
+func (e Element) ClientHeight() float64 {
+	return e.floatProperty("clientHeight")
+}
+
+// floatProperty reads a numeric property, returning 0 for an empty Element
+func (e Element) floatProperty(name string) float64 {
+	if !e.Exists() {
+		return 0
 	}
+	return e.El.Get(name).Float()
+}
 
-	// Create the callback
-	callback := js.FuncOf(func(_ js.Value, _ []js.Value) interface{} {
+// AddEventListener adds an event listener to the element with a callback.
+// The listener is owned by the element's scope and released with it
+func (e Element) AddEventListener(event string, fn func()) *Listener {
+	if !e.Exists() {
+		return nil
+	}
+
+	return e.Scope().Listen(newListener(e.El, event, func(_ Value) {
 		fn()
+	}))
+}
+
+// AddEventListenerWithEvent adds an event listener with the event object
+func (e Element) AddEventListenerWithEvent(event string, fn func(Event)) *Listener {
+	if !e.Exists() {
 		return nil
-	})
+	}
 
-	// Instead of trying to access 'onfinish' property's addEventListener,
-	// set the onfinish property directly to the callback
-	a.AnimObj.Set("onfinish", callback)
+	return e.Scope().Listen(newListener(e.El, event, func(value Value) {
+		fn(Event{EventObj: value})
+	}))
+}
+
+// RemoveEventListener removes every listener for the event type that was
+// attached to the element through this package
+func (e Element) RemoveEventListener(event string) Element {
+	if !e.Exists() {
+		return e
+	}
+
+	e.Scope().RemoveListeners(event)
+	return e
+}
+
+// valid reports whether the class list wraps a real classList object
+func (c ClassList) valid() bool {
+	return !isNullish(c.ClassListObj)
 }
 
 // Add adds a class to the element
 func (c ClassList) Add(className string) ClassList {
+	if !c.valid() {
+		return c
+	}
+
 	c.ClassListObj.Call("add", className)
 	return c
 }
 
 // Remove removes a class from the element
 func (c ClassList) Remove(className string) ClassList {
+	if !c.valid() {
+		return c
+	}
+
 	c.ClassListObj.Call("remove", className)
 	return c
 }
 
 // Toggle toggles a class on the element
 func (c ClassList) Toggle(className string) ClassList {
+	if !c.valid() {
+		return c
+	}
+
 	c.ClassListObj.Call("toggle", className)
 	return c
 }
 
 // Contains checks if the element has a class
 func (c ClassList) Contains(className string) bool {
+	if !c.valid() {
+		return false
+	}
+
 	return c.ClassListObj.Call("contains", className).Bool()
 }
 
+// valid reports whether the style wraps a real style object
+func (s Style) valid() bool {
+	return !isNullish(s.StyleObj)
+}
+
 // Display sets the element's style.display property
 func (s Style) Display(value string) Style {
+	if !s.valid() {
+		return s
+	}
+
 	s.StyleObj.Set("display", value)
 	return s
 }
 
 // SetProperty sets any style property
 func (s Style) SetProperty(property, value string) Style {
+	if !s.valid() {
+		return s
+	}
+
 	s.StyleObj.Set(property, value)
 	return s
 }
 
 // GetProperty gets a style property
 func (s Style) GetProperty(property string) string {
+	if !s.valid() {
+		return ""
+	}
+
 	return s.StyleObj.Get(property).String()
 }
 
 // SetVariable sets a CSS variable
 func (s Style) SetVariable(name, value string) Style {
+	if !s.valid() {
+		return s
+	}
+
 	s.StyleObj.Call("setProperty", "--"+name, value)
 	return s
 }
 
+// RemoveVariable removes a CSS variable set on the element
+func (s Style) RemoveVariable(name string) Style {
+	if !s.valid() {
+		return s
+	}
+
+	s.StyleObj.Call("removeProperty", "--"+name)
+	return s
+}
+
 // GetVariable gets a CSS variable
 func (s Style) GetVariable(name string) string {
+	if !s.valid() {
+		return ""
+	}
+
 	return s.StyleObj.Call("getPropertyValue", "--"+name).String()
 }
 
@@ -417,36 +613,106 @@ func GetWindow() Window {
 	return Window{}
 }
 
-// SetTimeout executes a function after a specified delay
-func (w Window) SetTimeout(fn func(), delayMs int) js.Value {
-	callback := js.FuncOf(func(_ js.Value, _ []js.Value) interface{} {
+// SetTimeout executes a function after a specified delay. The callback is
+// released once it has run or the timer is cleared
+func (w Window) SetTimeout(fn func(), delayMs int) *Timer {
+	timer := &Timer{active: true}
+	timer.callback = FuncOf(func(_ Value, _ []Value) interface{} {
+		timer.active = false
+		timer.callback.Release()
 		fn()
 		return nil
 	})
 
-	// Store callback to prevent garbage collection
-	return js.Global().Call("setTimeout", callback, delayMs)
+	timer.id = Global().Call("setTimeout", timer.callback, delayMs)
+	return timer
 }
 
 // ClearTimeout clears a timeout
-func (w Window) ClearTimeout(timeoutID js.Value) {
-	js.Global().Call("clearTimeout", timeoutID)
+func (w Window) ClearTimeout(timer *Timer) {
+	timer.Clear()
 }
 
-// SetInterval executes a function at specified intervals
-func (w Window) SetInterval(fn func(), intervalMs int) js.Value {
-	callback := js.FuncOf(func(_ js.Value, _ []js.Value) interface{} {
+// SetInterval executes a function at specified intervals until cleared
+func (w Window) SetInterval(fn func(), intervalMs int) *Timer {
+	timer := &Timer{active: true, interval: true}
+	timer.callback = FuncOf(func(_ Value, _ []Value) interface{} {
 		fn()
 		return nil
 	})
 
-	// Store callback to prevent garbage collection
-	return js.Global().Call("setInterval", callback, intervalMs)
+	timer.id = Global().Call("setInterval", timer.callback, intervalMs)
+	return timer
 }
 
 // ClearInterval clears an interval
-func (w Window) ClearInterval(intervalID js.Value) {
-	js.Global().Call("clearInterval", intervalID)
+func (w Window) ClearInterval(timer *Timer) {
+	timer.Clear()
+}
+
+// RequestAnimationFrame calls fn before the next repaint with the frame's
+// timestamp in milliseconds. The callback is released once it has run or the
+// frame is cancelled
+func (w Window) RequestAnimationFrame(fn func(timestamp float64)) *Timer {
+	timer := &Timer{active: true, frame: true}
+	timer.callback = FuncOf(func(_ Value, args []Value) interface{} {
+		timer.active = false
+		timer.callback.Release()
+
+		timestamp := 0.0
+		if len(args) > 0 && args[0].Type() == TypeNumber {
+			timestamp = args[0].Float()
+		}
+		fn(timestamp)
+		return nil
+	})
+
+	timer.id = Global().Call("requestAnimationFrame", timer.callback)
+	return timer
+}
+
+// CancelAnimationFrame cancels a frame requested with RequestAnimationFrame
+func (w Window) CancelAnimationFrame(timer *Timer) {
+	timer.Clear()
+}
+
+// InnerWidth returns the width of the viewport
+func (w Window) InnerWidth() float64 {
+	return Global().Get("innerWidth").Float()
+}
+
+// InnerHeight returns the height of the viewport
+func (w Window) InnerHeight() float64 {
+	return Global().Get("innerHeight").Float()
+}
+
+// ScrollX returns how far the document is scrolled horizontally
+func (w Window) ScrollX() float64 {
+	return Global().Get("scrollX").Float()
+}
+
+// ScrollY returns how far the document is scrolled vertically
+func (w Window) ScrollY() float64 {
+	return Global().Get("scrollY").Float()
+}
+
+// ScrollTo scrolls the document to the given position
+func (w Window) ScrollTo(x, y float64) {
+	Global().Call("scrollTo", x, y)
+}
+
+// ScrollBy scrolls the document by the given amounts
+func (w Window) ScrollBy(dx, dy float64) {
+	Global().Call("scrollBy", dx, dy)
+}
+
+// DevicePixelRatio returns the number of device pixels per CSS pixel
+func (w Window) DevicePixelRatio() float64 {
+	ratio := Global().Get("devicePixelRatio")
+	if ratio.Type() != TypeNumber {
+		return 1
+	}
+	return ratio.Float()
 }
 
 // GetLocalStorage returns the localStorage object
@@ -461,82 +727,31 @@ func (w Window) GetSessionStorage() Storage {
 
 // Alert displays an alert dialog
 func (w Window) Alert(message string) {
-	js.Global().Call("alert", message)
+	Global().Call("alert", message)
 }
 
 // Confirm displays a confirm dialog
 func (w Window) Confirm(message string) bool {
-	return js.Global().Call("confirm", message).Bool()
+	return Global().Call("confirm", message).Bool()
 }
 
 // Prompt displays a prompt dialog
 func (w Window) Prompt(message, defaultValue string) string {
-	return js.Global().Call("prompt", message, defaultValue).String()
+	return Global().Call("prompt", message, defaultValue).String()
 }
 
 // AddEventListener adds an event listener to the window
-func (w Window) AddEventListener(event string, fn func()) {
-	callback := js.FuncOf(func(_ js.Value, _ []js.Value) interface{} {
+func (w Window) AddEventListener(event string, fn func()) *Listener {
+	return newListener(Global(), event, func(_ Value) {
 		fn()
-		return nil
 	})
-
-	// Store callback to prevent garbage collection
-	js.Global().Call("addEventListener", event, callback)
 }
 
 // AddEventListenerWithEvent adds an event listener to the window with the event object
-func (w Window) AddEventListenerWithEvent(event string, fn func(js.Value)) {
-	callback := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
-		if len(args) > 0 {
-			fn(args[0])
-		}
-		return nil
+func (w Window) AddEventListenerWithEvent(event string, fn func(Event)) *Listener {
+	return newListener(Global(), event, func(value Value) {
+		fn(Event{EventObj: value})
 	})
-
-	// Store callback to prevent garbage collection
-	js.Global().Call("addEventListener", event, callback)
-}
-
-// ThemeSwitcher manages theme switching
-type ThemeSwitcher struct {
-	CurrentTheme string
-	IsDarkMode   bool
-}
-
-// NewThemeSwitcher creates a new theme switcher
-func NewThemeSwitcher() ThemeSwitcher {
-	return ThemeSwitcher{
-		CurrentTheme: "blue",
-		IsDarkMode:   false,
-	}
-}
-
-// SetTheme sets the current theme
-func (t *ThemeSwitcher) SetTheme(theme string) {
-	t.CurrentTheme = theme
-	body := Document().QuerySelector("body")
-
-	// Remove all theme classes
-	body.ClassList().Remove("theme-blue")
-	body.ClassList().Remove("theme-green")
-	body.ClassList().Remove("theme-purple")
-	body.ClassList().Remove("theme-orange")
-
-	// Add current theme class
-	body.ClassList().Add("theme-" + theme)
-}
-
-// ToggleDarkMode toggles dark mode
-func (t *ThemeSwitcher) ToggleDarkMode() {
-	t.IsDarkMode = !t.IsDarkMode
-	body := Document().QuerySelector("body")
-
-	if t.IsDarkMode {
-		body.ClassList().Add("dark-theme")
-	} else {
-		body.ClassList().Remove("dark-theme")
-	}
 }
 
 // SetAnimationSpeed sets the animation speed
@@ -576,75 +791,3 @@ func SetFontSize(size string) {
 		root.SetVariable("font-size-base", "18px")
 	}
 }
-
-// DragDropManager manages drag and drop functionality
-type DragDropManager struct {
-	DragElement Element
-	DropTargets []Element
-	OnDrop      func(source, target Element)
-	IsDragging  bool
-	OriginalPos map[string]float64
-	OffsetX     float64
-	OffsetY     float64
-}
-
-// NewDragDropManager creates a new drag and drop manager
-func NewDragDropManager() DragDropManager {
-	return DragDropManager{
-		DropTargets: []Element{},
-		IsDragging:  false,
-		OriginalPos: map[string]float64{},
-	}
-}
-
-// MakeDraggable makes an element draggable
-func (d *DragDropManager) MakeDraggable(element Element) {
-	element.SetAttribute("draggable", "true")
-
-	element.AddEventListenerWithEvent("dragstart", func(event js.Value) {
-		d.DragElement = element
-		d.IsDragging = true
-
-		// Store original position
-		rect := element.GetRect()
-		d.OriginalPos["top"] = rect["top"]
-		d.OriginalPos["left"] = rect["left"]
-
-		// Calculate offset
-		d.OffsetX = event.Get("clientX").Float() - rect["left"]
-		d.OffsetY = event.Get("clientY").Float() - rect["top"]
-
-		// Add dragging class
-		element.ClassList().Add("dragging")
-	})
-
-	element.AddEventListenerWithEvent("dragend", func(_ js.Value) {
-		d.IsDragging = false
-		element.ClassList().Remove("dragging")
-	})
-}
-
-// AddDropTarget adds a drop target
-func (d *DragDropManager) AddDropTarget(target Element, onDrop func(source, target Element)) {
-	d.DropTargets = append(d.DropTargets, target)
-	d.OnDrop = onDrop
-
-	target.AddEventListenerWithEvent("dragover", func(event js.Value) {
-		event.Call("preventDefault")
-		target.ClassList().Add("drag-over")
-	})
-
-	target.AddEventListenerWithEvent("dragleave", func(event js.Value) {
-		event.Call("preventDefault")
-		target.ClassList().Remove("drag-over")
-	})
-
-	target.AddEventListenerWithEvent("drop", func(event js.Value) {
-		event.Call("preventDefault")
-		target.ClassList().Remove("drag-over")
-
-		if d.IsDragging && d.OnDrop != nil {
-			d.OnDrop(d.DragElement, target)
-		}
-	})
-}
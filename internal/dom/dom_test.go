@@ -0,0 +1,59 @@
+package dom
+
+import "testing"
+
+func TestLookupsReportAbsence(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<p id="lookup-found" class="found"></p>`)
+
+	if _, ok := Document().TryQuerySelector(".found"); !ok {
+		t.Error("TryQuerySelector missed an element")
+	}
+	if _, ok := Document().TryGetElementById("lookup-found"); !ok {
+		t.Error("TryGetElementById missed an element")
+	}
+	if element, ok := Document().TryQuerySelector(".missing"); ok || element.Exists() {
+		t.Error("document lookup found a missing element")
+	}
+	if element, ok := Document().TryGetElementById("lookup-missing"); ok || element.Exists() {
+		t.Error("id lookup found a missing element")
+	}
+	if element, ok := root.TryQuerySelector("span"); ok || element.Exists() {
+		t.Error("element lookup found a missing element")
+	}
+	if element, ok := root.TryClosest("ul"); ok || element.Exists() {
+		t.Error("closest found a missing ancestor")
+	}
+}
+
+func TestEmptyElementIsANoOp(t *testing.T) {
+	missing := Document().QuerySelector(".missing")
+	if missing.Exists() {
+		t.Fatal("lookup of a missing element exists")
+	}
+
+	missing.SetText("text").SetHTML("<b></b>").SetAttribute("title", "x").RemoveAttribute("title").
+		SetValue("v").Focus().Blur().AppendChild(Document().CreateElement("b")).RemoveChild(Element{})
+	missing.ClassList().Add("a").Remove("a").Toggle("b")
+	missing.Style().Display("none").SetProperty("color", "red").SetVariable("gap", "1px").RemoveVariable("gap")
+	missing.SetScrollTop(10).SetScrollLeft(10)
+	missing.ScrollIntoView()
+	missing.ScrollIntoViewWithOptions(ScrollIntoViewOptions{Block: "center"})
+
+	if missing.GetText() != "" || missing.GetHTML() != "" || missing.GetAttribute("title") != "" || missing.HasAttribute("title") ||
+		missing.GetValue() != "" || missing.ClassList().Contains("a") || missing.Style().GetProperty("color") != "" ||
+		missing.Style().GetVariable("gap") != "" || missing.Matches("p") || missing.Closest("body").Exists() {
+		t.Error("empty element returned a value")
+	}
+	if len(missing.QuerySelectorAll("*")) != 0 || missing.QuerySelector("*").Exists() || missing.GetRect() != (Rect{}) || missing.ScrollTop() != 0 {
+		t.Error("empty element has contents")
+	}
+}
+
+func TestAppendChildIgnoresEmptyChildren(t *testing.T) {
+	root := newContainer(t)
+	root.AppendChild(Element{}).AppendChild(Document().QuerySelector(".missing"))
+	if root.El.Get("childNodes").Length() != 0 {
+		t.Error("empty child appended")
+	}
+}
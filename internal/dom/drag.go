@@ -0,0 +1,367 @@
+package dom
+
+import "math"
+
+// DragOptions configures a Draggable. Empty fields use the defaults
+type DragOptions struct {
+	// Handle matches the part of an item that starts a drag, such as a grip
+	// icon. The whole item does when empty. On touch screens the handle, or
+	// the item, needs touch-action: none so the browser doesn't scroll instead
+	Handle string
+	// Threshold is how far in pixels the pointer must move before a press
+	// becomes a drag, 5 by default. Shorter presses remain clicks
+	Threshold float64
+	// ScrollContainer is scrolled while the pointer is near its edges, the
+	// window when empty
+	ScrollContainer Element
+	ScrollMargin    float64 // distance from an edge where scrolling starts, 40 by default
+	ScrollSpeed     float64 // pixels scrolled per frame at the very edge, 15 by default
+	GhostClass      string  // class of the ghost, "drag-ghost" by default
+	DraggingClass   string  // class of the item being dragged, "dragging" by default
+
+	OnStart  func(drag *DragState) // the pointer moved past the threshold
+	OnMove   func(drag *DragState) // the pointer or the content under it moved
+	OnDrop   func(drag *DragState) // the pointer was released
+	OnCancel func(drag *DragState) // Escape was pressed or the browser took over the pointer
+
+	// OnKeyboardMove is called when Alt+ArrowUp or Alt+ArrowDown is pressed
+	// on a focused item, with an offset of -1 or 1. Keyboard reordering is off
+	// when nil
+	OnKeyboardMove func(item Element, offset int)
+}
+
+// DragState describes a drag in progress
+type DragState struct {
+	Item  Element // item being dragged
+	Ghost Element // copy of the item following the pointer
+	// Over is the element under the pointer, ignoring the ghost. It may be
+	// outside the container, or empty outside the window
+	Over Element
+
+	StartX, StartY float64 // where the pointer went down, in viewport pixels
+	X, Y           float64 // where the pointer is now
+	PointerType    string  // "mouse", "pen" or "touch"
+}
+
+// Draggable lets the items of a container be dragged with a mouse, pen or
+// finger using pointer events, which unlike HTML5 drag and drop also work on
+// touch screens. Pressing an item and moving past the threshold starts a drag:
+// the item gets DraggingClass, a ghost copy follows the pointer and the
+// container scrolls when the pointer nears its edges. Releasing the pointer
+// drops the item and pressing Escape cancels the drag. What a drop does is up
+// to the callbacks:
+//
+//	dom.NewDraggable(list, "li", dom.DragOptions{
+//		Handle: ".drag-handle",
+//		OnDrop: func(drag *dom.DragState) {
+//			if target := drag.Over.Closest("li"); target.Exists() {
+//				move(drag.Item, target)
+//			}
+//		},
+//	})
+//
+// The listeners belong to the container's scope, so releasing the container
+// releases the Draggable too
+type Draggable struct {
+	container Element
+	selector  string
+	options   DragOptions
+	scope     *Scope
+
+	drag      *DragState // current drag, nil before the threshold is passed
+	pointerID int
+	session   *Scope // listeners and timers of the press or drag
+	scroll    *Timer // frame of the auto-scroll loop
+}
+
+// NewDraggable makes the items of container matching selector draggable. An
+// empty selector makes the container itself the only item
+func NewDraggable(container Element, selector string, options DragOptions) *Draggable {
+	if options.Threshold <= 0 {
+		options.Threshold = 5
+	}
+	if options.ScrollMargin <= 0 {
+		options.ScrollMargin = 40
+	}
+	if options.ScrollSpeed <= 0 {
+		options.ScrollSpeed = 15
+	}
+	if options.GhostClass == "" {
+		options.GhostClass = "drag-ghost"
+	}
+	if options.DraggingClass == "" {
+		options.DraggingClass = "dragging"
+	}
+
+	d := &Draggable{container: container, selector: selector, options: options, scope: NewScope()}
+
+	d.listen("pointerdown", d.press)
+	d.listen("keydown", d.keydown)
+	container.Scope().OnRelease(d.Release)
+
+	return d
+}
+
+// listen calls handler for events of the given type on an item
+func (d *Draggable) listen(eventType string, handler func(item Element, event Event)) {
+	if d.selector != "" {
+		d.scope.Listen(d.container.On(eventType, d.selector, handler))
+		return
+	}
+
+	container := d.container
+	if container.Exists() {
+		d.scope.Listen(container.Scope().Listen(newListener(container.El, eventType, func(event Value) {
+			handler(container, Event{EventObj: event})
+		})))
+	}
+}
+
+// Dragging reports whether an item is being dragged
+func (d *Draggable) Dragging() bool {
+	return d.drag != nil
+}
+
+// Cancel stops the current drag as if Escape had been pressed
+func (d *Draggable) Cancel() {
+	if d.drag != nil {
+		d.finish(d.options.OnCancel)
+	} else {
+		d.end()
+	}
+}
+
+// Release cancels the current drag and removes the listeners
+func (d *Draggable) Release() {
+	d.Cancel()
+	d.scope.Release()
+}
+
+// press starts tracking a pointer that went down on item
+func (d *Draggable) press(item Element, event Event) {
+	mouse := event.Mouse()
+	pointer := event.Pointer()
+	if d.session != nil || mouse.Button() != 0 || !pointer.IsPrimary() {
+		return
+	}
+
+	// Controls inside the item keep working unless the handle is pressed
+	target := event.Target()
+	if d.options.Handle != "" {
+		if handle := target.Closest(d.options.Handle); !handle.Exists() || !item.El.Call("contains", handle.El).Bool() {
+			return
+		}
+	} else if target.Closest("input, textarea, select, button, a, [contenteditable]").Exists() {
+		return
+	}
+
+	d.pointerID = pointer.PointerID()
+	startX, startY := mouse.ClientX(), mouse.ClientY()
+	pointerType := pointer.PointerType()
+
+	document := Global().Get("document")
+	d.session = NewScope()
+	d.session.Listen(newListener(document, "pointermove", func(value Value) {
+		event := Event{EventObj: value}
+		if event.Pointer().PointerID() != d.pointerID {
+			return
+		}
+
+		x, y := event.Mouse().ClientX(), event.Mouse().ClientY()
+		if d.drag == nil {
+			if math.Hypot(x-startX, y-startY) < d.options.Threshold {
+				return
+			}
+			d.start(item, startX, startY, pointerType)
+		}
+
+		// Keep touch screens from scrolling or selecting text while dragging
+		event.PreventDefault()
+		d.move(x, y)
+	}))
+	d.session.Listen(newListener(document, "pointerup", func(value Value) {
+		if (Event{EventObj: value}).Pointer().PointerID() != d.pointerID {
+			return
+		}
+		if d.drag == nil {
+			d.end()
+			return
+		}
+
+		suppressClick()
+		d.finish(d.options.OnDrop)
+	}))
+	d.session.Listen(newListener(document, "pointercancel", func(value Value) {
+		if (Event{EventObj: value}).Pointer().PointerID() == d.pointerID {
+			d.Cancel()
+		}
+	}))
+	d.session.Listen(newListener(document, "keydown", func(value Value) {
+		event := Event{EventObj: value}
+		if d.drag != nil && event.Keyboard().Key() == "Escape" {
+			event.PreventDefault()
+			event.StopPropagation()
+			d.Cancel()
+		}
+	}))
+}
+
+// start turns the press on item into a drag and creates the ghost
+func (d *Draggable) start(item Element, startX, startY float64, pointerType string) {
+	rect := item.GetRect()
+
+	ghost := Element{El: item.El.Call("cloneNode", true)}
+	ghost.RemoveAttribute("id")
+	ghost.SetAttribute("aria-hidden", "true")
+	ghost.ClassList().Add(d.options.GhostClass)
+	ghost.Style().
+		SetProperty("position", "fixed").
+		SetProperty("left", pixels(rect.Left)).
+		SetProperty("top", pixels(rect.Top)).
+		SetProperty("width", pixels(rect.Width)).
+		SetProperty("height", pixels(rect.Height)).
+		SetProperty("margin", "0").
+		SetProperty("pointerEvents", "none").
+		SetProperty("zIndex", "1000")
+	Document().QuerySelector("body").AppendChild(ghost)
+
+	item.ClassList().Add(d.options.DraggingClass)
+
+	d.drag = &DragState{
+		Item:        item,
+		Ghost:       ghost,
+		StartX:      startX,
+		StartY:      startY,
+		X:           startX,
+		Y:           startY,
+		PointerType: pointerType,
+	}
+	if d.options.OnStart != nil {
+		d.options.OnStart(d.drag)
+	}
+}
+
+// move follows the pointer to x, y
+func (d *Draggable) move(x, y float64) {
+	drag := d.drag
+	drag.X, drag.Y = x, y
+	drag.Ghost.Style().SetProperty("transform", "translate("+pixels(x-drag.StartX)+", "+pixels(y-drag.StartY)+")")
+	d.update()
+
+	if d.scrollStep() != 0 && !d.scroll.Active() {
+		d.autoScroll()
+	}
+}
+
+// update finds the element under the pointer and reports the move
+func (d *Draggable) update() {
+	d.drag.Over = Document().ElementFromPoint(d.drag.X, d.drag.Y)
+	if d.options.OnMove != nil {
+		d.options.OnMove(d.drag)
+	}
+}
+
+// scrollStep returns how far to scroll in this frame, negative towards the
+// top, growing as the pointer gets closer to the edge of the scroll container
+func (d *Draggable) scrollStep() float64 {
+	top, bottom := 0.0, GetWindow().InnerHeight()
+	if container := d.options.ScrollContainer; container.Exists() {
+		rect := container.GetRect()
+		top, bottom = rect.Top, rect.Bottom
+	}
+
+	margin := d.options.ScrollMargin
+	switch y := d.drag.Y; {
+	case y < top+margin:
+		return -d.options.ScrollSpeed * math.Min(1, (top+margin-y)/margin)
+	case y > bottom-margin:
+		return d.options.ScrollSpeed * math.Min(1, (y-bottom+margin)/margin)
+	}
+	return 0
+}
+
+// autoScroll scrolls every frame while the pointer stays near an edge. The
+// content moves under a still pointer, so each step reports a move
+func (d *Draggable) autoScroll() {
+	d.scroll = GetWindow().RequestAnimationFrame(func(float64) {
+		if d.drag == nil {
+			return
+		}
+
+		step := d.scrollStep()
+		if step == 0 {
+			return
+		}
+		if container := d.options.ScrollContainer; container.Exists() {
+			container.SetScrollTop(container.ScrollTop() + step)
+		} else {
+			GetWindow().ScrollBy(0, step)
+		}
+
+		d.update()
+		d.autoScroll()
+	})
+}
+
+// finish ends the drag after calling callback, if set
+func (d *Draggable) finish(callback func(drag *DragState)) {
+	drag := d.drag
+	d.end()
+	if callback != nil {
+		callback(drag)
+	}
+}
+
+// end removes the ghost and stops tracking the pointer
+func (d *Draggable) end() {
+	if d.drag != nil {
+		d.drag.Ghost.Remove()
+		d.drag.Item.ClassList().Remove(d.options.DraggingClass)
+		d.drag = nil
+	}
+
+	d.scroll.Clear()
+	if d.session != nil {
+		d.session.Release()
+		d.session = nil
+	}
+}
+
+// suppressClick swallows the click the browser fires after a drag ends on
+// the element where it started. The listener is removed once the events of
+// the release have been handled, should no click follow
+func suppressClick() {
+	var listener *Listener
+	listener = newCaptureListener(Global(), "click", func(value Value) {
+		event := Event{EventObj: value}
+		event.PreventDefault()
+		event.StopPropagation()
+		listener.Remove()
+	}, true)
+
+	GetWindow().SetTimeout(listener.Remove, 0)
+}
+
+// keydown moves a focused item with Alt+ArrowUp and Alt+ArrowDown
+func (d *Draggable) keydown(item Element, event Event) {
+	keyboard := event.Keyboard()
+	if d.options.OnKeyboardMove == nil || !keyboard.AltKey() || keyboard.CtrlKey() || keyboard.MetaKey() {
+		return
+	}
+	if event.Target().Matches("input, textarea, select, [contenteditable]") {
+		return
+	}
+
+	offset := 0
+	switch keyboard.Key() {
+	case "ArrowUp":
+		offset = -1
+	case "ArrowDown":
+		offset = 1
+	default:
+		return
+	}
+
+	event.PreventDefault()
+	d.options.OnKeyboardMove(item, offset)
+}
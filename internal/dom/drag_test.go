@@ -0,0 +1,207 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newDragList fills a fresh 100x100 container with one 100x20 row per key
+// and makes the rows draggable, logging the callbacks
+func newDragList(t *testing.T, options DragOptions, keys ...string) (*Draggable, Element, *[]string) {
+	t.Helper()
+	list := newContainer(t)
+	memory := backend.(*MemoryBackend)
+	memory.SetLayout(list, 0, 0, 100, 100)
+	for i, key := range keys {
+		row := Document().CreateElement("li")
+		row.SetAttribute("data-id", key)
+		row.SetHTML(`<span class="grip"></span><button>x</button>`)
+		list.AppendChild(row)
+		memory.SetLayout(row, 0, float64(i*20), 100, 20)
+		memory.SetLayout(row.QuerySelector(".grip"), 0, float64(i*20), 10, 20)
+		memory.SetLayout(row.QuerySelector("button"), 90, float64(i*20), 10, 20)
+	}
+
+	var log []string
+	record := func(name string) func(*DragState) {
+		return func(drag *DragState) {
+			log = append(log, name+" "+drag.Item.GetAttribute("data-id")+" over "+drag.Over.Closest("li").GetAttribute("data-id"))
+		}
+	}
+	options.OnStart, options.OnMove = record("start"), record("move")
+	options.OnDrop, options.OnCancel = record("drop"), record("cancel")
+	d := NewDraggable(list, "li", options)
+	t.Cleanup(d.Release)
+	return d, list, &log
+}
+
+func TestDraggableStartsPastTheThreshold(t *testing.T) {
+	d, list, log := newDragList(t, DragOptions{}, "a", "b", "c")
+	row := list.QuerySelector(`[data-id="a"]`)
+
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 23, 12)
+	if d.Dragging() || len(*log) != 0 {
+		t.Fatalf("drag started within the threshold: %q", *log)
+	}
+
+	pointer(row, "pointermove", 20, 50)
+	ghost := Document().QuerySelector(".drag-ghost")
+	if !d.Dragging() || !row.ClassList().Contains("dragging") || !ghost.Exists() {
+		t.Fatal("drag not started past the threshold")
+	}
+	if ghost.GetAttribute("aria-hidden") != "true" || ghost.Style().GetProperty("transform") != "translate(0px, 40px)" {
+		t.Errorf("ghost hidden %q, moved by %q", ghost.GetAttribute("aria-hidden"), ghost.Style().GetProperty("transform"))
+	}
+
+	clicked := false
+	row.AddEventListener("click", func() { clicked = true })
+	pointer(row, "pointerup", 20, 50)
+	row.El.Call("click")
+
+	want := []string{"start a over ", "move a over c", "drop a over c"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("callbacks %q, want %q", *log, want)
+	}
+	if d.Dragging() || row.ClassList().Contains("dragging") || Document().QuerySelector(".drag-ghost").Exists() {
+		t.Error("drop left the drag behind")
+	}
+	if clicked {
+		t.Error("click after the drop not suppressed")
+	}
+}
+
+func TestDraggableLetsShortPressesClick(t *testing.T) {
+	_, list, log := newDragList(t, DragOptions{}, "a")
+	row := list.QuerySelector("li")
+	clicked := false
+	row.AddEventListener("click", func() { clicked = true })
+
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointerup", 20, 10)
+	row.El.Call("click")
+
+	if !clicked || len(*log) != 0 {
+		t.Errorf("clicked %v, callbacks %q", clicked, *log)
+	}
+}
+
+func TestDraggableCancels(t *testing.T) {
+	d, list, log := newDragList(t, DragOptions{}, "a", "b")
+	row := list.QuerySelector(`[data-id="a"]`)
+
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 20, 30)
+	escape := press(row.El, "Escape")
+	pointer(row, "pointerup", 20, 30)
+
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 20, 30)
+	pointer(row, "pointercancel", 20, 30)
+
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 20, 30)
+	d.Release()
+	pointer(row, "pointermove", 20, 10)
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 20, 30)
+
+	want := []string{
+		"start a over ", "move a over b", "cancel a over b",
+		"start a over ", "move a over b", "cancel a over b",
+		"start a over ", "move a over b", "cancel a over b",
+	}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("callbacks %q, want %q", *log, want)
+	}
+	if !escape.Get("defaultPrevented").Bool() || Document().QuerySelector(".drag-ghost").Exists() {
+		t.Error("Escape not taken by the drag")
+	}
+}
+
+func TestDraggableHandlesAndControls(t *testing.T) {
+	d, list, log := newDragList(t, DragOptions{}, "a")
+	row := list.QuerySelector("li")
+
+	pointer(row.QuerySelector("button"), "pointerdown", 95, 10)
+	pointer(row, "pointermove", 95, 50)
+	row.El.Call("dispatchEvent", Global().Get("PointerEvent").New("pointerdown", map[string]interface{}{
+		"bubbles": true, "clientX": 20, "clientY": 10, "isPrimary": false,
+	}))
+	pointer(row, "pointermove", 20, 50)
+	if d.Dragging() || len(*log) != 0 {
+		t.Fatalf("drag started from a control or a second finger: %q", *log)
+	}
+
+	handled, list, log := newDragList(t, DragOptions{Handle: ".grip"}, "a")
+	row = list.QuerySelector("li")
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 20, 50)
+	if handled.Dragging() {
+		t.Fatal("drag started outside the handle")
+	}
+	pointer(row, "pointerup", 20, 50)
+	pointer(row.QuerySelector(".grip"), "pointerdown", 5, 10)
+	pointer(row, "pointermove", 5, 50)
+	if !handled.Dragging() {
+		t.Error("drag not started from the handle")
+	}
+}
+
+func TestDraggableScrollsNearTheEdges(t *testing.T) {
+	memory := resetWindow(t)
+	memory.SetViewport(400, 100)
+	_, list, log := newDragList(t, DragOptions{}, "a", "b", "c", "d", "e", "f", "g", "h")
+	row := list.QuerySelector(`[data-id="a"]`)
+
+	pointer(row, "pointerdown", 20, 10)
+	pointer(row, "pointermove", 20, 99)
+	memory.Advance(100 * time.Millisecond)
+	scrolled := GetWindow().ScrollY()
+	if scrolled <= 0 {
+		t.Fatal("window not scrolled with the pointer at its bottom edge")
+	}
+	if moves := len(*log); moves < 4 {
+		t.Errorf("%d callbacks, want a move for every scrolled frame", moves)
+	}
+
+	pointer(row, "pointermove", 20, 50)
+	memory.Advance(100 * time.Millisecond)
+	if GetWindow().ScrollY() != scrolled {
+		t.Errorf("scrolled on to %v with the pointer in the middle", GetWindow().ScrollY())
+	}
+
+	pointer(row, "pointermove", 20, 0)
+	memory.Advance(time.Second)
+	pointer(row, "pointerup", 20, 0)
+	memory.Advance(100 * time.Millisecond)
+	if GetWindow().ScrollY() != 0 {
+		t.Errorf("scrolled back to %v, want the top", GetWindow().ScrollY())
+	}
+}
+
+func TestDraggableKeyboardMove(t *testing.T) {
+	var moves []string
+	_, list, _ := newDragList(t, DragOptions{OnKeyboardMove: func(item Element, offset int) {
+		moves = append(moves, item.GetAttribute("data-id")+" "+map[int]string{-1: "up", 1: "down"}[offset])
+	}}, "a", "b")
+	row := list.QuerySelector(`[data-id="b"]`)
+	row.SetHTML(`<input>`)
+
+	up := press(row.El, "alt+ArrowUp")
+	press(row.El, "ArrowDown")
+	press(row.El, "ctrl+alt+ArrowDown")
+	press(row.QuerySelector("input").El, "alt+ArrowDown")
+	press(list.QuerySelector(`[data-id="a"]`).El, "alt+ArrowDown")
+
+	if !reflect.DeepEqual(moves, []string{"b up", "a down"}) {
+		t.Errorf("moved %q, want [b up a down]", moves)
+	}
+	if !up.Get("defaultPrevented").Bool() {
+		t.Error("handled key not prevented")
+	}
+}
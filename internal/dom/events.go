@@ -0,0 +1,349 @@
+package dom
+
+// Event wraps a DOM Event object
+type Event struct {
+	EventObj Value
+}
+
+// KeyboardEvent wraps a DOM KeyboardEvent
+type KeyboardEvent struct {
+	Event
+}
+
+// MouseEvent wraps a DOM MouseEvent
+type MouseEvent struct {
+	Event
+}
+
+// PointerEvent wraps a DOM PointerEvent
+type PointerEvent struct {
+	MouseEvent
+}
+
+// DragEvent wraps a DOM DragEvent
+type DragEvent struct {
+	MouseEvent
+}
+
+// InputEvent wraps a DOM InputEvent
+type InputEvent struct {
+	Event
+}
+
+// DataTransfer wraps the dataTransfer object of a drag event
+type DataTransfer struct {
+	DataTransferObj Value
+}
+
+// Type returns the event type, such as "click"
+func (e Event) Type() string {
+	return e.EventObj.Get("type").String()
+}
+
+// Target returns the element the event was dispatched to
+func (e Event) Target() Element {
+	return wrapElement(e.EventObj.Get("target"))
+}
+
+// CurrentTarget returns the element whose listener is handling the event
+func (e Event) CurrentTarget() Element {
+	return wrapElement(e.EventObj.Get("currentTarget"))
+}
+
+// PreventDefault cancels the browser's default action for the event
+func (e Event) PreventDefault() {
+	e.EventObj.Call("preventDefault")
+}
+
+// StopPropagation stops the event from reaching further listeners up the tree
+func (e Event) StopPropagation() {
+	e.EventObj.Call("stopPropagation")
+}
+
+// StopImmediatePropagation also prevents other listeners on the same element from running
+func (e Event) StopImmediatePropagation() {
+	e.EventObj.Call("stopImmediatePropagation")
+}
+
+// DefaultPrevented reports whether PreventDefault has been called
+func (e Event) DefaultPrevented() bool {
+	return e.EventObj.Get("defaultPrevented").Bool()
+}
+
+// Bubbles reports whether the event bubbles up through the DOM
+func (e Event) Bubbles() bool {
+	return e.EventObj.Get("bubbles").Bool()
+}
+
+// Cancelable reports whether the event's default action can be prevented
+func (e Event) Cancelable() bool {
+	return e.EventObj.Get("cancelable").Bool()
+}
+
+// IsTrusted reports whether the event was generated by a user action
+func (e Event) IsTrusted() bool {
+	return e.EventObj.Get("isTrusted").Bool()
+}
+
+// TimeStamp returns the time in milliseconds at which the event was created
+func (e Event) TimeStamp() float64 {
+	return e.EventObj.Get("timeStamp").Float()
+}
+
+// Keyboard views the event as a KeyboardEvent
+func (e Event) Keyboard() KeyboardEvent {
+	return KeyboardEvent{e}
+}
+
+// Mouse views the event as a MouseEvent
+func (e Event) Mouse() MouseEvent {
+	return MouseEvent{e}
+}
+
+// Pointer views the event as a PointerEvent
+func (e Event) Pointer() PointerEvent {
+	return PointerEvent{MouseEvent{e}}
+}
+
+// Drag views the event as a DragEvent
+func (e Event) Drag() DragEvent {
+	return DragEvent{MouseEvent{e}}
+}
+
+// Input views the event as an InputEvent
+func (e Event) Input() InputEvent {
+	return InputEvent{e}
+}
+
+// Key returns the key value, such as "Enter" or "a"
+func (k KeyboardEvent) Key() string {
+	return k.EventObj.Get("key").String()
+}
+
+// Code returns the physical key code, such as "KeyA"
+func (k KeyboardEvent) Code() string {
+	return k.EventObj.Get("code").String()
+}
+
+// CtrlKey reports whether the Control key was held
+func (k KeyboardEvent) CtrlKey() bool {
+	return k.EventObj.Get("ctrlKey").Bool()
+}
+
+// ShiftKey reports whether the Shift key was held
+func (k KeyboardEvent) ShiftKey() bool {
+	return k.EventObj.Get("shiftKey").Bool()
+}
+
+// AltKey reports whether the Alt (Option) key was held
+func (k KeyboardEvent) AltKey() bool {
+	return k.EventObj.Get("altKey").Bool()
+}
+
+// MetaKey reports whether the Meta (Command/Windows) key was held
+func (k KeyboardEvent) MetaKey() bool {
+	return k.EventObj.Get("metaKey").Bool()
+}
+
+// Repeat reports whether the key is being held down and auto-repeating
+func (k KeyboardEvent) Repeat() bool {
+	return k.EventObj.Get("repeat").Bool()
+}
+
+// IsComposing reports whether the event fired during IME composition
+func (k KeyboardEvent) IsComposing() bool {
+	return k.EventObj.Get("isComposing").Bool()
+}
+
+// ClientX returns the horizontal position within the viewport
+func (m MouseEvent) ClientX() float64 {
+	return m.EventObj.Get("clientX").Float()
+}
+
+// ClientY returns the vertical position within the viewport
+func (m MouseEvent) ClientY() float64 {
+	return m.EventObj.Get("clientY").Float()
+}
+
+// PageX returns the horizontal position within the document
+func (m MouseEvent) PageX() float64 {
+	return m.EventObj.Get("pageX").Float()
+}
+
+// PageY returns the vertical position within the document
+func (m MouseEvent) PageY() float64 {
+	return m.EventObj.Get("pageY").Float()
+}
+
+// OffsetX returns the horizontal position within the target element
+func (m MouseEvent) OffsetX() float64 {
+	return m.EventObj.Get("offsetX").Float()
+}
+
+// OffsetY returns the vertical position within the target element
+func (m MouseEvent) OffsetY() float64 {
+	return m.EventObj.Get("offsetY").Float()
+}
+
+// MovementX returns the horizontal distance moved since the last event
+func (m MouseEvent) MovementX() float64 {
+	return m.EventObj.Get("movementX").Float()
+}
+
+// MovementY returns the vertical distance moved since the last event
+func (m MouseEvent) MovementY() float64 {
+	return m.EventObj.Get("movementY").Float()
+}
+
+// Button returns the button that changed state (0 primary, 1 middle, 2 secondary)
+func (m MouseEvent) Button() int {
+	return m.EventObj.Get("button").Int()
+}
+
+// Buttons returns a bitmask of the buttons currently held
+func (m MouseEvent) Buttons() int {
+	return m.EventObj.Get("buttons").Int()
+}
+
+// CtrlKey reports whether the Control key was held
+func (m MouseEvent) CtrlKey() bool {
+	return m.EventObj.Get("ctrlKey").Bool()
+}
+
+// ShiftKey reports whether the Shift key was held
+func (m MouseEvent) ShiftKey() bool {
+	return m.EventObj.Get("shiftKey").Bool()
+}
+
+// AltKey reports whether the Alt (Option) key was held
+func (m MouseEvent) AltKey() bool {
+	return m.EventObj.Get("altKey").Bool()
+}
+
+// MetaKey reports whether the Meta (Command/Windows) key was held
+func (m MouseEvent) MetaKey() bool {
+	return m.EventObj.Get("metaKey").Bool()
+}
+
+// RelatedTarget returns the secondary target, such as the element being left on mouseover
+func (m MouseEvent) RelatedTarget() Element {
+	return wrapElement(m.EventObj.Get("relatedTarget"))
+}
+
+// PointerID returns the unique identifier of the pointer
+func (p PointerEvent) PointerID() int {
+	return p.EventObj.Get("pointerId").Int()
+}
+
+// PointerType returns "mouse", "pen" or "touch"
+func (p PointerEvent) PointerType() string {
+	return p.EventObj.Get("pointerType").String()
+}
+
+// IsPrimary reports whether this is the primary pointer of its type
+func (p PointerEvent) IsPrimary() bool {
+	return p.EventObj.Get("isPrimary").Bool()
+}
+
+// Pressure returns the normalized pressure of the pointer between 0 and 1
+func (p PointerEvent) Pressure() float64 {
+	return p.EventObj.Get("pressure").Float()
+}
+
+// Width returns the width of the pointer's contact geometry
+func (p PointerEvent) Width() float64 {
+	return p.EventObj.Get("width").Float()
+}
+
+// Height returns the height of the pointer's contact geometry
+func (p PointerEvent) Height() float64 {
+	return p.EventObj.Get("height").Float()
+}
+
+// DataTransfer returns the data being dragged
+func (d DragEvent) DataTransfer() DataTransfer {
+	return DataTransfer{
+		DataTransferObj: d.EventObj.Get("dataTransfer"),
+	}
+}
+
+// Data returns the inserted characters, if any
+func (i InputEvent) Data() string {
+	data := i.EventObj.Get("data")
+	if data.IsNull() || data.IsUndefined() {
+		return ""
+	}
+	return data.String()
+}
+
+// InputType returns the kind of edit, such as "insertText" or "deleteContentBackward"
+func (i InputEvent) InputType() string {
+	return i.EventObj.Get("inputType").String()
+}
+
+// IsComposing reports whether the event fired during IME composition
+func (i InputEvent) IsComposing() bool {
+	return i.EventObj.Get("isComposing").Bool()
+}
+
+// SetData stores data of the given format, such as "text/plain"
+func (d DataTransfer) SetData(format, data string) DataTransfer {
+	d.DataTransferObj.Call("setData", format, data)
+	return d
+}
+
+// GetData retrieves data of the given format
+func (d DataTransfer) GetData(format string) string {
+	return d.DataTransferObj.Call("getData", format).String()
+}
+
+// ClearData removes data of the given format, or all data if format is empty
+func (d DataTransfer) ClearData(format string) DataTransfer {
+	if format == "" {
+		d.DataTransferObj.Call("clearData")
+	} else {
+		d.DataTransferObj.Call("clearData", format)
+	}
+	return d
+}
+
+// Types returns the formats of the data being dragged
+func (d DataTransfer) Types() []string {
+	types := d.DataTransferObj.Get("types")
+	length := types.Get("length").Int()
+	result := make([]string, length)
+
+	for i := 0; i < length; i++ {
+		result[i] = types.Index(i).String()
+	}
+
+	return result
+}
+
+// DropEffect returns the drop effect ("none", "copy", "link" or "move")
+func (d DataTransfer) DropEffect() string {
+	return d.DataTransferObj.Get("dropEffect").String()
+}
+
+// SetDropEffect sets the drop effect shown to the user
+func (d DataTransfer) SetDropEffect(effect string) DataTransfer {
+	d.DataTransferObj.Set("dropEffect", effect)
+	return d
+}
+
+// EffectAllowed returns the operations allowed for the drag
+func (d DataTransfer) EffectAllowed() string {
+	return d.DataTransferObj.Get("effectAllowed").String()
+}
+
+// SetEffectAllowed sets the operations allowed for the drag
+func (d DataTransfer) SetEffectAllowed(effect string) DataTransfer {
+	d.DataTransferObj.Set("effectAllowed", effect)
+	return d
+}
+
+// SetDragImage sets the image shown under the pointer while dragging
+func (d DataTransfer) SetDragImage(image Element, x, y int) DataTransfer {
+	d.DataTransferObj.Call("setDragImage", image.El, x, y)
+	return d
+}
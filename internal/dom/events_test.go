@@ -0,0 +1,113 @@
+package dom
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fire dispatches a new event of the given constructor and type on target
+// and returns it wrapped
+func fire(target Element, constructor, eventType string, init map[string]interface{}) Event {
+	event := Global().Get(constructor).New(eventType, init)
+	target.El.Call("dispatchEvent", event)
+	return Event{EventObj: event}
+}
+
+func TestEventPropagation(t *testing.T) {
+	root := newContainer(t)
+	root.SetHTML(`<button></button>`)
+	button := root.QuerySelector("button")
+	var seen []string
+	root.AddEventListenerWithEvent("click", func(event Event) {
+		seen = append(seen, "root "+event.Target().El.Get("tagName").String())
+	})
+	button.AddEventListenerWithEvent("click", func(event Event) {
+		seen = append(seen, "button")
+		if event.CurrentTarget().El.Equal(button.El) && event.Cancelable() {
+			event.PreventDefault()
+		}
+	})
+
+	event := fire(button, "Event", "click", map[string]interface{}{"bubbles": true, "cancelable": true})
+	if !event.DefaultPrevented() || !event.Bubbles() || event.Type() != "click" {
+		t.Errorf("prevented %v, bubbles %v, type %q", event.DefaultPrevented(), event.Bubbles(), event.Type())
+	}
+
+	button.AddEventListenerWithEvent("click", func(event Event) { event.StopPropagation() })
+	fire(button, "Event", "click", map[string]interface{}{"bubbles": true})
+
+	want := []string{"button", "root BUTTON", "button"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("seen %q, want %q", seen, want)
+	}
+}
+
+func TestKeyboardEvent(t *testing.T) {
+	keyboard := fire(newContainer(t), "KeyboardEvent", "keydown", map[string]interface{}{
+		"key": "A", "code": "KeyA", "shiftKey": true, "repeat": true,
+	}).Keyboard()
+
+	if keyboard.Key() != "A" || keyboard.Code() != "KeyA" || !keyboard.ShiftKey() || !keyboard.Repeat() ||
+		keyboard.CtrlKey() || keyboard.AltKey() || keyboard.MetaKey() || keyboard.IsComposing() {
+		t.Errorf("keyboard event read as %q %q shift %v repeat %v", keyboard.Key(), keyboard.Code(), keyboard.ShiftKey(), keyboard.Repeat())
+	}
+}
+
+func TestMouseAndPointerEvents(t *testing.T) {
+	root := newContainer(t)
+	mouse := fire(root, "MouseEvent", "mousedown", map[string]interface{}{
+		"clientX": 10, "clientY": 20, "button": 2, "buttons": 2, "ctrlKey": true,
+	}).Mouse()
+	if mouse.ClientX() != 10 || mouse.ClientY() != 20 || mouse.Button() != 2 || mouse.Buttons() != 2 || !mouse.CtrlKey() {
+		t.Errorf("mouse event read as (%v, %v) button %d", mouse.ClientX(), mouse.ClientY(), mouse.Button())
+	}
+	if mouse.RelatedTarget().Exists() {
+		t.Error("missing related target not empty")
+	}
+
+	pointer := fire(root, "PointerEvent", "pointerdown", map[string]interface{}{
+		"pointerId": 7, "pointerType": "touch", "isPrimary": false, "pressure": 0.5,
+	}).Pointer()
+	if pointer.PointerID() != 7 || pointer.PointerType() != "touch" || pointer.IsPrimary() || pointer.Pressure() != 0.5 {
+		t.Errorf("pointer event read as %d %q primary %v", pointer.PointerID(), pointer.PointerType(), pointer.IsPrimary())
+	}
+	if pointer.ClientX() != 0 || pointer.Width() != 1 {
+		t.Errorf("pointer defaults read as x %v width %v", pointer.ClientX(), pointer.Width())
+	}
+}
+
+func TestDragEventDataTransfer(t *testing.T) {
+	transfer := Global().Get("DataTransfer").New()
+	drag := fire(newContainer(t), "DragEvent", "dragstart", map[string]interface{}{"dataTransfer": transfer}).Drag()
+
+	data := drag.DataTransfer().
+		SetData("text/plain", "todo-1").
+		SetData("text/uri-list", "/todo/1").
+		SetEffectAllowed("move").
+		SetDropEffect("move")
+	if data.GetData("text/plain") != "todo-1" || data.EffectAllowed() != "move" || data.DropEffect() != "move" {
+		t.Errorf("data %q effect %q", data.GetData("text/plain"), data.EffectAllowed())
+	}
+
+	data.ClearData("text/uri-list")
+	if !reflect.DeepEqual(data.Types(), []string{"text/plain"}) {
+		t.Errorf("types %v after clearing one", data.Types())
+	}
+	data.ClearData("")
+	if len(data.Types()) != 0 {
+		t.Errorf("types %v after clearing all", data.Types())
+	}
+}
+
+func TestInputEvent(t *testing.T) {
+	root := newContainer(t)
+	typed := fire(root, "InputEvent", "input", map[string]interface{}{"data": "a", "inputType": "insertText"}).Input()
+	deleted := fire(root, "InputEvent", "input", map[string]interface{}{"inputType": "deleteContentBackward"}).Input()
+
+	if typed.Data() != "a" || typed.InputType() != "insertText" {
+		t.Errorf("typed input read as %q %q", typed.Data(), typed.InputType())
+	}
+	if deleted.Data() != "" || deleted.InputType() != "deleteContentBackward" {
+		t.Errorf("deletion read as %q %q", deleted.Data(), deleted.InputType())
+	}
+}
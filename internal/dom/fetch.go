@@ -0,0 +1,341 @@
+package dom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Header holds the header fields of a request or response, keyed by
+// canonical name. It has the layout of http.Header, converting freely, without
+// pulling net/http into the WebAssembly binary
+type Header map[string][]string
+
+// Add appends value to the values of the named field
+func (h Header) Add(name, value string) {
+	name = canonicalHeaderKey(name)
+	h[name] = append(h[name], value)
+}
+
+// Set replaces the values of the named field with value
+func (h Header) Set(name, value string) {
+	h[canonicalHeaderKey(name)] = []string{value}
+}
+
+// Get returns the first value of the named field, or "" when it is missing
+func (h Header) Get(name string) string {
+	if values := h[canonicalHeaderKey(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Values returns every value of the named field
+func (h Header) Values(name string) []string {
+	return h[canonicalHeaderKey(name)]
+}
+
+// Del removes the named field
+func (h Header) Del(name string) {
+	delete(h, canonicalHeaderKey(name))
+}
+
+// canonicalHeaderKey capitalizes the first letter and every letter following
+// a hyphen, lowering the rest: "content-type" becomes "Content-Type"
+func canonicalHeaderKey(name string) string {
+	key := []byte(name)
+	upper := true
+	for i, c := range key {
+		if c == ' ' || c >= 0x7f {
+			return name // not a valid field name, leave it alone
+		}
+		if upper && 'a' <= c && c <= 'z' {
+			key[i] = c - 'a' + 'A'
+		} else if !upper && 'A' <= c && c <= 'Z' {
+			key[i] = c - 'A' + 'a'
+		}
+		upper = c == '-'
+	}
+	return string(key)
+}
+
+// statusText gives the reason phrase of common statuses, for responses that
+// arrive without one, as over HTTP/2
+var statusText = map[int]string{
+	200: "OK",
+	201: "Created",
+	202: "Accepted",
+	204: "No Content",
+	206: "Partial Content",
+	301: "Moved Permanently",
+	302: "Found",
+	303: "See Other",
+	304: "Not Modified",
+	307: "Temporary Redirect",
+	308: "Permanent Redirect",
+	400: "Bad Request",
+	401: "Unauthorized",
+	403: "Forbidden",
+	404: "Not Found",
+	405: "Method Not Allowed",
+	406: "Not Acceptable",
+	408: "Request Timeout",
+	409: "Conflict",
+	410: "Gone",
+	412: "Precondition Failed",
+	413: "Request Entity Too Large",
+	415: "Unsupported Media Type",
+	422: "Unprocessable Entity",
+	429: "Too Many Requests",
+	500: "Internal Server Error",
+	501: "Not Implemented",
+	502: "Bad Gateway",
+	503: "Service Unavailable",
+	504: "Gateway Timeout",
+}
+
+// Request describes an HTTP request sent with Fetch
+type Request struct {
+	Method  string // "GET" when empty
+	URL     string // relative URLs are joined to Client.BaseURL
+	Header  Header
+	Body    []byte
+	Timeout time.Duration // overrides Client.Timeout when positive
+}
+
+// Response is the answer to a request, with its body read
+type Response struct {
+	Status     int
+	StatusText string
+	Header     Header
+	Body       []byte
+	URL        string // final URL, after any redirects
+}
+
+// HTTPError is returned by the JSON helpers for a response whose status is
+// not in the 2xx range
+type HTTPError struct {
+	Method     string
+	URL        string
+	Status     int
+	StatusText string
+	Body       []byte
+}
+
+// Client sends requests with window.fetch. The zero value is ready to use
+type Client struct {
+	BaseURL string        // prefix for relative request URLs, such as "/api"
+	Header  Header        // sent with every request
+	Timeout time.Duration // no limit when zero
+	// Credentials is the fetch credentials mode: "omit", "same-origin" or
+	// "include". The browser default applies when empty
+	Credentials string
+}
+
+// DefaultClient is the client used by Fetch and FetchJSON
+var DefaultClient = &Client{}
+
+// Fetch sends req with DefaultClient
+func Fetch(ctx context.Context, req *Request) (*Response, error) {
+	return DefaultClient.Do(ctx, req)
+}
+
+// FetchJSON sends a request with DefaultClient and decodes the JSON response
+// into a T:
+//
+//	todos, err := dom.FetchJSON[[]Todo](ctx, "GET", "/api/todos", nil)
+//
+// When body is not nil it is encoded as JSON and sent as the request body
+func FetchJSON[T any](ctx context.Context, method, url string, body interface{}) (T, error) {
+	return DoJSON[T](ctx, DefaultClient, method, url, body)
+}
+
+// DoJSON is FetchJSON for a given client. A response outside the 2xx range
+// is returned as an *HTTPError, and an empty body leaves the result zero
+func DoJSON[T any](ctx context.Context, client *Client, method, url string, body interface{}) (T, error) {
+	var result T
+
+	req := &Request{Method: method, URL: url, Header: Header{}}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return result, fmt.Errorf("dom: %s %s: %w", req.method(), url, err)
+		}
+		req.Body = data
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		return result, err
+	}
+	if !resp.OK() {
+		return result, &HTTPError{
+			Method:     req.method(),
+			URL:        resp.URL,
+			Status:     resp.Status,
+			StatusText: resp.StatusText,
+			Body:       resp.Body,
+		}
+	}
+
+	if err := resp.JSON(&result); err != nil {
+		return result, fmt.Errorf("dom: %s %s: %w", req.method(), resp.URL, err)
+	}
+	return result, nil
+}
+
+// Do sends req and waits for the response and its body. Responses with any
+// status are returned without error; only network failures, cancellation and
+// timeouts fail. When ctx is done first the request is aborted through an
+// AbortController and ctx.Err() is returned.
+//
+// Do blocks the calling goroutine until the browser answers, which needs the
+// event loop to keep running. Call it from a goroutine of its own, never
+// directly inside an event handler or another callback from JavaScript:
+//
+//	button.AddEventListener("click", func() {
+//		go func() {
+//			todos, err := dom.FetchJSON[[]Todo](ctx, "GET", "/api/todos", nil)
+//			// ...
+//		}()
+//	})
+func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
+	timeout := c.Timeout
+	if req.Timeout > 0 {
+		timeout = req.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	method := req.method()
+	target := c.resolve(req.URL)
+
+	headers := map[string]interface{}{}
+	for _, header := range []Header{c.Header, req.Header} {
+		for name, values := range header {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	controller := Global().Get("AbortController").New()
+	init := map[string]interface{}{
+		"method":  method,
+		"headers": headers,
+		"signal":  controller.Get("signal"),
+	}
+	if req.Body != nil {
+		// A Uint8Array carries the bytes unchanged, where a string would be
+		// sent as UTF-8 and mangle binary bodies
+		body := Global().Get("Uint8Array").New(len(req.Body))
+		CopyBytesToJS(body, req.Body)
+		init["body"] = body
+	}
+	if c.Credentials != "" {
+		init["credentials"] = c.Credentials
+	}
+
+	// Both the response and its body arrive through promises
+	wait := func(promise Value) (Value, error) {
+		select {
+		case outcome := <-watchPromise(promise):
+			if outcome.rejected {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("dom: %s %s: %w", method, target, &JSError{Value: outcome.value})
+			}
+			return outcome.value, nil
+		case <-ctx.Done():
+			controller.Call("abort")
+			return nil, ctx.Err()
+		}
+	}
+
+	response, err := wait(Global().Call("fetch", target, init))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{
+		Status:     response.Get("status").Int(),
+		StatusText: response.Get("statusText").String(),
+		Header:     Header{},
+		URL:        response.Get("url").String(),
+	}
+	if resp.URL == "" {
+		resp.URL = target
+	}
+	if resp.StatusText == "" {
+		resp.StatusText = statusText[resp.Status]
+	}
+
+	forEach := FuncOf(func(_ Value, args []Value) interface{} {
+		resp.Header.Add(args[1].String(), args[0].String())
+		return nil
+	})
+	response.Get("headers").Call("forEach", forEach)
+	forEach.Release()
+
+	buffer, err := wait(response.Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+	body := Global().Get("Uint8Array").New(buffer)
+	resp.Body = make([]byte, body.Length())
+	CopyBytesToGo(resp.Body, body)
+
+	return resp, nil
+}
+
+// resolve joins a relative request URL to the base URL
+func (c *Client) resolve(ref string) string {
+	if c.BaseURL == "" {
+		return ref
+	}
+	if parsed, err := url.Parse(ref); err == nil && (parsed.IsAbs() || strings.HasPrefix(ref, "//")) {
+		return ref
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.TrimPrefix(ref, "/")
+}
+
+// method returns the request method, GET by default
+func (r *Request) method() string {
+	if r.Method == "" {
+		return "GET"
+	}
+	return strings.ToUpper(r.Method)
+}
+
+// OK reports whether the status is in the 2xx range
+func (r *Response) OK() bool {
+	return r.Status >= 200 && r.Status < 300
+}
+
+// Text returns the body as a string
+func (r *Response) Text() string {
+	return string(r.Body)
+}
+
+// JSON decodes the body into v. An empty body leaves v unchanged
+func (r *Response) JSON(v interface{}) error {
+	if len(bytes.TrimSpace(r.Body)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Body, v)
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("dom: %s %s: %d %s", e.Method, e.URL, e.Status, e.StatusText)
+}
@@ -0,0 +1,125 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// serve answers the requests of the test with handler, or fails them when
+// handler is nil
+func serve(t *testing.T, handler http.Handler) {
+	t.Helper()
+	memory := backend.(*MemoryBackend)
+	saved := memory.FetchHandler
+	memory.FetchHandler = handler
+	t.Cleanup(func() { memory.FetchHandler = saved })
+}
+
+func TestFetchSendsAndReceivesBinaryBodies(t *testing.T) {
+	payload := []byte{0x00, 0xff, 0xfe, 0x80, 'a', 0xc3}
+	var received []byte
+	serve(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.Write(received)
+	}))
+
+	resp, err := Fetch(context.Background(), &Request{Method: "post", URL: "/upload", Body: payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(received, payload) {
+		t.Errorf("server received % x, want % x", received, payload)
+	}
+	if !bytes.Equal(resp.Body, payload) {
+		t.Errorf("response body % x, want % x", resp.Body, payload)
+	}
+}
+
+func TestFetchJSON(t *testing.T) {
+	serve(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %s with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append(body[:len(body)-1:len(body)-1], ",3]"...))
+	}))
+
+	numbers, err := FetchJSON[[]int](context.Background(), "PUT", "/numbers", []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(numbers) != 3 || numbers[2] != 3 {
+		t.Errorf("numbers = %v, want [1 2 3]", numbers)
+	}
+}
+
+func TestFetchJSONReturnsHTTPErrors(t *testing.T) {
+	serve(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+
+	_, err := FetchJSON[[]int](context.Background(), "GET", "/missing", nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want an *HTTPError", err)
+	}
+	if httpErr.Status != 404 || httpErr.StatusText != "Not Found" || string(httpErr.Body) != "gone\n" {
+		t.Errorf("err = %+v", httpErr)
+	}
+}
+
+func TestFetchMergesHeaders(t *testing.T) {
+	serve(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Token", r.Header.Get("Authorization")+"/"+r.Header.Get("X-Trace"))
+	}))
+
+	client := &Client{BaseURL: "/api/", Header: Header{}}
+	client.Header.Set("authorization", "secret")
+	req := &Request{URL: "items", Header: Header{}}
+	req.Header.Add("x-trace", "1")
+
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("x-token"); got != "secret/1" {
+		t.Errorf("X-Token = %q, want secret/1", got)
+	}
+	if resp.URL != "http://localhost/api/items" {
+		t.Errorf("URL = %q", resp.URL)
+	}
+}
+
+func TestFetchFailures(t *testing.T) {
+	serve(t, nil)
+	if _, err := Fetch(context.Background(), &Request{URL: "/"}); err == nil {
+		t.Error("fetch without a network succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Fetch(ctx, &Request{URL: "/"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestCanonicalHeaderKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"content-type":  "Content-Type",
+		"X-REQUEST-ID":  "X-Request-Id",
+		"etag":          "Etag",
+		"bad name":      "bad name",
+		"Authorization": "Authorization",
+	} {
+		if got := canonicalHeaderKey(name); got != want {
+			t.Errorf("canonicalHeaderKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dom
+
+import (
+	"math"
+	"strconv"
+)
+
+// FlipOptions configures Flip. Empty fields use the defaults
+type FlipOptions struct {
+	// Key is the attribute identifying an item across renders, "data-id" by
+	// default
+	Key string
+	// Selector matches the items inside the container, every element with
+	// the Key attribute by default
+	Selector string
+	Duration int    // milliseconds, 300 by default
+	Easing   string // "ease-in-out" by default
+	// Enter names a preset played on items that were not in the container
+	// before the change, such as "fadeIn". Nothing is played when empty
+	Enter string
+}
+
+// Flip returns a step that moves the items of container smoothly to where a
+// change puts them, using the First, Last, Invert, Play technique. change
+// runs when the step starts and must leave the DOM to the scheduler, as
+// signals and Invalidate do. The box of every item is recorded in the read
+// phase of the next frame, before the views render the change; once they have,
+// in the write phase, each item that moved is offset by a transform so it
+// appears in its old place, and the transform is animated away. Items are
+// matched by their key attribute, so keyed lists rendered by the reconciler or
+// by components keep their identity:
+//
+//	dom.Flip(list.Element(), dom.FlipOptions{}, func() {
+//		todos.Set(reordered)
+//	}).Start()
+//
+// The step completes when every item has arrived
+func Flip(container Element, options FlipOptions, change func()) Step {
+	if options.Key == "" {
+		options.Key = "data-id"
+	}
+	if options.Selector == "" {
+		options.Selector = "[" + options.Key + "]"
+	}
+	if options.Duration <= 0 {
+		options.Duration = 300
+	}
+	if options.Easing == "" {
+		options.Easing = "ease-in-out"
+	}
+
+	return func(done func()) {
+		// First
+		first := map[string]Rect{}
+		ScheduleRead(func() {
+			for _, item := range container.QuerySelectorAll(options.Selector) {
+				first[item.GetAttribute(options.Key)] = item.GetRect()
+			}
+		})
+
+		change()
+
+		// Marked after the views the change invalidated, so it updates after
+		// them in the same write phase and the items never paint in their new
+		// place untransformed
+		Invalidate(&flipInvert{func() {
+			var steps []Step
+			for _, item := range container.QuerySelectorAll(options.Selector) {
+				before, ok := first[item.GetAttribute(options.Key)]
+				if !ok {
+					if options.Enter != "" {
+						steps = append(steps, item.Preset(options.Enter, options.Duration))
+					}
+					continue
+				}
+
+				// Last. The one layout FLIP needs, after the render
+				after := item.GetRect()
+				dx := before.Left - after.Left
+				dy := before.Top - after.Top
+				if math.Abs(dx) < 0.5 && math.Abs(dy) < 0.5 {
+					continue
+				}
+
+				// Invert and Play
+				steps = append(steps, item.Keyframes([]Keyframe{
+					{Properties: map[string]string{"transform": "translate(" + pixels(dx) + ", " + pixels(dy) + ")"}},
+					{Properties: map[string]string{"transform": "none"}},
+				}, AnimationOptions{Duration: options.Duration, Easing: options.Easing}))
+			}
+
+			Parallel(steps...)(done)
+		}})
+	}
+}
+
+// flipInvert runs the Last, Invert and Play stages of Flip as a view update
+type flipInvert struct {
+	run func()
+}
+
+// Update runs the stages
+func (f *flipInvert) Update() {
+	f.run()
+}
+
+// pixels formats a length in CSS pixels
+func pixels(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64) + "px"
+}
@@ -0,0 +1,101 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"reflect"
+	"testing"
+)
+
+// layoutView renders by running render
+type layoutView struct {
+	render func()
+}
+
+func (v *layoutView) Update() {
+	v.render()
+}
+
+// newFlipList fills a fresh container with one 100x20 row per key, laid out
+// from the top
+func newFlipList(t *testing.T, keys ...string) Element {
+	t.Helper()
+	list := newContainer(t)
+	relayout(list, keys...)
+	return list
+}
+
+// relayout shows the rows with keys in the given order, reusing existing
+// rows, removing the others and laying them out from the top
+func relayout(list Element, keys ...string) {
+	memory := backend.(*MemoryBackend)
+	rows := map[string]Element{}
+	for _, row := range list.QuerySelectorAll("[data-id]") {
+		rows[row.GetAttribute("data-id")] = row
+		row.Remove()
+	}
+	for i, key := range keys {
+		row, ok := rows[key]
+		if !ok {
+			row = Document().CreateElement("li").SetAttribute("data-id", key)
+		}
+		list.AppendChild(row)
+		memory.SetLayout(row, 0, float64(i*20), 100, 20)
+	}
+}
+
+// startTransforms returns the first transform of the running animation of
+// each row that has one
+func startTransforms(list Element) map[string]string {
+	found := map[string]string{}
+	for _, row := range list.QuerySelectorAll("[data-id]") {
+		animations := row.El.Call("getAnimations")
+		if animations.Length() == 0 {
+			continue
+		}
+		keyframes := animations.Index(0).Get("effect").Call("getKeyframes")
+		found[row.GetAttribute("data-id")] = keyframes.Index(0).Get("transform").String()
+	}
+	return found
+}
+
+func TestFlipInvertsTheItemsThatMoved(t *testing.T) {
+	list := newFlipList(t, "a", "b", "c", "x")
+
+	finished := false
+	Flip(list, FlipOptions{}, func() {
+		Invalidate(&layoutView{func() { relayout(list, "b", "a", "c", "d") }})
+	})(func() { finished = true })
+	if got := list.QuerySelector("[data-id]").GetAttribute("data-id"); got != "a" {
+		t.Fatalf("list rendered before the frame, first row %q", got)
+	}
+
+	FlushFrame()
+	want := map[string]string{"a": "translate(0px, -20px)", "b": "translate(0px, 20px)"}
+	if got := startTransforms(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("transforms %v, want %v", got, want)
+	}
+
+	runFrames()
+	if !finished {
+		t.Error("step did not complete once the items arrived")
+	}
+}
+
+func TestFlipPlaysEnterOnNewItems(t *testing.T) {
+	list := newFlipList(t, "a")
+
+	Flip(list, FlipOptions{Enter: "fadeIn"}, func() {
+		Invalidate(&layoutView{func() { relayout(list, "a", "b") }})
+	}).Start()
+	FlushFrame()
+
+	if n := list.QuerySelector(`[data-id="a"]`).El.Call("getAnimations").Length(); n != 0 {
+		t.Errorf("item that stayed has %d animations", n)
+	}
+	if n := list.QuerySelector(`[data-id="b"]`).El.Call("getAnimations").Length(); n != 1 {
+		t.Errorf("new item has %d animations, want the enter preset", n)
+	}
+	runFrames()
+}
@@ -0,0 +1,417 @@
+package dom
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Form binds the fields of a struct held in a Signal to the controls inside
+// a root element, in both directions. Fields are bound through a form tag
+// naming the control, matched against the control's name attribute and
+// otherwise its id. A validate tag lists the rules the value must satisfy:
+//
+//	type Settings struct {
+//		FontSize string `form:"font-size" validate:"required,oneof=small medium large"`
+//		Volume   int    `form:"volume" validate:"min=0,max=100"`
+//		Sounds   bool   `form:"sounds"`
+//	}
+//
+//	form, err := dom.BindForm(panel, settings)
+//
+// Text inputs, selects and radio groups bind to string and number fields,
+// checkboxes to bool fields. When the user edits a control its value is
+// validated and, if valid, stored in the signal; when the signal changes the
+// controls are updated. Invalid input is reported through OnError and the
+// signal keeps its last valid value
+type Form[T any] struct {
+	root   Element
+	model  *Signal[T]
+	fields []*formField
+	errors map[string]error
+	scope  *Scope
+	effect *Effect
+
+	// OnError is called when a field fails validation and OnValid when it
+	// passes again. The defaults mark the controls with the invalid class and
+	// aria-invalid, and show the message in an element with a matching
+	// data-error-for attribute
+	OnError func(err *FieldError)
+	OnValid func(name string, controls []Element)
+}
+
+// FieldError is a validation failure of a bound field
+type FieldError struct {
+	Name     string    // name of the control
+	Field    string    // name of the struct field
+	Controls []Element // controls bound to the field
+	Err      error
+}
+
+func (e *FieldError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Validator checks a field value, returning an error describing what is wrong
+type Validator func(value interface{}) error
+
+// formField is a struct field bound to one or more controls
+type formField struct {
+	name       string
+	index      int
+	kind       reflect.Kind
+	validators []Validator
+}
+
+// bindableKinds are the kinds of the fields a control can be bound to
+var bindableKinds = map[reflect.Kind]bool{
+	reflect.Bool:    true,
+	reflect.String:  true,
+	reflect.Int:     true,
+	reflect.Float64: true,
+}
+
+// formControls matches the elements that can be bound
+const formControls = "input, select, textarea"
+
+// BindForm binds the struct fields of model's value to the controls inside
+// root and fills the controls from the current value. It returns an error if
+// T is not a struct, or a field with a form tag is unexported, of a kind that
+// can't be bound or has a malformed validate tag
+func BindForm[T any](root Element, model *Signal[T]) (*Form[T], error) {
+	structType := reflect.TypeOf(model.Peek())
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dom: BindForm needs a struct, got %v", structType)
+	}
+
+	var fields []*formField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, ok := field.Tag.Lookup("form")
+		if !ok || name == "" || name == "-" {
+			continue
+		}
+
+		if !field.IsExported() {
+			return nil, fmt.Errorf("dom: field %s: a bound field must be exported", field.Name)
+		}
+		if !bindableKinds[field.Type.Kind()] {
+			return nil, fmt.Errorf("dom: field %s: cannot bind a field of kind %v", field.Name, field.Type.Kind())
+		}
+		validators, err := parseValidators(field.Tag.Get("validate"))
+		if err != nil {
+			return nil, fmt.Errorf("dom: field %s: %v", field.Name, err)
+		}
+		fields = append(fields, &formField{
+			name:       name,
+			index:      i,
+			kind:       field.Type.Kind(),
+			validators: validators,
+		})
+	}
+
+	f := &Form[T]{
+		root:   root,
+		model:  model,
+		fields: fields,
+		errors: map[string]error{},
+		scope:  NewScope(),
+	}
+	f.OnError = f.showError
+	f.OnValid = f.clearError
+
+	// Controls to model
+	for _, eventType := range []string{"input", "change"} {
+		if !root.Exists() {
+			break
+		}
+		f.scope.Listen(root.On(eventType, formControls, func(control Element, _ Event) {
+			if field := f.fieldFor(control); field != nil {
+				f.read(field)
+			}
+		}))
+	}
+
+	// Model to controls
+	f.effect = NewEffect(func() {
+		value := reflect.ValueOf(model.Get())
+		for _, field := range f.fields {
+			f.write(field, value.Field(field.index))
+		}
+	})
+
+	return f, nil
+}
+
+// Validate adds a validator to the field bound to the named control. It runs
+// after the rules of the validate tag
+func (f *Form[T]) Validate(name string, validator Validator) *Form[T] {
+	for _, field := range f.fields {
+		if field.name == name {
+			field.validators = append(field.validators, validator)
+		}
+	}
+	return f
+}
+
+// Errors returns the current validation errors by control name
+func (f *Form[T]) Errors() map[string]error {
+	errors := make(map[string]error, len(f.errors))
+	for name, err := range f.errors {
+		errors[name] = err
+	}
+	return errors
+}
+
+// Valid reports whether every field passed validation the last time it was
+// edited
+func (f *Form[T]) Valid() bool {
+	return len(f.errors) == 0
+}
+
+// Unbind removes the listeners and stops updating the controls
+func (f *Form[T]) Unbind() {
+	f.effect.Stop()
+	f.scope.Release()
+}
+
+// fieldFor returns the field bound to control, if any
+func (f *Form[T]) fieldFor(control Element) *formField {
+	name := control.GetAttribute("name")
+	if name == "" {
+		name = control.GetAttribute("id")
+	}
+
+	for _, field := range f.fields {
+		if field.name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// controls returns the controls bound to field
+func (f *Form[T]) controls(field *formField) []Element {
+	controls := f.root.QuerySelectorAll(fmt.Sprintf("[name=%q]", field.name))
+	if len(controls) == 0 {
+		controls = f.root.QuerySelectorAll(fmt.Sprintf("[id=%q]", field.name))
+	}
+	return controls
+}
+
+// read validates the value of field's controls and stores it in the model
+func (f *Form[T]) read(field *formField) {
+	controls := f.controls(field)
+	value, err := field.parse(controls)
+	if err == nil {
+		for _, validator := range field.validators {
+			if err = validator(value.Interface()); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		fieldErr := &FieldError{
+			Name:     field.name,
+			Field:    reflect.TypeOf(f.model.Peek()).Field(field.index).Name,
+			Controls: controls,
+			Err:      err,
+		}
+		f.errors[field.name] = fieldErr
+		if f.OnError != nil {
+			f.OnError(fieldErr)
+		}
+		return
+	}
+
+	if _, failed := f.errors[field.name]; failed {
+		delete(f.errors, field.name)
+		if f.OnValid != nil {
+			f.OnValid(field.name, controls)
+		}
+	}
+
+	// Named types such as type Mode string take the value converted
+	model := f.model.Peek()
+	target := reflect.ValueOf(&model).Elem().Field(field.index)
+	value = value.Convert(target.Type())
+	if reflect.DeepEqual(target.Interface(), value.Interface()) {
+		return
+	}
+	target.Set(value)
+	f.model.Set(model)
+}
+
+// write shows value in field's controls, leaving controls that already show
+// it alone so that a control being typed in keeps its cursor
+func (f *Form[T]) write(field *formField, value reflect.Value) {
+	text := formatField(value)
+
+	for _, control := range f.controls(field) {
+		switch control.GetAttribute("type") {
+		case "checkbox":
+			checked := value.Kind() == reflect.Bool && value.Bool()
+			if control.El.Get("checked").Bool() != checked {
+				control.El.Set("checked", checked)
+			}
+		case "radio":
+			checked := control.GetValue() == text
+			if control.El.Get("checked").Bool() != checked {
+				control.El.Set("checked", checked)
+			}
+		default:
+			if control.GetValue() != text {
+				control.SetValue(text)
+			}
+		}
+	}
+}
+
+// parse reads the value of the controls as the field's type
+func (field *formField) parse(controls []Element) (reflect.Value, error) {
+	text := ""
+	checked := false
+	for _, control := range controls {
+		switch control.GetAttribute("type") {
+		case "checkbox":
+			checked = control.El.Get("checked").Bool()
+		case "radio":
+			if control.El.Get("checked").Bool() {
+				text = control.GetValue()
+			}
+		default:
+			text = control.GetValue()
+		}
+	}
+
+	switch field.kind {
+	case reflect.Bool:
+		return reflect.ValueOf(checked), nil
+	case reflect.String:
+		return reflect.ValueOf(text), nil
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return reflect.Value{}, errors.New("must be a whole number")
+		}
+		return reflect.ValueOf(n), nil
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return reflect.Value{}, errors.New("must be a number")
+		}
+		return reflect.ValueOf(n), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot bind a field of kind %v", field.kind)
+}
+
+// formatField converts a field value to the text shown in a control
+func formatField(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	}
+	return ""
+}
+
+// showError is the default OnError
+func (f *Form[T]) showError(err *FieldError) {
+	for _, control := range err.Controls {
+		control.ClassList().Add("invalid")
+		control.SetAttribute("aria-invalid", "true")
+	}
+	f.root.QuerySelector(fmt.Sprintf("[data-error-for=%q]", err.Name)).SetText(err.Err.Error())
+}
+
+// clearError is the default OnValid
+func (f *Form[T]) clearError(name string, controls []Element) {
+	for _, control := range controls {
+		control.ClassList().Remove("invalid")
+		control.RemoveAttribute("aria-invalid")
+	}
+	f.root.QuerySelector(fmt.Sprintf("[data-error-for=%q]", name)).SetText("")
+}
+
+// parseValidators turns a validate tag into validators. The rules are
+// required, min=N and max=N (length for strings, value for numbers) and
+// oneof=a b c
+func parseValidators(tag string) ([]Validator, error) {
+	var validators []Validator
+	if strings.TrimSpace(tag) == "" {
+		return nil, nil
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			validators = append(validators, func(value interface{}) error {
+				if reflect.ValueOf(value).IsZero() {
+					return errors.New("is required")
+				}
+				return nil
+			})
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q", name, rule)
+			}
+			isMin := name == "min"
+			validators = append(validators, func(value interface{}) error {
+				return checkLimit(value, limit, isMin)
+			})
+		case "oneof":
+			allowed := strings.Fields(arg)
+			validators = append(validators, func(value interface{}) error {
+				text := formatField(reflect.ValueOf(value))
+				for _, option := range allowed {
+					if text == option {
+						return nil
+					}
+				}
+				return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+			})
+		default:
+			return nil, fmt.Errorf("unknown rule %q", rule)
+		}
+	}
+	return validators, nil
+}
+
+// checkLimit applies a min or max rule
+func checkLimit(value interface{}, limit float64, isMin bool) error {
+	var n float64
+	unit := ""
+	switch v := value.(type) {
+	case string:
+		n = float64(len([]rune(v)))
+		unit = " characters"
+	case int:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return nil
+	}
+
+	if isMin && n < limit {
+		return fmt.Errorf("must be at least %s%s", strconv.FormatFloat(limit, 'f', -1, 64), unit)
+	}
+	if !isMin && n > limit {
+		return fmt.Errorf("must be at most %s%s", strconv.FormatFloat(limit, 'f', -1, 64), unit)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package dom
+
+import (
+	"strings"
+	"testing"
+)
+
+type mode string
+
+type settings struct {
+	Name   string  `form:"name" validate:"required,max=5"`
+	Volume int     `form:"volume" validate:"min=0,max=10"`
+	Ratio  float64 `form:"ratio"`
+	Sounds bool    `form:"sounds"`
+	Mode   mode    `form:"mode" validate:"oneof=light dark"`
+	Notes  string
+}
+
+const settingsForm = `
+	<input name="name"><span data-error-for="name"></span>
+	<input name="volume">
+	<input id="ratio">
+	<input name="sounds" type="checkbox">
+	<input name="mode" type="radio" value="light">
+	<input name="mode" type="radio" value="dark">`
+
+// bindSettings binds a settings signal to the controls of settingsForm
+func bindSettings(t *testing.T) (Element, *Signal[settings], *Form[settings]) {
+	t.Helper()
+	root := newContainer(t)
+	root.SetHTML(settingsForm)
+
+	model := NewSignal(settings{Name: "ann", Volume: 3, Ratio: 0.5, Mode: "light"})
+	form, err := BindForm(root, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(form.Unbind)
+	return root, model, form
+}
+
+// edit sets the value of the control matching selector and fires its input
+// event, as typing would
+func edit(root Element, selector, value string) {
+	control := root.QuerySelector(selector)
+	control.SetValue(value)
+	control.El.Call("dispatchEvent", Global().Get("Event").New("input", map[string]interface{}{"bubbles": true}))
+}
+
+// click toggles the checkbox or radio button matching selector
+func click(root Element, selector string) {
+	root.QuerySelector(selector).El.Call("click")
+}
+
+func TestBindFormFillsControls(t *testing.T) {
+	root, _, _ := bindSettings(t)
+
+	for selector, want := range map[string]string{`[name="name"]`: "ann", `[name="volume"]`: "3", "#ratio": "0.5"} {
+		if got := root.QuerySelector(selector).GetValue(); got != want {
+			t.Errorf("%s = %q, want %q", selector, got, want)
+		}
+	}
+	if !root.QuerySelector(`[value="light"]`).El.Get("checked").Bool() {
+		t.Error("light radio not checked")
+	}
+}
+
+func TestBindFormStoresEdits(t *testing.T) {
+	root, model, form := bindSettings(t)
+
+	edit(root, `[name="volume"]`, "7")
+	edit(root, "#ratio", "1.25")
+	click(root, `[name="sounds"]`)
+	click(root, `[value="dark"]`)
+
+	want := settings{Name: "ann", Volume: 7, Ratio: 1.25, Sounds: true, Mode: "dark"}
+	if got := model.Peek(); got != want {
+		t.Errorf("model = %+v, want %+v", got, want)
+	}
+	if !form.Valid() {
+		t.Errorf("errors = %v", form.Errors())
+	}
+}
+
+func TestBindFormUpdatesControls(t *testing.T) {
+	root, model, _ := bindSettings(t)
+
+	model.Update(func(s settings) settings {
+		s.Name, s.Sounds = "bob", true
+		return s
+	})
+
+	if got := root.QuerySelector(`[name="name"]`).GetValue(); got != "bob" {
+		t.Errorf("name = %q, want bob", got)
+	}
+	if !root.QuerySelector(`[name="sounds"]`).El.Get("checked").Bool() {
+		t.Error("sounds not checked")
+	}
+}
+
+func TestBindFormRejectsInvalidInput(t *testing.T) {
+	root, model, form := bindSettings(t)
+
+	edit(root, `[name="name"]`, "")
+	edit(root, `[name="volume"]`, "11")
+
+	if got := model.Peek(); got.Name != "ann" || got.Volume != 3 {
+		t.Errorf("model = %+v, want the last valid values", got)
+	}
+	errors := form.Errors()
+	if len(errors) != 2 || errors["name"] == nil || errors["volume"] == nil {
+		t.Fatalf("errors = %v, want name and volume", errors)
+	}
+	if got := root.QuerySelector(`[data-error-for="name"]`).GetText(); got != "is required" {
+		t.Errorf("error message = %q", got)
+	}
+
+	edit(root, `[name="name"]`, "cy")
+	if _, failed := form.Errors()["name"]; failed || root.QuerySelector(`[name="name"]`).ClassList().Contains("invalid") {
+		t.Error("name still marked invalid after a valid edit")
+	}
+}
+
+func TestBindFormReportsUnbindableFields(t *testing.T) {
+	root := newContainer(t)
+
+	type unexported struct {
+		name string `form:"name"`
+	}
+	type unsupported struct {
+		Tags []string `form:"tags"`
+	}
+	type badRule struct {
+		Name string `form:"name" validate:"between=1"`
+	}
+
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{bindErr(root, NewSignal(unexported{})), "must be exported"},
+		{bindErr(root, NewSignal(unsupported{})), "cannot bind a field of kind slice"},
+		{bindErr(root, NewSignal(badRule{})), "unknown rule"},
+		{bindErr(root, NewSignal(42)), "needs a struct"},
+	} {
+		if test.err == nil || !strings.Contains(test.err.Error(), test.want) {
+			t.Errorf("err = %v, want it to mention %q", test.err, test.want)
+		}
+	}
+}
+
+// bindErr returns the error of binding model to root
+func bindErr[T any](root Element, model *Signal[T]) error {
+	_, err := BindForm(root, model)
+	return err
+}
@@ -0,0 +1,170 @@
+package dom
+
+import (
+	"strings"
+)
+
+// Arg is an argument to H: an attribute set, a property set, a class, an
+// event binding, a key or a child
+type Arg interface {
+	apply(node *VNode)
+}
+
+// Attrs sets HTML attributes. Values are assigned with setAttribute and are
+// never parsed as markup. Inline handler attributes such as onclick are
+// ignored; bind events with On instead
+type Attrs map[string]string
+
+// Props sets DOM properties such as value or checked. innerHTML and
+// outerHTML are ignored so that text can't be turned into markup
+type Props map[string]interface{}
+
+// Children adds a list of child nodes. nil entries are skipped, which makes
+// it easy to leave out optional children
+type Children []*VNode
+
+type classArg struct {
+	name string
+	on   bool
+}
+
+type eventArg struct {
+	eventType string
+	handler   func(Event)
+}
+
+type keyArg string
+
+type createArg func(Element)
+
+// H builds a virtual element with the given tag:
+//
+//	H("li", Key(todo.ID), Attrs{"data-id": todo.ID}, Class("completed", todo.Completed),
+//		H("span", Text(todo.Text)),
+//		H("button", On("click", onDelete), Text("×")),
+//	)
+//
+// Text is always inserted as a text node, so user input is displayed as
+// typed and never interpreted as HTML. nil arguments are skipped
+func H(tag string, args ...Arg) *VNode {
+	node := &VNode{Tag: tag}
+	for _, arg := range args {
+		if arg != nil {
+			arg.apply(node)
+		}
+	}
+	return node
+}
+
+// Text creates a text node. It is the only way H adds text, and the text is
+// inserted with createTextNode
+func Text(text string) *VNode {
+	return TextNode(text)
+}
+
+// Class adds a class name when on is true
+func Class(name string, on bool) Arg {
+	return classArg{name, on}
+}
+
+// On binds an event handler to the element
+func On(eventType string, handler func(Event)) Arg {
+	return eventArg{eventType, handler}
+}
+
+// Key sets the key identifying the element among its siblings
+func Key(key string) Arg {
+	return keyArg(key)
+}
+
+// OnCreate runs fn once the element has been created and inserted
+func OnCreate(fn func(Element)) Arg {
+	return createArg(fn)
+}
+
+// apply lets a *VNode be passed to H as a child
+func (v *VNode) apply(node *VNode) {
+	if v != nil {
+		node.Children = append(node.Children, v)
+	}
+}
+
+func (a Attrs) apply(node *VNode) {
+	if node.Attrs == nil {
+		node.Attrs = map[string]string{}
+	}
+
+	for name, value := range a {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "on") {
+			continue
+		}
+
+		// Classes accumulate so Attrs and Class can be combined
+		if lower == "class" {
+			addClass(node, value)
+			continue
+		}
+
+		node.Attrs[name] = value
+	}
+}
+
+func (p Props) apply(node *VNode) {
+	if node.Props == nil {
+		node.Props = map[string]interface{}{}
+	}
+
+	for name, value := range p {
+		if name == "innerHTML" || name == "outerHTML" {
+			continue
+		}
+		node.Props[name] = value
+	}
+}
+
+func (c Children) apply(node *VNode) {
+	for _, child := range c {
+		child.apply(node)
+	}
+}
+
+func (c classArg) apply(node *VNode) {
+	if c.on {
+		addClass(node, c.name)
+	}
+}
+
+func (e eventArg) apply(node *VNode) {
+	if e.handler == nil {
+		return
+	}
+	if node.Events == nil {
+		node.Events = map[string]func(Event){}
+	}
+	node.Events[e.eventType] = e.handler
+}
+
+func (k keyArg) apply(node *VNode) {
+	node.Key = string(k)
+}
+
+func (c createArg) apply(node *VNode) {
+	node.OnCreate = c
+}
+
+// addClass appends class names to the node's class attribute
+func addClass(node *VNode, names string) {
+	if strings.TrimSpace(names) == "" {
+		return
+	}
+	if node.Attrs == nil {
+		node.Attrs = map[string]string{}
+	}
+
+	if current := node.Attrs["class"]; current != "" {
+		node.Attrs["class"] = current + " " + strings.TrimSpace(names)
+	} else {
+		node.Attrs["class"] = strings.TrimSpace(names)
+	}
+}
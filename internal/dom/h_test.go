@@ -0,0 +1,62 @@
+package dom
+
+import "testing"
+
+// renderNode patches node into a fresh container and returns its element
+func renderNode(t *testing.T, node *VNode) Element {
+	t.Helper()
+	container := newContainer(t)
+	NewReconciler(container).Patch([]*VNode{node})
+	return wrapElement(container.El.Get("firstElementChild"))
+}
+
+func TestHNeverParsesTextAsMarkup(t *testing.T) {
+	text := `<img src=x onerror="alert(1)">`
+	li := renderNode(t, H("li",
+		Attrs{"title": `"><script>`, "onclick": "alert(1)"},
+		Props{"innerHTML": "<b>bold</b>", "value": "v"},
+		H("span", Text(text)),
+	))
+
+	if li.QuerySelector("img").Exists() || li.QuerySelector("b").Exists() {
+		t.Fatal("text inserted as markup")
+	}
+	if got := li.QuerySelector("span").GetText(); got != text {
+		t.Errorf("text %q, want it as typed", got)
+	}
+	if li.GetAttribute("title") != `"><script>` || li.HasAttribute("onclick") {
+		t.Errorf("title %q, onclick set %v", li.GetAttribute("title"), li.HasAttribute("onclick"))
+	}
+}
+
+func TestHClassesAndChildren(t *testing.T) {
+	var created Element
+	li := renderNode(t, H("li",
+		Attrs{"class": "todo"},
+		Class("completed", true),
+		Class("editing", false),
+		Children{H("b"), nil, H("i")},
+		nil,
+		OnCreate(func(el Element) { created = el }),
+	))
+
+	if got := li.GetAttribute("class"); got != "todo completed" {
+		t.Errorf("class %q, want %q", got, "todo completed")
+	}
+	if got := li.El.Get("children").Length(); got != 2 {
+		t.Errorf("%d children, want the nil ones skipped", got)
+	}
+	if !created.Exists() || !created.El.Equal(li.El) {
+		t.Error("OnCreate not given the element")
+	}
+}
+
+func TestHBindsEvents(t *testing.T) {
+	clicks := 0
+	button := renderNode(t, H("button", On("click", func(Event) { clicks++ }), On("focus", nil)))
+
+	button.El.Call("click")
+	if clicks != 1 {
+		t.Errorf("handler ran %d times", clicks)
+	}
+}
@@ -0,0 +1,229 @@
+package dom
+
+// scopeProperty is the expando property linking a DOM node to its owner scope
+const scopeProperty = "__gorgasmScope"
+
+// Listener is a handle to an event listener attached through this package
+type Listener struct {
+	target    Value
+	eventType string
+	callback  Func
+	capture   bool
+	active    bool
+}
+
+// newListener attaches fn to target and returns a handle to it
+func newListener(target Value, eventType string, fn func(Value)) *Listener {
+	return newCaptureListener(target, eventType, fn, false)
+}
+
+// newCaptureListener attaches fn to target, optionally in the capture phase
+func newCaptureListener(target Value, eventType string, fn func(Value), capture bool) *Listener {
+	callback := FuncOf(func(_ Value, args []Value) interface{} {
+		if len(args) > 0 {
+			fn(args[0])
+		} else {
+			fn(Undefined())
+		}
+		return nil
+	})
+
+	target.Call("addEventListener", eventType, callback, capture)
+
+	return &Listener{
+		target:    target,
+		eventType: eventType,
+		callback:  callback,
+		capture:   capture,
+		active:    true,
+	}
+}
+
+// Remove detaches the listener and releases its callback
+func (l *Listener) Remove() {
+	if l == nil || !l.active {
+		return
+	}
+
+	l.active = false
+	l.target.Call("removeEventListener", l.eventType, l.callback, l.capture)
+	l.callback.Release()
+}
+
+// Active reports whether the listener is still attached
+func (l *Listener) Active() bool {
+	return l != nil && l.active
+}
+
+// Scope owns a group of listeners, timers and other cleanups so they can be
+// released together
+type Scope struct {
+	listeners []*Listener
+	cleanups  []func()
+	released  bool
+}
+
+// NewScope creates an empty scope
+func NewScope() *Scope {
+	return &Scope{}
+}
+
+// Listen adds a listener to the scope and returns it
+func (s *Scope) Listen(listener *Listener) *Listener {
+	if s.released {
+		listener.Remove()
+		return listener
+	}
+
+	s.listeners = append(s.listeners, listener)
+	return listener
+}
+
+// RemoveListeners removes every listener in the scope for the given event type
+func (s *Scope) RemoveListeners(eventType string) {
+	kept := s.listeners[:0]
+	for _, listener := range s.listeners {
+		if listener.eventType == eventType {
+			listener.Remove()
+		} else {
+			kept = append(kept, listener)
+		}
+	}
+	s.listeners = kept
+}
+
+// OnRelease registers a cleanup to run when the scope is released. If the scope
+// has already been released the cleanup runs immediately
+func (s *Scope) OnRelease(cleanup func()) {
+	if s.released {
+		cleanup()
+		return
+	}
+	s.cleanups = append(s.cleanups, cleanup)
+}
+
+// Release removes every listener in the scope and runs the registered
+// cleanups in reverse order
+func (s *Scope) Release() {
+	listeners := s.listeners
+	cleanups := s.cleanups
+	s.listeners = nil
+	s.cleanups = nil
+	s.released = true
+
+	for _, listener := range listeners {
+		listener.Remove()
+	}
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// elementScopes holds the owner scopes of elements, keyed by the id stored on
+// the element under scopeProperty
+var (
+	elementScopes = map[int]*Scope{}
+	nextScopeID   = 1
+)
+
+// Scope returns the owner scope of the element, creating it on first use.
+// An empty Element has an already released scope, so anything added to it is
+// released straight away
+func (e Element) Scope() *Scope {
+	if !e.Exists() {
+		return &Scope{released: true}
+	}
+
+	if id := e.El.Get(scopeProperty); id.Type() == TypeNumber {
+		if scope, ok := elementScopes[id.Int()]; ok {
+			return scope
+		}
+	}
+
+	id := nextScopeID
+	nextScopeID++
+
+	scope := NewScope()
+	elementScopes[id] = scope
+	e.El.Set(scopeProperty, id)
+
+	return scope
+}
+
+// releaseScope releases the owner scope of a single node if it has one
+func releaseScope(node Value) {
+	id := node.Get(scopeProperty)
+	if id.Type() != TypeNumber {
+		return
+	}
+
+	if scope, ok := elementScopes[id.Int()]; ok {
+		delete(elementScopes, id.Int())
+		scope.Release()
+	}
+	node.Delete(scopeProperty)
+}
+
+// Release releases everything attached under the element and its descendants
+func (e Element) Release() Element {
+	if !e.Exists() {
+		return e
+	}
+
+	releaseScope(e.El)
+
+	// Text nodes and comments have no descendants to walk
+	if e.El.Get("nodeType").Int() != 1 {
+		return e
+	}
+
+	descendants := e.El.Call("querySelectorAll", "*")
+	length := descendants.Get("length").Int()
+	for i := 0; i < length; i++ {
+		releaseScope(descendants.Call("item", i))
+	}
+
+	return e
+}
+
+// Remove detaches the element from its parent and releases everything attached under it
+func (e Element) Remove() {
+	if !e.Exists() {
+		return
+	}
+
+	e.El.Call("remove")
+	e.Release()
+}
+
+// Timer is a handle to a pending timeout, interval or animation frame
+type Timer struct {
+	id       Value
+	callback Func
+	interval bool
+	frame    bool
+	active   bool
+}
+
+// Clear cancels the timer and releases its callback
+func (t *Timer) Clear() {
+	if t == nil || !t.active {
+		return
+	}
+
+	t.active = false
+	if t.frame {
+		Global().Call("cancelAnimationFrame", t.id)
+	} else if t.interval {
+		Global().Call("clearInterval", t.id)
+	} else {
+		Global().Call("clearTimeout", t.id)
+	}
+	t.callback.Release()
+}
+
+// Active reports whether the timer is still pending
+func (t *Timer) Active() bool {
+	return t != nil && t.active
+}
@@ -0,0 +1,142 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListenerRemove(t *testing.T) {
+	button := newContainer(t)
+	clicks := 0
+	listener := button.AddEventListener("click", func() { clicks++ })
+
+	button.El.Call("click")
+	listener.Remove()
+	listener.Remove()
+	button.El.Call("click")
+
+	if clicks != 1 || listener.Active() {
+		t.Errorf("%d clicks, active %v after Remove", clicks, listener.Active())
+	}
+}
+
+func TestRemoveEventListenerKeepsOtherTypes(t *testing.T) {
+	element := newContainer(t)
+	var got []string
+	element.AddEventListener("click", func() { got = append(got, "click") })
+	element.AddEventListenerWithEvent("focus", func(event Event) { got = append(got, event.Type()) })
+
+	element.RemoveEventListener("click")
+	element.El.Call("click")
+	element.El.Call("dispatchEvent", Global().Get("Event").New("focus"))
+
+	if !reflect.DeepEqual(got, []string{"focus"}) {
+		t.Errorf("ran %v, want [focus]", got)
+	}
+}
+
+func TestRemoveReleasesDescendantListeners(t *testing.T) {
+	container := newContainer(t)
+	child := Document().CreateElement("button")
+	container.AppendChild(child)
+	listener := child.AddEventListener("click", func() {})
+	released := false
+	child.Scope().OnRelease(func() { released = true })
+
+	container.Remove()
+
+	if listener.Active() || !released {
+		t.Errorf("child listener active %v, cleanup ran %v after its ancestor was removed", listener.Active(), released)
+	}
+	// A callback still attached would panic once released
+	child.El.Call("click")
+}
+
+func TestScopeRelease(t *testing.T) {
+	scope := NewScope()
+	var order []string
+	scope.OnRelease(func() { order = append(order, "first") })
+	scope.OnRelease(func() { order = append(order, "second") })
+	listener := scope.Listen(newListener(newContainer(t).El, "click", func(Value) {}))
+
+	scope.Release()
+	scope.OnRelease(func() { order = append(order, "late") })
+	late := scope.Listen(newListener(newContainer(t).El, "click", func(Value) {}))
+
+	if !reflect.DeepEqual(order, []string{"second", "first", "late"}) {
+		t.Errorf("cleanups ran %v", order)
+	}
+	if listener.Active() || late.Active() {
+		t.Error("listener left attached by a released scope")
+	}
+}
+
+func TestEmptyElementListenersAreNoOps(t *testing.T) {
+	var missing Element
+	if missing.AddEventListener("click", func() {}) != nil || !missing.Scope().released {
+		t.Error("empty element took a listener")
+	}
+	missing.RemoveEventListener("click")
+	missing.Release()
+	missing.Remove()
+}
+
+func TestTimers(t *testing.T) {
+	memory := backend.(*MemoryBackend)
+	window := GetWindow()
+	var ran []string
+
+	once := window.SetTimeout(func() { ran = append(ran, "timeout") }, 10)
+	cleared := window.SetTimeout(func() { ran = append(ran, "cleared") }, 10)
+	ticks := window.SetInterval(func() { ran = append(ran, "tick") }, 10)
+	window.ClearTimeout(cleared)
+
+	memory.Advance(25 * time.Millisecond)
+	window.ClearInterval(ticks)
+	memory.Advance(25 * time.Millisecond)
+
+	if !reflect.DeepEqual(ran, []string{"timeout", "tick", "tick"}) {
+		t.Errorf("ran %v", ran)
+	}
+	if once.Active() || cleared.Active() || ticks.Active() {
+		t.Error("timer still active after running or being cleared")
+	}
+}
+
+func TestRequestAnimationFrame(t *testing.T) {
+	window := GetWindow()
+	frames := 0
+	frame := window.RequestAnimationFrame(func(timestamp float64) { frames++ })
+	cancelled := window.RequestAnimationFrame(func(float64) { frames += 10 })
+	window.CancelAnimationFrame(cancelled)
+
+	runFrames()
+	if frames != 1 || frame.Active() {
+		t.Errorf("%d frames ran, active %v", frames, frame.Active())
+	}
+}
+
+func TestAnimationFramesFollowEachOther(t *testing.T) {
+	memory := NewMemoryBackend()
+	var stamps []int
+	var callback Func
+	callback = memory.FuncOf(func(_ Value, args []Value) interface{} {
+		stamps = append(stamps, int(args[0].Float()))
+		if len(stamps) < 3 {
+			memory.Global().Call("requestAnimationFrame", callback)
+		}
+		return nil
+	})
+
+	memory.Advance(time.Second)
+	memory.Global().Call("requestAnimationFrame", callback)
+	memory.Advance(time.Second)
+
+	if want := []int{1016, 1033, 1050}; !reflect.DeepEqual(stamps, want) {
+		t.Errorf("frames at %v, want %v", stamps, want)
+	}
+}
@@ -0,0 +1,1371 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MemoryBackend is a pure-Go stand-in for the browser. It provides a window
+// with a document tree, attributes, classes, styles, event dispatch,
+// localStorage and sessionStorage, location and session history, promises,
+// fetch served by an http.Handler, and a virtual clock for timers, so code
+// built on this package can run under a normal go test
+type MemoryBackend struct {
+	// Alerts records the messages passed to window.alert
+	Alerts []string
+	// Console records the messages passed to console.log, info, warn and
+	// error, prefixed with the method name and a colon
+	Console []string
+	// ConfirmResult is returned by window.confirm
+	ConfirmResult bool
+	// PromptResult is returned by window.prompt when set, otherwise the default value is
+	PromptResult *string
+	// FetchHandler serves the requests made with window.fetch. When nil every
+	// request fails as if the network were down
+	FetchHandler http.Handler
+
+	window   *memObject
+	document *memObject
+	now      float64
+	timers   []*memTimer
+	nextID   int
+
+	// Session history behind location and history
+	history *memHistory
+
+	// Custom element definitions behind window.customElements
+	customElements *memCustomElements
+
+	// Media features and the lists created by matchMedia
+	mediaFeatures map[string]string
+	mediaQueries  []*memObject
+
+	// Intersection and resize observers with targets, and the pending check
+	layoutObservers []*memLayoutObserver
+	layoutTimer     int
+}
+
+// memTimer is a pending timeout or interval on the virtual clock
+type memTimer struct {
+	id       int
+	due      float64
+	interval float64
+	repeat   bool
+	fn       Value
+	args     []Value
+}
+
+// NewMemoryBackend creates a backend with an empty document
+func NewMemoryBackend() *MemoryBackend {
+	m := &MemoryBackend{
+		ConfirmResult: true,
+		nextID:        1,
+		mediaFeatures: map[string]string{},
+	}
+	for name, value := range memMediaDefaults {
+		m.mediaFeatures[name] = value
+	}
+	m.window = m.newWindow()
+	return m
+}
+
+// Global returns the window object
+func (m *MemoryBackend) Global() Value {
+	return m.window.value()
+}
+
+// FuncOf wraps a Go function as a callable value
+func (m *MemoryBackend) FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	f := &memFunc{}
+	obj := newMemFunction(func(this Value, args []Value) Value {
+		if f.released {
+			panic("dom: call to released function")
+		}
+		return memValueOf(fn(this, args))
+	})
+	f.memValue = memValue{kind: TypeFunction, obj: obj}
+	return f
+}
+
+// Null returns the null value
+func (m *MemoryBackend) Null() Value {
+	return memNull
+}
+
+// Undefined returns the undefined value
+func (m *MemoryBackend) Undefined() Value {
+	return memUndefined
+}
+
+// ValueOf converts a Go value to a memory value
+func (m *MemoryBackend) ValueOf(x interface{}) Value {
+	return memValueOf(x)
+}
+
+// CopyBytesToGo copies bytes from a Uint8Array into dst
+func (m *MemoryBackend) CopyBytesToGo(dst []byte, src Value) int {
+	return copy(dst, memBytesOf(src, "CopyBytesToGo", "src"))
+}
+
+// CopyBytesToJS copies bytes from src into a Uint8Array
+func (m *MemoryBackend) CopyBytesToJS(dst Value, src []byte) int {
+	return copy(memBytesOf(dst, "CopyBytesToJS", "dst"), src)
+}
+
+// Now returns the time elapsed on the virtual clock
+func (m *MemoryBackend) Now() time.Duration {
+	return time.Duration(m.now * float64(time.Millisecond))
+}
+
+// Advance moves the virtual clock forward, running every timer that falls due
+func (m *MemoryBackend) Advance(d time.Duration) {
+	m.advanceTo(m.now + float64(d)/float64(time.Millisecond))
+}
+
+// advanceTo moves the virtual clock to target, given in milliseconds
+func (m *MemoryBackend) advanceTo(target float64) {
+	for {
+		timer := m.nextTimer(target)
+		if timer == nil {
+			break
+		}
+
+		m.now = timer.due
+		if timer.repeat {
+			timer.due += timer.interval
+		} else {
+			m.removeTimer(timer.id)
+		}
+
+		callMem(timer.fn, memUndefined, timer.args)
+	}
+
+	m.now = target
+}
+
+// RunTimers advances the clock until no timeouts are pending. Intervals keep
+// running while they are due but do not keep the loop alive on their own
+func (m *MemoryBackend) RunTimers() {
+	for i := 0; i < 10000; i++ {
+		due := math.Inf(1)
+		for _, timer := range m.timers {
+			if !timer.repeat && timer.due < due {
+				due = timer.due
+			}
+		}
+		if math.IsInf(due, 1) {
+			return
+		}
+		// Going through a Duration could round the clock to just before due
+		m.advanceTo(due)
+	}
+}
+
+// PendingTimers returns the number of scheduled timeouts and intervals
+func (m *MemoryBackend) PendingTimers() int {
+	return len(m.timers)
+}
+
+// nextTimer returns the earliest timer due at or before limit
+func (m *MemoryBackend) nextTimer(limit float64) *memTimer {
+	var next *memTimer
+	for _, timer := range m.timers {
+		if timer.due > limit {
+			continue
+		}
+		if next == nil || timer.due < next.due || (timer.due == next.due && timer.id < next.id) {
+			next = timer
+		}
+	}
+	return next
+}
+
+// addTimer schedules fn on the virtual clock and returns its id
+func (m *MemoryBackend) addTimer(fn Value, delay float64, repeat bool, args []Value) int {
+	if delay < 0 || math.IsNaN(delay) {
+		delay = 0
+	}
+	if repeat && delay < 1 {
+		delay = 1
+	}
+
+	id := m.nextID
+	m.nextID++
+
+	m.timers = append(m.timers, &memTimer{
+		id:       id,
+		due:      m.now + delay,
+		interval: delay,
+		repeat:   repeat,
+		fn:       fn,
+		args:     args,
+	})
+	return id
+}
+
+// removeTimer cancels the timer with the given id
+func (m *MemoryBackend) removeTimer(id int) {
+	for i, timer := range m.timers {
+		if timer.id == id {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+// newWindow builds the global object
+func (m *MemoryBackend) newWindow() *memObject {
+	window := newMemObject(windowClass)
+	window.data = m
+
+	// The document upgrades custom elements as it creates them
+	window.props["customElements"] = m.newMemCustomElements().value()
+	m.document = m.newDocument()
+
+	window.props["window"] = window.value()
+	window.props["self"] = window.value()
+	window.props["globalThis"] = window.value()
+	window.props["document"] = m.document.value()
+	location, history := m.newMemLocation()
+	window.props["location"] = location.value()
+	window.props["history"] = history.value()
+	window.props["localStorage"] = newMemStorage().value()
+	window.props["sessionStorage"] = newMemStorage().value()
+	window.props["navigator"] = memObjectOf(map[string]interface{}{
+		"userAgent": "gorgasm-memory",
+		"platform":  "Linux x86_64",
+		"language":  "en-US",
+		"onLine":    true,
+	})
+	window.props["console"] = m.newMemConsole()
+	window.props["innerWidth"] = memNumber(1024)
+	window.props["innerHeight"] = memNumber(768)
+	window.props["devicePixelRatio"] = memNumber(1)
+	window.props["scrollX"] = memNumber(0)
+	window.props["scrollY"] = memNumber(0)
+	window.props["performance"] = memMethods(map[string]func(args []Value) Value{
+		"now": func(_ []Value) Value { return memNumber(m.now) },
+	})
+
+	// Constructors
+	window.props["Object"] = newMemConstructor(func(_ []Value) Value {
+		return newMemObject(objectClass).value()
+	})
+	window.props["Array"] = newMemConstructor(func(args []Value) Value {
+		length := 0
+		if len(args) == 1 && args[0].Type() == TypeNumber {
+			length = args[0].Int()
+		}
+		array := newMemArray(make([]Value, length))
+		for i := range array.array {
+			array.array[i] = memUndefined
+		}
+		return array.value()
+	})
+	window.props["DataTransfer"] = newMemConstructor(func(_ []Value) Value {
+		return newMemDataTransfer().value()
+	})
+	window.props["Error"] = newMemConstructor(func(args []Value) Value {
+		return memError("Error", argString(args, 0))
+	})
+	window.props["Promise"] = newMemPromiseConstructor()
+	window.props["Headers"] = newMemConstructor(func(args []Value) Value {
+		headers := newMemHeaders(nil)
+		copyMemHeaders(headers.data.(http.Header), argAt(args, 0))
+		return headers.value()
+	})
+	window.props["AbortController"] = newMemConstructor(newMemAbortController)
+	window.props["ArrayBuffer"] = newMemConstructor(func(args []Value) Value {
+		return newMemArrayBuffer(make([]byte, int(argFloat(args, 0)))).value()
+	})
+	window.props["Uint8Array"] = newMemConstructor(newMemUint8Array)
+	window.props["MutationObserver"] = newMemConstructor(m.newMemMutationObserver)
+	window.props["IntersectionObserver"] = newMemConstructor(m.newMemIntersectionObserver)
+	window.props["ResizeObserver"] = newMemConstructor(m.newMemResizeObserver)
+	for name, defaults := range memEventDefaults {
+		defaults := defaults
+		window.props[name] = newMemConstructor(func(args []Value) Value {
+			return newMemEvent(args, defaults).value()
+		})
+	}
+
+	return window
+}
+
+// newMemConsole creates a console recording its messages in m.Console
+func (m *MemoryBackend) newMemConsole() Value {
+	methods := map[string]func(args []Value) Value{}
+	for _, name := range []string{"log", "info", "warn", "error", "debug"} {
+		name := name
+		methods[name] = func(args []Value) Value {
+			parts := make([]string, len(args))
+			for i, arg := range args {
+				parts[i] = jsString(arg)
+			}
+			m.Console = append(m.Console, name+": "+strings.Join(parts, " "))
+			return memUndefined
+		}
+	}
+	return memMethods(methods)
+}
+
+// memFrameInterval is the time between animation frames in milliseconds
+const memFrameInterval = 1000.0 / 60
+
+// windowClass implements the global object
+var windowClass = &memClass{
+	name:   "Window",
+	parent: eventTargetClass,
+	methods: map[string]memMethod{
+		"setTimeout": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			return memNumber(float64(m.addTimer(argAt(args, 0), argFloat(args, 1), false, restArgs(args, 2))))
+		},
+		"setInterval": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			return memNumber(float64(m.addTimer(argAt(args, 0), argFloat(args, 1), true, restArgs(args, 2))))
+		},
+		"clearTimeout": func(this *memObject, args []Value) Value {
+			this.data.(*MemoryBackend).removeTimer(int(argFloat(args, 0)))
+			return memUndefined
+		},
+		"clearInterval": func(this *memObject, args []Value) Value {
+			this.data.(*MemoryBackend).removeTimer(int(argFloat(args, 0)))
+			return memUndefined
+		},
+		"requestAnimationFrame": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+
+			// Frames fall on a fixed 60Hz grid of the virtual clock. The grid
+			// index is rounded so a clock sitting on a frame, such as 1000ms,
+			// never schedules the next one at its own time
+			frame := (math.Floor(m.now/memFrameInterval+1e-9) + 1) * memFrameInterval
+			id := m.addTimer(argAt(args, 0), frame-m.now, false, []Value{memNumber(frame)})
+			return memNumber(float64(id))
+		},
+		"cancelAnimationFrame": func(this *memObject, args []Value) Value {
+			this.data.(*MemoryBackend).removeTimer(int(argFloat(args, 0)))
+			return memUndefined
+		},
+		"matchMedia": func(this *memObject, args []Value) Value {
+			return this.data.(*MemoryBackend).matchMedia(argString(args, 0))
+		},
+		"scrollTo": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			m.scrollWindow(scrollArgs(args, this.get("scrollX").Float(), this.get("scrollY").Float()))
+			return memUndefined
+		},
+		"scrollBy": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			left, top := scrollArgs(args, 0, 0)
+			m.scrollWindow(this.get("scrollX").Float()+left, this.get("scrollY").Float()+top)
+			return memUndefined
+		},
+		"fetch": func(this *memObject, args []Value) Value {
+			return this.data.(*MemoryBackend).fetch(args)
+		},
+		"alert": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			m.Alerts = append(m.Alerts, argString(args, 0))
+			return memUndefined
+		},
+		"confirm": func(this *memObject, _ []Value) Value {
+			return memBool(this.data.(*MemoryBackend).ConfirmResult)
+		},
+		"prompt": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			if m.PromptResult != nil {
+				return memString(*m.PromptResult)
+			}
+			return memString(argString(args, 1))
+		},
+	},
+}
+
+// memMethod implements a method on a memory object
+type memMethod func(this *memObject, args []Value) Value
+
+// memClass describes the behaviour shared by a kind of memory object
+type memClass struct {
+	name    string
+	parent  *memClass
+	methods map[string]memMethod
+	get     func(obj *memObject, name string) (Value, bool)
+	set     func(obj *memObject, name string, value Value) bool
+
+	bound map[string]Value
+}
+
+// method looks up a method along the class chain
+func (c *memClass) method(name string) (memMethod, bool) {
+	for class := c; class != nil; class = class.parent {
+		if method, ok := class.methods[name]; ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// methodValue returns a function value for a method, created once per class
+func (c *memClass) methodValue(name string, method memMethod) Value {
+	if c.bound == nil {
+		c.bound = map[string]Value{}
+	}
+	if value, ok := c.bound[name]; ok {
+		return value
+	}
+
+	value := newMemFunction(func(this Value, args []Value) Value {
+		receiver, ok := this.(memValue)
+		if !ok || receiver.obj == nil {
+			panic(fmt.Sprintf("dom: illegal invocation of %s.%s", c.name, name))
+		}
+		return method(receiver.obj, args)
+	}).value()
+
+	c.bound[name] = value
+	return value
+}
+
+// memObject is an object, array or function in the memory backend
+type memObject struct {
+	class     *memClass
+	props     map[string]Value
+	array     []Value
+	isArray   bool
+	fn        func(this Value, args []Value) Value
+	ctor      func(args []Value) Value
+	node      *memNode
+	data      interface{}
+	listeners []*memListener
+}
+
+// objectClass is the class of plain objects
+var objectClass = &memClass{name: "Object"}
+
+// arrayClass is the class of arrays and node lists
+var arrayClass = &memClass{
+	name: "Array",
+	methods: map[string]memMethod{
+		"item": func(this *memObject, args []Value) Value {
+			i := int(argFloat(args, 0))
+			if i < 0 || i >= len(this.array) {
+				return memNull
+			}
+			return this.array[i]
+		},
+		"push": func(this *memObject, args []Value) Value {
+			this.array = append(this.array, args...)
+			return memNumber(float64(len(this.array)))
+		},
+		"includes": func(this *memObject, args []Value) Value {
+			for _, item := range this.array {
+				if item.Equal(argAt(args, 0)) {
+					return memBool(true)
+				}
+			}
+			return memBool(false)
+		},
+		"indexOf": func(this *memObject, args []Value) Value {
+			for i, item := range this.array {
+				if item.Equal(argAt(args, 0)) {
+					return memNumber(float64(i))
+				}
+			}
+			return memNumber(-1)
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		if name == "length" {
+			return memNumber(float64(len(obj.array))), true
+		}
+		if i, err := strconv.Atoi(name); err == nil {
+			if i >= 0 && i < len(obj.array) {
+				return obj.array[i], true
+			}
+			return memUndefined, true
+		}
+		return nil, false
+	},
+}
+
+// functionClass is the class of functions
+var functionClass = &memClass{name: "Function"}
+
+// newMemObject creates an object of the given class
+func newMemObject(class *memClass) *memObject {
+	return &memObject{
+		class: class,
+		props: map[string]Value{},
+	}
+}
+
+// newMemArray creates an array holding items
+func newMemArray(items []Value) *memObject {
+	obj := newMemObject(arrayClass)
+	obj.isArray = true
+	obj.array = items
+	return obj
+}
+
+// newMemFunction creates a callable object
+func newMemFunction(fn func(this Value, args []Value) Value) *memObject {
+	obj := newMemObject(functionClass)
+	obj.fn = fn
+	return obj
+}
+
+// newMemConstructor creates a function that can be called with New
+func newMemConstructor(ctor func(args []Value) Value) Value {
+	obj := newMemFunction(func(_ Value, args []Value) Value {
+		return ctor(args)
+	})
+	obj.ctor = ctor
+	return obj.value()
+}
+
+// memMethods creates a plain object exposing the given functions
+func memMethods(methods map[string]func(args []Value) Value) Value {
+	obj := newMemObject(objectClass)
+	for name, method := range methods {
+		method := method
+		obj.props[name] = newMemFunction(func(_ Value, args []Value) Value {
+			return method(args)
+		}).value()
+	}
+	return obj.value()
+}
+
+// memObjectOf creates a plain object from a Go map
+func memObjectOf(props map[string]interface{}) Value {
+	return memValueOf(props)
+}
+
+// value wraps the object as a Value
+func (o *memObject) value() memValue {
+	if o.fn != nil {
+		return memValue{kind: TypeFunction, obj: o}
+	}
+	return memValue{kind: TypeObject, obj: o}
+}
+
+// get reads a property, consulting the class first
+func (o *memObject) get(name string) Value {
+	for class := o.class; class != nil; class = class.parent {
+		if class.get != nil {
+			if value, ok := class.get(o, name); ok {
+				return value
+			}
+		}
+	}
+
+	if value, ok := o.props[name]; ok {
+		return value
+	}
+
+	if method, ok := o.class.method(name); ok {
+		return o.class.methodValue(name, method)
+	}
+
+	return memUndefined
+}
+
+// set writes a property, letting the class intercept it first
+func (o *memObject) set(name string, value Value) {
+	for class := o.class; class != nil; class = class.parent {
+		if class.set != nil && class.set(o, name, value) {
+			return
+		}
+	}
+
+	if o.isArray {
+		if i, err := strconv.Atoi(name); err == nil && i >= 0 {
+			for len(o.array) <= i {
+				o.array = append(o.array, memUndefined)
+			}
+			o.array[i] = value
+			return
+		}
+	}
+
+	o.props[name] = value
+}
+
+// memValue is a JavaScript value in the memory backend
+type memValue struct {
+	kind Type
+	b    bool
+	n    float64
+	s    string
+	obj  *memObject
+}
+
+// memFunc is a Go function exposed to the memory backend
+type memFunc struct {
+	memValue
+	released bool
+}
+
+// Release marks the function released. Like a released js.Func, calling it
+// afterwards panics, so a callback used after its release shows up in tests
+func (f *memFunc) Release() {
+	f.released = true
+}
+
+var (
+	memUndefined = memValue{kind: TypeUndefined}
+	memNull      = memValue{kind: TypeNull}
+)
+
+func memBool(b bool) memValue {
+	return memValue{kind: TypeBoolean, b: b}
+}
+
+func memNumber(n float64) memValue {
+	return memValue{kind: TypeNumber, n: n}
+}
+
+func memString(s string) memValue {
+	return memValue{kind: TypeString, s: s}
+}
+
+// memValueOf converts a Go value into a memory value
+func memValueOf(x interface{}) Value {
+	switch value := x.(type) {
+	case nil:
+		return memNull
+	case *memFunc:
+		return value.memValue
+	case memValue:
+		return value
+	case Value:
+		panic("dom: value from another backend passed to the memory backend")
+	case bool:
+		return memBool(value)
+	case int:
+		return memNumber(float64(value))
+	case int8:
+		return memNumber(float64(value))
+	case int16:
+		return memNumber(float64(value))
+	case int32:
+		return memNumber(float64(value))
+	case int64:
+		return memNumber(float64(value))
+	case uint:
+		return memNumber(float64(value))
+	case uint8:
+		return memNumber(float64(value))
+	case uint16:
+		return memNumber(float64(value))
+	case uint32:
+		return memNumber(float64(value))
+	case uint64:
+		return memNumber(float64(value))
+	case uintptr:
+		return memNumber(float64(value))
+	case float32:
+		return memNumber(float64(value))
+	case float64:
+		return memNumber(value)
+	case string:
+		return memString(value)
+	case []interface{}:
+		items := make([]Value, len(value))
+		for i, item := range value {
+			items[i] = memValueOf(item)
+		}
+		return newMemArray(items).value()
+	case []string:
+		items := make([]Value, len(value))
+		for i, item := range value {
+			items[i] = memString(item)
+		}
+		return newMemArray(items).value()
+	case map[string]interface{}:
+		obj := newMemObject(objectClass)
+		for key, item := range value {
+			obj.props[key] = memValueOf(item)
+		}
+		return obj.value()
+	}
+	panic(fmt.Sprintf("dom: invalid value %T", x))
+}
+
+// memArgs converts call arguments into memory values
+func memArgs(args []interface{}) []Value {
+	converted := make([]Value, len(args))
+	for i, arg := range args {
+		converted[i] = memValueOf(arg)
+	}
+	return converted
+}
+
+// callMem calls a function value with the given receiver
+func callMem(fn Value, this Value, args []Value) Value {
+	value, ok := memValueOf(fn).(memValue)
+	if !ok || value.obj == nil || value.obj.fn == nil {
+		panic("dom: value is not a function")
+	}
+	return value.obj.fn(this, args)
+}
+
+func (v memValue) Get(name string) Value {
+	switch v.kind {
+	case TypeUndefined, TypeNull:
+		panic(fmt.Sprintf("dom: cannot read property %q of %s", name, v.kind))
+	case TypeString:
+		if name == "length" {
+			return memNumber(float64(len([]rune(v.s))))
+		}
+		return memUndefined
+	case TypeObject, TypeFunction:
+		return v.obj.get(name)
+	}
+	return memUndefined
+}
+
+func (v memValue) Set(name string, value interface{}) {
+	if v.obj == nil {
+		panic(fmt.Sprintf("dom: cannot set property %q of %s", name, v.kind))
+	}
+	v.obj.set(name, memValueOf(value))
+}
+
+func (v memValue) Delete(name string) {
+	if v.obj == nil {
+		panic(fmt.Sprintf("dom: cannot delete property %q of %s", name, v.kind))
+	}
+	delete(v.obj.props, name)
+}
+
+func (v memValue) Call(method string, args ...interface{}) Value {
+	if v.obj == nil {
+		panic(fmt.Sprintf("dom: cannot call %s on %s", method, v.kind))
+	}
+
+	converted := memArgs(args)
+
+	// Class methods are called directly with the receiver
+	if m, ok := v.obj.class.method(method); ok {
+		if _, overridden := v.obj.props[method]; !overridden {
+			return m(v.obj, converted)
+		}
+	}
+
+	fn := v.obj.get(method)
+	if fn.Type() != TypeFunction {
+		panic(fmt.Sprintf("dom: %s.%s is not a function", v.obj.class.name, method))
+	}
+	return callMem(fn, v, converted)
+}
+
+func (v memValue) Invoke(args ...interface{}) Value {
+	if v.kind != TypeFunction {
+		panic("dom: value is not a function")
+	}
+	return v.obj.fn(memUndefined, memArgs(args))
+}
+
+func (v memValue) New(args ...interface{}) Value {
+	if v.obj == nil || v.obj.ctor == nil {
+		panic("dom: value is not a constructor")
+	}
+	return v.obj.ctor(memArgs(args))
+}
+
+func (v memValue) Index(i int) Value {
+	return v.Get(strconv.Itoa(i))
+}
+
+func (v memValue) SetIndex(i int, value interface{}) {
+	v.Set(strconv.Itoa(i), value)
+}
+
+func (v memValue) Length() int {
+	return v.Get("length").Int()
+}
+
+func (v memValue) Type() Type {
+	return v.kind
+}
+
+func (v memValue) IsNull() bool {
+	return v.kind == TypeNull
+}
+
+func (v memValue) IsUndefined() bool {
+	return v.kind == TypeUndefined
+}
+
+func (v memValue) Truthy() bool {
+	switch v.kind {
+	case TypeUndefined, TypeNull:
+		return false
+	case TypeBoolean:
+		return v.b
+	case TypeNumber:
+		return v.n != 0 && !math.IsNaN(v.n)
+	case TypeString:
+		return v.s != ""
+	}
+	return true
+}
+
+func (v memValue) String() string {
+	switch v.kind {
+	case TypeString:
+		return v.s
+	case TypeUndefined:
+		return "<undefined>"
+	case TypeNull:
+		return "<null>"
+	case TypeBoolean:
+		return "<boolean: " + strconv.FormatBool(v.b) + ">"
+	case TypeNumber:
+		return "<number: " + strconv.FormatFloat(v.n, 'g', -1, 64) + ">"
+	case TypeFunction:
+		return "<function>"
+	}
+	return "<object>"
+}
+
+func (v memValue) Int() int {
+	return int(v.Float())
+}
+
+func (v memValue) Float() float64 {
+	if v.kind != TypeNumber {
+		panic(fmt.Sprintf("dom: call of Value.Float on %s", v.kind))
+	}
+	return v.n
+}
+
+func (v memValue) Bool() bool {
+	if v.kind != TypeBoolean {
+		panic(fmt.Sprintf("dom: call of Value.Bool on %s", v.kind))
+	}
+	return v.b
+}
+
+func (v memValue) Equal(other Value) bool {
+	o, ok := memValueOf(other).(memValue)
+	if !ok || o.kind != v.kind {
+		return false
+	}
+
+	switch v.kind {
+	case TypeUndefined, TypeNull:
+		return true
+	case TypeBoolean:
+		return v.b == o.b
+	case TypeNumber:
+		return v.n == o.n
+	case TypeString:
+		return v.s == o.s
+	}
+	return v.obj == o.obj
+}
+
+// Argument helpers for memory methods
+
+func argAt(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return memUndefined
+}
+
+func argString(args []Value, i int) string {
+	value := argAt(args, i)
+	switch value.Type() {
+	case TypeUndefined:
+		return ""
+	case TypeString:
+		return value.String()
+	}
+	return jsString(value)
+}
+
+func argFloat(args []Value, i int) float64 {
+	value := argAt(args, i)
+	switch value.Type() {
+	case TypeNumber:
+		return value.Float()
+	case TypeString:
+		n, err := strconv.ParseFloat(value.String(), 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return n
+	case TypeBoolean:
+		if value.Bool() {
+			return 1
+		}
+	}
+	return 0
+}
+
+func restArgs(args []Value, from int) []Value {
+	if from >= len(args) {
+		return nil
+	}
+	return args[from:]
+}
+
+// jsString converts a value to a string the way JavaScript's String() does
+func jsString(value Value) string {
+	switch value.Type() {
+	case TypeString:
+		return value.String()
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return strconv.FormatBool(value.Bool())
+	case TypeNumber:
+		n := value.Float()
+		if n == math.Trunc(n) && math.Abs(n) < 1e21 {
+			return strconv.FormatInt(int64(n), 10)
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	case TypeFunction:
+		return "function () { [native code] }"
+	}
+
+	if obj := value.(memValue).obj; obj.isArray {
+		parts := ""
+		for i, item := range obj.array {
+			if i > 0 {
+				parts += ","
+			}
+			if !isNullish(item) {
+				parts += jsString(item)
+			}
+		}
+		return parts
+	}
+	return "[object Object]"
+}
+
+// memStorage implements localStorage and sessionStorage
+type memStorage struct {
+	keys   []string
+	values map[string]string
+}
+
+// storageClass implements the Storage interface
+var storageClass = &memClass{
+	name: "Storage",
+	methods: map[string]memMethod{
+		"getItem": func(this *memObject, args []Value) Value {
+			store := this.data.(*memStorage)
+			if value, ok := store.values[argString(args, 0)]; ok {
+				return memString(value)
+			}
+			return memNull
+		},
+		"setItem": func(this *memObject, args []Value) Value {
+			store := this.data.(*memStorage)
+			key := argString(args, 0)
+			if _, ok := store.values[key]; !ok {
+				store.keys = append(store.keys, key)
+			}
+			store.values[key] = argString(args, 1)
+			return memUndefined
+		},
+		"removeItem": func(this *memObject, args []Value) Value {
+			store := this.data.(*memStorage)
+			key := argString(args, 0)
+			if _, ok := store.values[key]; ok {
+				delete(store.values, key)
+				for i, k := range store.keys {
+					if k == key {
+						store.keys = append(store.keys[:i], store.keys[i+1:]...)
+						break
+					}
+				}
+			}
+			return memUndefined
+		},
+		"clear": func(this *memObject, _ []Value) Value {
+			this.data = &memStorage{values: map[string]string{}}
+			return memUndefined
+		},
+		"key": func(this *memObject, args []Value) Value {
+			store := this.data.(*memStorage)
+			i := int(argFloat(args, 0))
+			if i < 0 || i >= len(store.keys) {
+				return memNull
+			}
+			return memString(store.keys[i])
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		if name == "length" {
+			return memNumber(float64(len(obj.data.(*memStorage).keys))), true
+		}
+		return nil, false
+	},
+}
+
+// newMemStorage creates an empty storage area
+func newMemStorage() *memObject {
+	obj := newMemObject(storageClass)
+	obj.data = &memStorage{values: map[string]string{}}
+	return obj
+}
+
+// memListener is an event listener registered on a memory object
+type memListener struct {
+	eventType string
+	fn        Value
+	capture   bool
+	once      bool
+	removed   bool
+}
+
+// memEventState tracks propagation flags while an event is dispatched
+type memEventState struct {
+	stopped          bool
+	stoppedImmediate bool
+}
+
+// eventTargetClass implements addEventListener, removeEventListener and dispatchEvent
+var eventTargetClass = &memClass{
+	name: "EventTarget",
+	methods: map[string]memMethod{
+		"addEventListener": func(this *memObject, args []Value) Value {
+			capture, once := listenerOptions(argAt(args, 2))
+			fn := argAt(args, 1)
+			if fn.Type() != TypeFunction {
+				return memUndefined
+			}
+
+			eventType := argString(args, 0)
+			for _, l := range this.listeners {
+				if !l.removed && l.eventType == eventType && l.capture == capture && l.fn.Equal(fn) {
+					return memUndefined
+				}
+			}
+
+			this.listeners = append(this.listeners, &memListener{
+				eventType: eventType,
+				fn:        fn,
+				capture:   capture,
+				once:      once,
+			})
+			return memUndefined
+		},
+		"removeEventListener": func(this *memObject, args []Value) Value {
+			capture, _ := listenerOptions(argAt(args, 2))
+			eventType := argString(args, 0)
+			fn := argAt(args, 1)
+
+			kept := this.listeners[:0]
+			for _, l := range this.listeners {
+				if l.eventType == eventType && l.capture == capture && l.fn.Equal(fn) {
+					l.removed = true
+					continue
+				}
+				kept = append(kept, l)
+			}
+			this.listeners = kept
+			return memUndefined
+		},
+		"dispatchEvent": func(this *memObject, args []Value) Value {
+			event, ok := argAt(args, 0).(memValue)
+			if !ok || event.obj == nil {
+				panic("dom: dispatchEvent requires an event")
+			}
+			return memBool(dispatchMemEvent(this, event.obj))
+		},
+	},
+}
+
+// removeListener drops a listener from the object
+func (o *memObject) removeListener(listener *memListener) {
+	for i, l := range o.listeners {
+		if l == listener {
+			o.listeners = append(o.listeners[:i], o.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// listenerOptions reads the capture and once flags from addEventListener's third argument
+func listenerOptions(options Value) (capture, once bool) {
+	switch options.Type() {
+	case TypeBoolean:
+		return options.Bool(), false
+	case TypeObject:
+		return options.Get("capture").Truthy(), options.Get("once").Truthy()
+	}
+	return false, false
+}
+
+// eventPath returns the propagation path from target up to the window.
+// Composed events continue from a shadow root to its host
+func eventPath(target *memObject, composed bool) []*memObject {
+	path := []*memObject{target}
+
+	if target.node == nil {
+		return path
+	}
+
+	node := target.node
+	for {
+		if node.parent != nil {
+			node = node.parent
+		} else if composed && node.host != nil {
+			node = node.host
+		} else {
+			break
+		}
+		path = append(path, node.obj)
+	}
+
+	// Connected nodes propagate up to the window
+	if node.nodeType == documentNode && node.backend != nil {
+		path = append(path, node.backend.window)
+	}
+
+	return path
+}
+
+// dispatchMemEvent runs the capture, target and bubble phases for event and
+// reports whether the default action was not prevented
+func dispatchMemEvent(target *memObject, event *memObject) bool {
+	state, ok := event.data.(*memEventState)
+	if !ok {
+		state = &memEventState{}
+		event.data = state
+	}
+	state.stopped = false
+	state.stoppedImmediate = false
+
+	event.props["target"] = target.value()
+	path := eventPath(target, event.get("composed").Truthy())
+	bubbles := event.get("bubbles").Truthy()
+
+	invoke := func(obj *memObject, phase int) {
+		if state.stopped {
+			return
+		}
+
+		// Listeners outside a shadow tree see its host as the target
+		event.props["target"] = retarget(target, obj).value()
+		event.props["currentTarget"] = obj.value()
+		event.props["eventPhase"] = memNumber(float64(phase))
+		eventType := event.get("type").String()
+
+		listeners := append([]*memListener(nil), obj.listeners...)
+		for _, l := range listeners {
+			if l.removed || l.eventType != eventType {
+				continue
+			}
+			if phase == 1 && !l.capture || phase == 3 && l.capture {
+				continue
+			}
+			if l.once {
+				l.removed = true
+				obj.removeListener(l)
+			}
+
+			callMem(l.fn, obj.value(), []Value{event.value()})
+
+			if state.stoppedImmediate {
+				return
+			}
+		}
+
+		// on<type> handler properties
+		if phase != 1 {
+			if handler, ok := obj.props["on"+eventType]; ok && handler.Type() == TypeFunction {
+				callMem(handler, obj.value(), []Value{event.value()})
+			}
+		}
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		invoke(path[i], 1)
+	}
+	invoke(target, 2)
+	for _, obj := range path[1:] {
+		// Shadow hosts the event was retargeted to are reached even when it
+		// does not bubble
+		if bubbles || retarget(target, obj) == obj {
+			invoke(obj, 3)
+		}
+	}
+	event.props["target"] = target.value()
+
+	event.props["currentTarget"] = memNull
+	event.props["eventPhase"] = memNumber(0)
+
+	return !event.get("defaultPrevented").Truthy()
+}
+
+// eventClass implements Event and its subclasses
+var eventClass = &memClass{
+	name: "Event",
+	methods: map[string]memMethod{
+		"preventDefault": func(this *memObject, _ []Value) Value {
+			if this.get("cancelable").Truthy() {
+				this.props["defaultPrevented"] = memBool(true)
+			}
+			return memUndefined
+		},
+		"stopPropagation": func(this *memObject, _ []Value) Value {
+			if state, ok := this.data.(*memEventState); ok {
+				state.stopped = true
+			}
+			return memUndefined
+		},
+		"stopImmediatePropagation": func(this *memObject, _ []Value) Value {
+			if state, ok := this.data.(*memEventState); ok {
+				state.stopped = true
+				state.stoppedImmediate = true
+			}
+			return memUndefined
+		},
+	},
+}
+
+// memEventDefaults lists the event constructors and the default values of
+// the properties each one adds
+var memEventDefaults = map[string]map[string]interface{}{
+	"Event":       {},
+	"CustomEvent": {"detail": nil},
+	"FocusEvent":  {"relatedTarget": nil},
+	"InputEvent":  {"data": nil, "inputType": "", "isComposing": false},
+	"KeyboardEvent": {
+		"key": "", "code": "", "location": 0, "repeat": false, "isComposing": false,
+		"ctrlKey": false, "shiftKey": false, "altKey": false, "metaKey": false,
+	},
+	"MouseEvent":   mouseEventDefaults(nil),
+	"PointerEvent": mouseEventDefaults(map[string]interface{}{"pointerId": 1, "pointerType": "mouse", "isPrimary": true, "pressure": 0, "width": 1, "height": 1}),
+	"DragEvent":    mouseEventDefaults(map[string]interface{}{"dataTransfer": nil}),
+
+	"PopStateEvent":   {"state": nil},
+	"HashChangeEvent": {"oldURL": "", "newURL": ""},
+}
+
+// mouseEventDefaults returns the MouseEvent defaults merged with extra
+func mouseEventDefaults(extra map[string]interface{}) map[string]interface{} {
+	defaults := map[string]interface{}{
+		"clientX": 0, "clientY": 0, "pageX": 0, "pageY": 0, "screenX": 0, "screenY": 0,
+		"offsetX": 0, "offsetY": 0, "movementX": 0, "movementY": 0, "button": 0, "buttons": 0,
+		"ctrlKey": false, "shiftKey": false, "altKey": false, "metaKey": false, "relatedTarget": nil,
+	}
+	for key, value := range extra {
+		defaults[key] = value
+	}
+	return defaults
+}
+
+// newMemEvent creates an event from constructor arguments (type, init)
+func newMemEvent(args []Value, defaults map[string]interface{}) *memObject {
+	event := newMemObject(eventClass)
+	event.data = &memEventState{}
+
+	event.props["type"] = memString(argString(args, 0))
+	event.props["bubbles"] = memBool(false)
+	event.props["cancelable"] = memBool(false)
+	event.props["composed"] = memBool(false)
+	event.props["defaultPrevented"] = memBool(false)
+	event.props["isTrusted"] = memBool(false)
+	event.props["target"] = memNull
+	event.props["currentTarget"] = memNull
+	event.props["eventPhase"] = memNumber(0)
+	event.props["timeStamp"] = memNumber(0)
+
+	for key, value := range defaults {
+		event.props[key] = memValueOf(value)
+	}
+
+	if init, ok := argAt(args, 1).(memValue); ok && init.obj != nil {
+		keys := make([]string, 0, len(init.obj.props))
+		for key := range init.obj.props {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			event.props[key] = init.obj.props[key]
+		}
+	}
+
+	if _, ok := defaults["dataTransfer"]; ok && isNullish(event.props["dataTransfer"]) {
+		event.props["dataTransfer"] = newMemDataTransfer().value()
+	}
+
+	return event
+}
+
+// memDataTransfer holds the data of a drag operation
+type memDataTransfer struct {
+	formats []string
+	data    map[string]string
+}
+
+// dataTransferClass implements DataTransfer
+var dataTransferClass = &memClass{
+	name: "DataTransfer",
+	methods: map[string]memMethod{
+		"setData": func(this *memObject, args []Value) Value {
+			transfer := this.data.(*memDataTransfer)
+			format := argString(args, 0)
+			if _, ok := transfer.data[format]; !ok {
+				transfer.formats = append(transfer.formats, format)
+			}
+			transfer.data[format] = argString(args, 1)
+			return memUndefined
+		},
+		"getData": func(this *memObject, args []Value) Value {
+			return memString(this.data.(*memDataTransfer).data[argString(args, 0)])
+		},
+		"clearData": func(this *memObject, args []Value) Value {
+			transfer := this.data.(*memDataTransfer)
+			if len(args) == 0 {
+				this.data = &memDataTransfer{data: map[string]string{}}
+				return memUndefined
+			}
+			format := argString(args, 0)
+			delete(transfer.data, format)
+			for i, f := range transfer.formats {
+				if f == format {
+					transfer.formats = append(transfer.formats[:i], transfer.formats[i+1:]...)
+					break
+				}
+			}
+			return memUndefined
+		},
+		"setDragImage": func(_ *memObject, _ []Value) Value {
+			return memUndefined
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		if name == "types" {
+			return memValueOf(obj.data.(*memDataTransfer).formats), true
+		}
+		return nil, false
+	},
+}
+
+// newMemDataTransfer creates an empty DataTransfer
+func newMemDataTransfer() *memObject {
+	obj := newMemObject(dataTransferClass)
+	obj.data = &memDataTransfer{data: map[string]string{}}
+	obj.props["dropEffect"] = memString("none")
+	obj.props["effectAllowed"] = memString("all")
+	return obj
+}
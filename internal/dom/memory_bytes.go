@@ -0,0 +1,140 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ArrayBuffer and Uint8Array for the memory window, enough to move binary
+// data in and out with CopyBytesToGo and CopyBytesToJS. A Uint8Array over an
+// ArrayBuffer shares its bytes, as in the browser
+
+// memArrayBuffer is the state behind an ArrayBuffer object
+type memArrayBuffer struct {
+	bytes []byte
+}
+
+// memUint8Array is the state behind a Uint8Array object
+type memUint8Array struct {
+	buffer *memObject
+	offset int
+	bytes  []byte
+}
+
+// arrayBufferClass implements ArrayBuffer objects
+var arrayBufferClass = &memClass{
+	name: "ArrayBuffer",
+	get: func(obj *memObject, name string) (Value, bool) {
+		if name == "byteLength" {
+			return memNumber(float64(len(obj.data.(*memArrayBuffer).bytes))), true
+		}
+		return nil, false
+	},
+}
+
+// uint8ArrayClass implements Uint8Array objects
+var uint8ArrayClass = &memClass{
+	name: "Uint8Array",
+	get: func(obj *memObject, name string) (Value, bool) {
+		view := obj.data.(*memUint8Array)
+		switch name {
+		case "length", "byteLength":
+			return memNumber(float64(len(view.bytes))), true
+		case "byteOffset":
+			return memNumber(float64(view.offset)), true
+		case "buffer":
+			return view.buffer.value(), true
+		}
+		if i, err := strconv.Atoi(name); err == nil {
+			if i >= 0 && i < len(view.bytes) {
+				return memNumber(float64(view.bytes[i])), true
+			}
+			return memUndefined, true
+		}
+		return nil, false
+	},
+	set: func(obj *memObject, name string, value Value) bool {
+		i, err := strconv.Atoi(name)
+		if err != nil {
+			return false
+		}
+		// Writes past the end are dropped and numbers wrap modulo 256
+		if view := obj.data.(*memUint8Array); i >= 0 && i < len(view.bytes) {
+			view.bytes[i] = byte(int(argFloat([]Value{value}, 0)))
+		}
+		return true
+	},
+}
+
+// newMemArrayBuffer creates an ArrayBuffer holding bytes
+func newMemArrayBuffer(bytes []byte) *memObject {
+	obj := newMemObject(arrayBufferClass)
+	obj.data = &memArrayBuffer{bytes: bytes}
+	return obj
+}
+
+// newMemUint8Array implements new Uint8Array(length), new Uint8Array(buffer,
+// offset, length) and new Uint8Array(arrayLike)
+func newMemUint8Array(args []Value) Value {
+	source, _ := argAt(args, 0).(memValue)
+	if source.obj == nil {
+		return newMemView(newMemArrayBuffer(make([]byte, memLength(args, 0))), 0, -1)
+	}
+
+	if buffer, ok := source.obj.data.(*memArrayBuffer); ok {
+		offset, length := memLength(args, 1), -1
+		if !argAt(args, 2).IsUndefined() {
+			length = memLength(args, 2)
+		}
+		if offset > len(buffer.bytes) || (length >= 0 && offset+length > len(buffer.bytes)) {
+			panic("dom: RangeError: invalid Uint8Array length")
+		}
+		return newMemView(source.obj, offset, length)
+	}
+
+	// Anything else is copied element by element
+	bytes := make([]byte, source.Length())
+	for i := range bytes {
+		bytes[i] = byte(int(argFloat([]Value{source.Index(i)}, 0)))
+	}
+	return newMemView(newMemArrayBuffer(bytes), 0, -1)
+}
+
+// newMemView creates a Uint8Array over length bytes of buffer from offset,
+// or over the rest of it when length is negative
+func newMemView(buffer *memObject, offset, length int) Value {
+	bytes := buffer.data.(*memArrayBuffer).bytes[offset:]
+	if length >= 0 {
+		bytes = bytes[:length]
+	}
+
+	obj := newMemObject(uint8ArrayClass)
+	obj.data = &memUint8Array{buffer: buffer, offset: offset, bytes: bytes}
+	return obj.value()
+}
+
+// memLength reads a length or offset argument, zero when missing
+func memLength(args []Value, i int) int {
+	if argAt(args, i).Type() != TypeNumber {
+		return 0
+	}
+	n := argAt(args, i).Int()
+	if n < 0 {
+		panic("dom: RangeError: invalid array length")
+	}
+	return n
+}
+
+// memBytesOf returns the bytes viewed by a Uint8Array, panicking like
+// syscall/js does for any other value
+func memBytesOf(v Value, fn, arg string) []byte {
+	if value, ok := v.(memValue); ok && value.obj != nil {
+		if view, ok := value.obj.data.(*memUint8Array); ok {
+			return view.bytes
+		}
+	}
+	panic(fmt.Sprintf("dom: %s: expected %s to be a Uint8Array", fn, arg))
+}
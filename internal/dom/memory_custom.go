@@ -0,0 +1,315 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+// Custom elements and shadow roots for the memory document. Element classes
+// are made by DefineElement through elementClass; an element of a defined
+// name is upgraded when the document creates it or when it is connected, and
+// from then on its connected, disconnected and attribute changed callbacks
+// run synchronously as the tree changes. Events dispatched with composed set
+// cross shadow roots to their hosts and are retargeted on the way
+
+// memElementDefinition is the class behind a custom element name
+type memElementDefinition struct {
+	class    Value
+	observed []string
+	hooks    Value // created, connected, disconnected and attributeChanged
+}
+
+// memCustomElements is the state behind window.customElements
+type memCustomElements struct {
+	backend     *MemoryBackend
+	definitions map[string]*memElementDefinition
+	waiting     map[string][]*memPromise
+}
+
+// elementClass creates a class for DefineElement. It stands in for the
+// JavaScript class the browser needs, which the memory window cannot compile
+func (m *MemoryBackend) elementClass(observed []string, hooks Value) Value {
+	class := newMemFunction(func(_ Value, _ []Value) Value {
+		panic("dom: custom element classes must be used through customElements.define")
+	})
+	class.data = &memElementDefinition{observed: observed, hooks: hooks}
+	class.props["observedAttributes"] = memValueOf(observed)
+	return class.value()
+}
+
+// customElementsClass implements window.customElements
+var customElementsClass = &memClass{
+	name: "CustomElementRegistry",
+	methods: map[string]memMethod{
+		"define": func(this *memObject, args []Value) Value {
+			registry := this.data.(*memCustomElements)
+			name := argString(args, 0)
+			if !validElementName(name) {
+				panic("dom: " + name + " is not a valid custom element name")
+			}
+			if registry.definitions[name] != nil {
+				panic("dom: the name " + name + " has already been used with this registry")
+			}
+
+			class, ok := argAt(args, 1).(memValue)
+			if !ok || class.obj == nil {
+				panic("dom: customElements.define requires a class")
+			}
+			definition, ok := class.obj.data.(*memElementDefinition)
+			if !ok {
+				panic("dom: the memory window only defines classes made by DefineElement")
+			}
+			definition.class = class
+			registry.definitions[name] = definition
+
+			// Upgrade the elements already in the document
+			document := registry.backend.document.node
+			for _, element := range document.shadowIncludingElements() {
+				if element.tag == name && element.definition == nil {
+					element.upgrade(definition)
+					element.definition.call("connected", element)
+				}
+			}
+
+			waiting := registry.waiting[name]
+			delete(registry.waiting, name)
+			for _, promise := range waiting {
+				promise.resolve(class)
+			}
+			return memUndefined
+		},
+		"get": func(this *memObject, args []Value) Value {
+			if definition := this.data.(*memCustomElements).definitions[argString(args, 0)]; definition != nil {
+				return definition.class
+			}
+			return memUndefined
+		},
+		"whenDefined": func(this *memObject, args []Value) Value {
+			registry := this.data.(*memCustomElements)
+			name := argString(args, 0)
+			if definition := registry.definitions[name]; definition != nil {
+				return resolvedMemPromise(definition.class)
+			}
+
+			obj, promise := newMemPromise()
+			registry.waiting[name] = append(registry.waiting[name], promise)
+			return obj.value()
+		},
+	},
+}
+
+// newMemCustomElements creates the custom element registry of the window
+func (m *MemoryBackend) newMemCustomElements() *memObject {
+	m.customElements = &memCustomElements{
+		backend:     m,
+		definitions: map[string]*memElementDefinition{},
+		waiting:     map[string][]*memPromise{},
+	}
+
+	registry := newMemObject(customElementsClass)
+	registry.data = m.customElements
+	return registry
+}
+
+// call runs one of the definition's callbacks for element
+func (d *memElementDefinition) call(hook string, element *memNode, args ...Value) {
+	callMem(d.hooks.Get(hook), memUndefined, append([]Value{element.obj.value()}, args...))
+}
+
+// upgrade makes element an instance of definition. Observed attributes the
+// element already has are reported as changed from null
+func (n *memNode) upgrade(definition *memElementDefinition) {
+	n.definition = definition
+	definition.call("created", n)
+
+	for _, name := range definition.observed {
+		if value, ok := n.getAttr(name); ok {
+			definition.call("attributeChanged", n, memString(name), memNull, memString(value))
+		}
+	}
+}
+
+// connectedCallbacks upgrades and notifies the custom elements in a subtree
+// that was just connected to document
+func (n *memNode) connectedCallbacks(document *memNode) {
+	definitions := document.backend.customElements.definitions
+	if len(definitions) == 0 {
+		return
+	}
+
+	for _, element := range n.shadowIncludingElements() {
+		if element.definition == nil {
+			if definition := definitions[element.tag]; definition != nil {
+				element.upgrade(definition)
+			}
+		}
+		if element.definition != nil {
+			element.definition.call("connected", element)
+		}
+	}
+}
+
+// disconnectedCallbacks notifies the custom elements in a subtree that was
+// just disconnected
+func (n *memNode) disconnectedCallbacks() {
+	for _, element := range n.shadowIncludingElements() {
+		if element.definition != nil {
+			element.definition.call("disconnected", element)
+		}
+	}
+}
+
+// attributeChangedCallback notifies a custom element of a change to one of
+// its observed attributes
+func (n *memNode) attributeChangedCallback(name, old string, had bool) {
+	if n.definition == nil {
+		return
+	}
+
+	observed := false
+	for _, attr := range n.definition.observed {
+		if attr == name {
+			observed = true
+			break
+		}
+	}
+	if !observed {
+		return
+	}
+
+	oldValue, newValue := Value(memNull), Value(memNull)
+	if had {
+		oldValue = memString(old)
+	}
+	if value, ok := n.getAttr(name); ok {
+		newValue = memString(value)
+	}
+	n.definition.call("attributeChanged", n, memString(name), oldValue, newValue)
+}
+
+// shadowIncludingElements returns the node, when it is an element, and every
+// element below it, descending into shadow trees, in tree order
+func (n *memNode) shadowIncludingElements() []*memNode {
+	var elements []*memNode
+	var walk func(node *memNode)
+	walk = func(node *memNode) {
+		if node.nodeType == elementNode {
+			elements = append(elements, node)
+		}
+		if node.shadow != nil {
+			walk(node.shadow)
+		}
+		for _, child := range node.children {
+			if child.nodeType == elementNode {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return elements
+}
+
+// shadowRootClass implements shadow roots
+var shadowRootClass = &memClass{
+	name: "ShadowRoot",
+	get: func(obj *memObject, name string) (Value, bool) {
+		root := obj.node
+		switch name {
+		case "nodeName":
+			return memString("#document-fragment"), true
+		case "host":
+			return root.host.obj.value(), true
+		case "mode":
+			mode, _ := obj.props["__mode"].(memValue)
+			return mode, true
+		case "innerHTML":
+			return memString(serializeChildren(root)), true
+		case "children":
+			var items []Value
+			for _, child := range root.elementChildren() {
+				items = append(items, child.obj.value())
+			}
+			return newMemArray(items).value(), true
+		case "activeElement":
+			if document := root.ownerDocument(); document != nil && document.active != nil && root.contains(document.active) {
+				return document.active.obj.value(), true
+			}
+			return memNull, true
+		}
+		return nil, false
+	},
+}
+
+// attachShadow gives element a shadow root with the given mode
+func (n *memNode) attachShadow(mode string) *memNode {
+	if n.shadow != nil {
+		panic("dom: the element already has a shadow root")
+	}
+
+	root := newMemNode(shadowRootClass, fragmentNode, "")
+	root.host = n
+	root.obj.props["__mode"] = memString(mode)
+	n.shadow = root
+	return root
+}
+
+// retarget returns the target seen by listeners on obj: the innermost
+// shadow host around target whose shadow tree does not hold obj
+func retarget(target, obj *memObject) *memObject {
+	node := target.node
+	if node == nil {
+		return target
+	}
+
+	for {
+		root := node
+		for root.parent != nil {
+			root = root.parent
+		}
+		if root.host == nil || obj.node != nil && root.shadowIncludes(obj.node) {
+			return node.obj
+		}
+		node = root.host
+	}
+}
+
+// shadowIncludes reports whether other is the node or below it, counting
+// shadow trees as below their hosts
+func (n *memNode) shadowIncludes(other *memNode) bool {
+	for node := other; node != nil; {
+		if node == n {
+			return true
+		}
+		if node.parent != nil {
+			node = node.parent
+		} else {
+			node = node.host
+		}
+	}
+	return false
+}
+
+func init() {
+	// Set here to break the initialization cycle through elementClass
+	shadowRootClass.parent = nodeClass
+	shadowRootClass.set = func(obj *memObject, name string, value Value) bool {
+		if name == "innerHTML" {
+			root := obj.node
+			root.removeChildren()
+			for _, child := range parseHTML(jsString(value)) {
+				root.appendChild(child)
+			}
+			return true
+		}
+		return false
+	}
+	shadowRootClass.methods = mergeMethods(queryMethods, map[string]memMethod{
+		"getElementById": func(this *memObject, args []Value) Value {
+			id := argString(args, 0)
+			for _, element := range this.node.descendants() {
+				if value, ok := element.getAttr("id"); ok && value == id {
+					return element.obj.value()
+				}
+			}
+			return memNull
+		},
+	})
+}
@@ -0,0 +1,1711 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node types, as reported by nodeType
+const (
+	elementNode  = 1
+	textNode     = 3
+	commentNode  = 8
+	documentNode = 9
+	fragmentNode = 11
+)
+
+// memNode is a node in the memory document tree
+type memNode struct {
+	obj      *memObject
+	nodeType int
+	tag      string // lower case tag name for elements
+	text     string // data of text and comment nodes
+	attrs    []memAttr
+	styles   []memAttr
+	parent   *memNode
+	children []*memNode
+
+	// Form state
+	value    *string
+	checked  *bool
+	selected *bool
+
+	// Document state
+	backend *MemoryBackend
+	active  *memNode
+
+	// Layout used by getBoundingClientRect and scrolling
+	rect       memRect
+	scrollTop  float64
+	scrollLeft float64
+
+	// Mutation observers watching the node
+	registrations []*memRegistration
+
+	// Shadow DOM and custom element state
+	host       *memNode              // element a shadow root is attached to
+	shadow     *memNode              // shadow root attached to an element
+	definition *memElementDefinition // custom element definition, once upgraded
+
+	// Animations started with animate, including finished ones
+	animations []*memObject
+}
+
+// memRect is the layout box of a node
+type memRect struct {
+	left, top, width, height float64
+}
+
+// memAttr is a name/value pair in document order
+type memAttr struct {
+	name  string
+	value string
+}
+
+// newMemNode creates a node of the given type wrapped in its object
+func newMemNode(class *memClass, nodeType int, tag string) *memNode {
+	node := &memNode{nodeType: nodeType, tag: tag}
+	node.obj = newMemObject(class)
+	node.obj.node = node
+	return node
+}
+
+// newDocument builds an empty HTML document
+func (m *MemoryBackend) newDocument() *memObject {
+	document := newMemNode(documentClass, documentNode, "")
+	document.backend = m
+
+	html := newMemNode(elementClass, elementNode, "html")
+	head := newMemNode(elementClass, elementNode, "head")
+	body := newMemNode(elementClass, elementNode, "body")
+
+	document.appendChild(html)
+	html.appendChild(head)
+	html.appendChild(body)
+
+	return document.obj
+}
+
+// nodeOf returns the node behind a value, or nil
+func nodeOf(value Value) *memNode {
+	if v, ok := memValueOf(value).(memValue); ok && v.obj != nil {
+		return v.obj.node
+	}
+	return nil
+}
+
+// nodeValue returns the node as a Value, or null for a nil node
+func nodeValue(node *memNode) Value {
+	if node == nil {
+		return memNull
+	}
+	return node.obj.value()
+}
+
+// ownerDocument returns the document the node belongs to, if it is connected.
+// Nodes in a shadow tree belong to the document of the host
+func (n *memNode) ownerDocument() *memNode {
+	root := n
+	for root.parent != nil || root.host != nil {
+		if root.parent != nil {
+			root = root.parent
+		} else {
+			root = root.host
+		}
+	}
+	if root.nodeType == documentNode {
+		return root
+	}
+	return nil
+}
+
+// documentElement returns the root html element of a document node
+func (n *memNode) documentElement() *memNode {
+	for _, child := range n.children {
+		if child.nodeType == elementNode {
+			return child
+		}
+	}
+	return nil
+}
+
+// body returns the body element of a document node
+func (n *memNode) body() *memNode {
+	if html := n.documentElement(); html != nil {
+		for _, child := range html.children {
+			if child.tag == "body" {
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+// index returns the position of the node among its siblings
+func (n *memNode) index() int {
+	if n.parent == nil {
+		return -1
+	}
+	for i, child := range n.parent.children {
+		if child == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// contains reports whether other is n or one of its descendants
+func (n *memNode) contains(other *memNode) bool {
+	for node := other; node != nil; node = node.parent {
+		if node == n {
+			return true
+		}
+	}
+	return false
+}
+
+// detach removes the node from its parent
+func (n *memNode) detach() {
+	if n.parent == nil {
+		return
+	}
+
+	// Removing the focused element moves focus back to the body
+	document := n.ownerDocument()
+	if document != nil && document.active != nil && n.contains(document.active) {
+		document.active = nil
+	}
+
+	parent := n.parent
+	i := n.index()
+	change := memMutation{kind: "childList", removed: []*memNode{n}}
+	if i > 0 {
+		change.previous = parent.children[i-1]
+	}
+	if i+1 < len(parent.children) {
+		change.next = parent.children[i+1]
+	}
+
+	parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	n.parent = nil
+	queueMutation(parent, change)
+
+	if document != nil {
+		n.disconnectedCallbacks()
+	}
+}
+
+// insertBefore inserts child before ref, or at the end when ref is nil
+func (n *memNode) insertBefore(child, ref *memNode) {
+	if child == ref {
+		return
+	}
+	if child.contains(n) {
+		panic("dom: the new child is an ancestor of the parent")
+	}
+
+	child.detach()
+	child.parent = n
+
+	i := len(n.children)
+	if ref != nil && ref.parent == n {
+		i = ref.index()
+	}
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+
+	change := memMutation{kind: "childList", added: []*memNode{child}}
+	if i > 0 {
+		change.previous = n.children[i-1]
+	}
+	if i+1 < len(n.children) {
+		change.next = n.children[i+1]
+	}
+	queueMutation(n, change)
+
+	if document := n.ownerDocument(); document != nil {
+		child.connectedCallbacks(document)
+	}
+}
+
+// appendChild appends child to the node
+func (n *memNode) appendChild(child *memNode) {
+	n.insertBefore(child, nil)
+}
+
+// elementChildren returns the element children of the node
+func (n *memNode) elementChildren() []*memNode {
+	var elements []*memNode
+	for _, child := range n.children {
+		if child.nodeType == elementNode {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// descendants returns every element below the node in document order
+func (n *memNode) descendants() []*memNode {
+	var elements []*memNode
+	var walk func(node *memNode)
+	walk = func(node *memNode) {
+		for _, child := range node.children {
+			if child.nodeType == elementNode {
+				elements = append(elements, child)
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return elements
+}
+
+// textContent concatenates the text of the node and its descendants
+func (n *memNode) textContent() string {
+	if n.nodeType == textNode || n.nodeType == commentNode {
+		return n.text
+	}
+
+	var sb strings.Builder
+	var walk func(node *memNode)
+	walk = func(node *memNode) {
+		for _, child := range node.children {
+			if child.nodeType == textNode {
+				sb.WriteString(child.text)
+			} else if child.nodeType == elementNode {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// setTextContent replaces the children of the node with a single text node
+func (n *memNode) setTextContent(text string) {
+	if n.nodeType == textNode || n.nodeType == commentNode {
+		n.setData(text)
+		return
+	}
+
+	n.removeChildren()
+	if text != "" {
+		n.appendChild(newTextNode(text))
+	}
+}
+
+// setData changes the text of a text or comment node
+func (n *memNode) setData(text string) {
+	old := n.text
+	n.text = text
+	queueMutation(n, memMutation{kind: "characterData", oldValue: old})
+}
+
+// removeChildren detaches every child of the node
+func (n *memNode) removeChildren() {
+	for len(n.children) > 0 {
+		n.children[0].detach()
+	}
+}
+
+// newTextNode creates a text node
+func newTextNode(text string) *memNode {
+	node := newMemNode(textClass, textNode, "")
+	node.text = text
+	return node
+}
+
+// newElement creates an element with the given tag
+func newElement(tag string) *memNode {
+	return newMemNode(elementClass, elementNode, strings.ToLower(tag))
+}
+
+// getAttr returns an attribute value and whether it is present
+func (n *memNode) getAttr(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if name == "style" {
+		if len(n.styles) == 0 {
+			for _, attr := range n.attrs {
+				if attr.name == "style" {
+					return "", true
+				}
+			}
+			return "", false
+		}
+		return serializeStyles(n.styles), true
+	}
+
+	for _, attr := range n.attrs {
+		if attr.name == name {
+			return attr.value, true
+		}
+	}
+	return "", false
+}
+
+// setAttr sets an attribute, keeping the original order
+func (n *memNode) setAttr(name, value string) {
+	name = strings.ToLower(name)
+	old, had := n.getAttr(name)
+	defer n.attributeChangedCallback(name, old, had)
+	defer queueMutation(n, memMutation{kind: "attributes", attributeName: name, oldValue: old})
+
+	if name == "style" {
+		n.styles = parseStyles(value)
+	}
+
+	for i, attr := range n.attrs {
+		if attr.name == name {
+			n.attrs[i].value = value
+			return
+		}
+	}
+	n.attrs = append(n.attrs, memAttr{name, value})
+}
+
+// removeAttr removes an attribute
+func (n *memNode) removeAttr(name string) {
+	name = strings.ToLower(name)
+	if old, ok := n.getAttr(name); ok {
+		defer n.attributeChangedCallback(name, old, true)
+		defer queueMutation(n, memMutation{kind: "attributes", attributeName: name, oldValue: old})
+	}
+
+	if name == "style" {
+		n.styles = nil
+	}
+
+	for i, attr := range n.attrs {
+		if attr.name == name {
+			n.attrs = append(n.attrs[:i], n.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// classes returns the class names of the element
+func (n *memNode) classes() []string {
+	value, _ := n.getAttr("class")
+	return strings.Fields(value)
+}
+
+// hasClass reports whether the element has the class
+func (n *memNode) hasClass(name string) bool {
+	for _, class := range n.classes() {
+		if class == name {
+			return true
+		}
+	}
+	return false
+}
+
+// setClasses writes the class attribute
+func (n *memNode) setClasses(classes []string) {
+	n.setAttr("class", strings.Join(classes, " "))
+}
+
+// addClasses adds class names that are not present yet
+func (n *memNode) addClasses(names ...string) {
+	classes := n.classes()
+	for _, name := range names {
+		if !contains(classes, name) {
+			classes = append(classes, name)
+		}
+	}
+	n.setClasses(classes)
+}
+
+// removeClasses removes class names, leaving a missing class attribute alone
+func (n *memNode) removeClasses(names ...string) {
+	if _, ok := n.getAttr("class"); !ok {
+		return
+	}
+	var kept []string
+	for _, class := range n.classes() {
+		if !contains(names, class) {
+			kept = append(kept, class)
+		}
+	}
+	n.setClasses(kept)
+}
+
+// stringArgs converts every argument to a string
+func stringArgs(args []Value) []string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = jsString(arg)
+	}
+	return strs
+}
+
+// getStyle returns a style property by its CSS name
+func (n *memNode) getStyle(name string) string {
+	name = cssPropertyName(name)
+	for _, style := range n.styles {
+		if style.name == name {
+			return style.value
+		}
+	}
+	return ""
+}
+
+// setStyle sets or, for an empty value, removes a style property
+func (n *memNode) setStyle(name, value string) {
+	name = cssPropertyName(name)
+	for i, style := range n.styles {
+		if style.name == name {
+			if value == "" {
+				n.styles = append(n.styles[:i], n.styles[i+1:]...)
+			} else {
+				n.styles[i].value = value
+			}
+			n.syncStyleAttr()
+			return
+		}
+	}
+
+	if value != "" {
+		n.styles = append(n.styles, memAttr{name, value})
+	}
+	n.syncStyleAttr()
+}
+
+// syncStyleAttr keeps the style attribute in step with the style properties
+func (n *memNode) syncStyleAttr() {
+	for i, attr := range n.attrs {
+		if attr.name == "style" {
+			n.attrs[i].value = serializeStyles(n.styles)
+			queueMutation(n, memMutation{kind: "attributes", attributeName: "style", oldValue: attr.value})
+			return
+		}
+	}
+	if len(n.styles) > 0 {
+		n.attrs = append(n.attrs, memAttr{"style", serializeStyles(n.styles)})
+		queueMutation(n, memMutation{kind: "attributes", attributeName: "style"})
+	}
+}
+
+// cssPropertyName converts a camelCase property such as backgroundColor to
+// its CSS form background-color. Custom properties are kept as they are
+func cssPropertyName(name string) string {
+	if strings.HasPrefix(name, "--") {
+		return name
+	}
+
+	var sb strings.Builder
+	for _, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			sb.WriteByte('-')
+			sb.WriteRune(r + ('a' - 'A'))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// parseStyles parses a declaration list such as "display: none; color: red"
+func parseStyles(text string) []memAttr {
+	var styles []memAttr
+	for _, declaration := range strings.Split(text, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if name != "" && value != "" {
+			styles = append(styles, memAttr{cssPropertyName(name), value})
+		}
+	}
+	return styles
+}
+
+// serializeStyles formats style properties as a declaration list
+func serializeStyles(styles []memAttr) string {
+	parts := make([]string, len(styles))
+	for i, style := range styles {
+		parts[i] = style.name + ": " + style.value + ";"
+	}
+	return strings.Join(parts, " ")
+}
+
+// formValue returns the value property of an input, textarea, select or option
+func (n *memNode) formValue() string {
+	switch n.tag {
+	case "select":
+		if option := n.selectedOption(); option != nil {
+			return option.formValue()
+		}
+		return ""
+	case "option":
+		if value, ok := n.getAttr("value"); ok {
+			return value
+		}
+		return strings.TrimSpace(n.textContent())
+	case "textarea":
+		if n.value != nil {
+			return *n.value
+		}
+		return n.textContent()
+	}
+
+	if n.value != nil {
+		return *n.value
+	}
+	if value, ok := n.getAttr("value"); ok {
+		return value
+	}
+	if inputType, _ := n.getAttr("type"); inputType == "checkbox" || inputType == "radio" {
+		return "on"
+	}
+	return ""
+}
+
+// setFormValue sets the value property
+func (n *memNode) setFormValue(value string) {
+	if n.tag == "select" {
+		for _, option := range n.options() {
+			selected := option.formValue() == value
+			option.selected = &selected
+		}
+		return
+	}
+	n.value = &value
+}
+
+// options returns the option elements of a select
+func (n *memNode) options() []*memNode {
+	var options []*memNode
+	for _, element := range n.descendants() {
+		if element.tag == "option" {
+			options = append(options, element)
+		}
+	}
+	return options
+}
+
+// selectedOption returns the selected option of a select
+func (n *memNode) selectedOption() *memNode {
+	options := n.options()
+
+	for _, option := range options {
+		if option.selected != nil {
+			if *option.selected {
+				return option
+			}
+			continue
+		}
+		if _, ok := option.getAttr("selected"); ok {
+			return option
+		}
+	}
+
+	// Without an explicit choice the first option is selected, unless an
+	// assignment deselected everything
+	for _, option := range options {
+		if option.selected != nil {
+			return nil
+		}
+	}
+	if len(options) > 0 {
+		return options[0]
+	}
+	return nil
+}
+
+// isChecked returns the checked property of a checkbox or radio button
+func (n *memNode) isChecked() bool {
+	if n.checked != nil {
+		return *n.checked
+	}
+	_, ok := n.getAttr("checked")
+	return ok
+}
+
+// setChecked sets the checked property, unchecking other radios in the group
+func (n *memNode) setChecked(checked bool) {
+	n.checked = &checked
+
+	if inputType, _ := n.getAttr("type"); checked && inputType == "radio" {
+		name, _ := n.getAttr("name")
+		root := n
+		for root.parent != nil {
+			root = root.parent
+		}
+		for _, other := range root.descendants() {
+			otherName, _ := other.getAttr("name")
+			otherType, _ := other.getAttr("type")
+			if other != n && other.tag == "input" && otherType == "radio" && otherName == name {
+				unchecked := false
+				other.checked = &unchecked
+			}
+		}
+	}
+}
+
+// focus moves focus to the node, dispatching blur/focusout and focus/focusin
+func (n *memNode) focus() {
+	document := n.ownerDocument()
+	if document == nil || document.active == n {
+		return
+	}
+
+	previous := document.active
+	document.active = n
+
+	if previous != nil {
+		dispatchMemEvent(previous.obj, newFocusEvent("blur", false, n))
+		dispatchMemEvent(previous.obj, newFocusEvent("focusout", true, n))
+	}
+	dispatchMemEvent(n.obj, newFocusEvent("focus", false, previous))
+	dispatchMemEvent(n.obj, newFocusEvent("focusin", true, previous))
+}
+
+// blur removes focus from the node if it has it
+func (n *memNode) blur() {
+	document := n.ownerDocument()
+	if document == nil || document.active != n {
+		return
+	}
+
+	document.active = nil
+	dispatchMemEvent(n.obj, newFocusEvent("blur", false, nil))
+	dispatchMemEvent(n.obj, newFocusEvent("focusout", true, nil))
+}
+
+// newFocusEvent creates a focus event
+func newFocusEvent(eventType string, bubbles bool, related *memNode) *memObject {
+	event := newMemEvent([]Value{memString(eventType)}, memEventDefaults["FocusEvent"])
+	event.props["bubbles"] = memBool(bubbles)
+	event.props["relatedTarget"] = nodeValue(related)
+	event.props["isTrusted"] = memBool(true)
+	return event
+}
+
+// click simulates a user click, toggling checkboxes and radios
+func (n *memNode) click() {
+	inputType, _ := n.getAttr("type")
+	toggles := n.tag == "input" && (inputType == "checkbox" || inputType == "radio")
+	before := n.isChecked()
+
+	if toggles {
+		if inputType == "checkbox" {
+			n.setChecked(!before)
+		} else {
+			n.setChecked(true)
+		}
+	}
+
+	event := newMemEvent([]Value{memString("click")}, memEventDefaults["MouseEvent"])
+	event.props["bubbles"] = memBool(true)
+	event.props["cancelable"] = memBool(true)
+	event.props["isTrusted"] = memBool(true)
+	notPrevented := dispatchMemEvent(n.obj, event)
+
+	if toggles {
+		if !notPrevented {
+			n.setChecked(before)
+		} else if n.isChecked() != before {
+			for _, eventType := range []string{"input", "change"} {
+				changed := newMemEvent([]Value{memString(eventType)}, nil)
+				changed.props["bubbles"] = memBool(true)
+				changed.props["isTrusted"] = memBool(true)
+				dispatchMemEvent(n.obj, changed)
+			}
+		}
+	}
+}
+
+// cloneNode copies the node and, if deep, its descendants
+func (n *memNode) cloneNode(deep bool) *memNode {
+	clone := newMemNode(n.obj.class, n.nodeType, n.tag)
+	clone.text = n.text
+	clone.attrs = append([]memAttr(nil), n.attrs...)
+	clone.styles = append([]memAttr(nil), n.styles...)
+	clone.rect = n.rect
+
+	if deep {
+		for _, child := range n.children {
+			clone.appendChild(child.cloneNode(true))
+		}
+	}
+	return clone
+}
+
+// nodeClass implements the Node interface shared by every node
+var nodeClass = &memClass{
+	name:   "Node",
+	parent: eventTargetClass,
+	methods: map[string]memMethod{
+		"appendChild": func(this *memObject, args []Value) Value {
+			child := nodeOf(argAt(args, 0))
+			if child == nil {
+				panic("dom: appendChild requires a node")
+			}
+			this.node.appendChild(child)
+			return child.obj.value()
+		},
+		"insertBefore": func(this *memObject, args []Value) Value {
+			child := nodeOf(argAt(args, 0))
+			if child == nil {
+				panic("dom: insertBefore requires a node")
+			}
+			ref := nodeOf(argAt(args, 1))
+			if ref != nil && ref.parent != this.node {
+				panic("dom: the reference node is not a child of this node")
+			}
+			this.node.insertBefore(child, ref)
+			return child.obj.value()
+		},
+		"removeChild": func(this *memObject, args []Value) Value {
+			child := nodeOf(argAt(args, 0))
+			if child == nil || child.parent != this.node {
+				panic("dom: the node to be removed is not a child of this node")
+			}
+			child.detach()
+			return child.obj.value()
+		},
+		"replaceChild": func(this *memObject, args []Value) Value {
+			replacement := nodeOf(argAt(args, 0))
+			old := nodeOf(argAt(args, 1))
+			if replacement == nil || old == nil || old.parent != this.node {
+				panic("dom: the node to be replaced is not a child of this node")
+			}
+			this.node.insertBefore(replacement, old)
+			old.detach()
+			return old.obj.value()
+		},
+		"remove": func(this *memObject, _ []Value) Value {
+			this.node.detach()
+			return memUndefined
+		},
+		"contains": func(this *memObject, args []Value) Value {
+			other := nodeOf(argAt(args, 0))
+			return memBool(other != nil && this.node.contains(other))
+		},
+		"getRootNode": func(this *memObject, _ []Value) Value {
+			root := this.node
+			for root.parent != nil {
+				root = root.parent
+			}
+			return root.obj.value()
+		},
+		"hasChildNodes": func(this *memObject, _ []Value) Value {
+			return memBool(len(this.node.children) > 0)
+		},
+		"cloneNode": func(this *memObject, args []Value) Value {
+			return this.node.cloneNode(argAt(args, 0).Truthy()).obj.value()
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		node := obj.node
+		switch name {
+		case "nodeType":
+			return memNumber(float64(node.nodeType)), true
+		case "parentNode":
+			return nodeValue(node.parent), true
+		case "parentElement":
+			if node.parent != nil && node.parent.nodeType == elementNode {
+				return node.parent.obj.value(), true
+			}
+			return memNull, true
+		case "childNodes":
+			items := make([]Value, len(node.children))
+			for i, child := range node.children {
+				items[i] = child.obj.value()
+			}
+			return newMemArray(items).value(), true
+		case "firstChild":
+			if len(node.children) == 0 {
+				return memNull, true
+			}
+			return node.children[0].obj.value(), true
+		case "lastChild":
+			if len(node.children) == 0 {
+				return memNull, true
+			}
+			return node.children[len(node.children)-1].obj.value(), true
+		case "nextSibling", "previousSibling":
+			i := node.index()
+			if i < 0 {
+				return memNull, true
+			}
+			if name == "nextSibling" {
+				i++
+			} else {
+				i--
+			}
+			if i < 0 || i >= len(node.parent.children) {
+				return memNull, true
+			}
+			return node.parent.children[i].obj.value(), true
+		case "isConnected":
+			return memBool(node.ownerDocument() != nil), true
+		case "ownerDocument":
+			if node.nodeType == documentNode {
+				return memNull, true
+			}
+			return nodeValue(node.ownerDocument()), true
+		case "textContent":
+			if node.nodeType == documentNode {
+				return memNull, true
+			}
+			return memString(node.textContent()), true
+		}
+		return nil, false
+	},
+	set: func(obj *memObject, name string, value Value) bool {
+		if name == "textContent" {
+			obj.node.setTextContent(jsString(value))
+			return true
+		}
+		return false
+	},
+}
+
+// textClass implements text nodes
+var textClass = &memClass{
+	name: "Text",
+	get: func(obj *memObject, name string) (Value, bool) {
+		switch name {
+		case "nodeName":
+			return memString("#text"), true
+		case "nodeValue", "data", "wholeText":
+			return memString(obj.node.text), true
+		case "length":
+			return memNumber(float64(len([]rune(obj.node.text)))), true
+		}
+		return nil, false
+	},
+	set: func(obj *memObject, name string, value Value) bool {
+		if name == "nodeValue" || name == "data" {
+			obj.node.setData(jsString(value))
+			return true
+		}
+		return false
+	},
+}
+
+// queryMethods are shared by documents and elements
+var queryMethods = map[string]memMethod{
+	"querySelector": func(this *memObject, args []Value) Value {
+		selector := mustParseSelector(argString(args, 0))
+		for _, element := range this.node.descendants() {
+			if selector.matches(element) {
+				return element.obj.value()
+			}
+		}
+		return memNull
+	},
+	"querySelectorAll": func(this *memObject, args []Value) Value {
+		selector := mustParseSelector(argString(args, 0))
+		var items []Value
+		for _, element := range this.node.descendants() {
+			if selector.matches(element) {
+				items = append(items, element.obj.value())
+			}
+		}
+		return newMemArray(items).value()
+	},
+	"getElementsByTagName": func(this *memObject, args []Value) Value {
+		tag := strings.ToLower(argString(args, 0))
+		var items []Value
+		for _, element := range this.node.descendants() {
+			if tag == "*" || element.tag == tag {
+				items = append(items, element.obj.value())
+			}
+		}
+		return newMemArray(items).value()
+	},
+	"getElementsByClassName": func(this *memObject, args []Value) Value {
+		names := strings.Fields(argString(args, 0))
+		var items []Value
+		for _, element := range this.node.descendants() {
+			matches := len(names) > 0
+			for _, name := range names {
+				if !element.hasClass(name) {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				items = append(items, element.obj.value())
+			}
+		}
+		return newMemArray(items).value()
+	},
+}
+
+// documentClass implements the document
+var documentClass = &memClass{
+	name:   "Document",
+	parent: nodeClass,
+	methods: mergeMethods(queryMethods, map[string]memMethod{
+		"createElement": func(this *memObject, args []Value) Value {
+			element := newElement(argString(args, 0))
+			if definition := this.node.backend.customElements.definitions[element.tag]; definition != nil {
+				element.upgrade(definition)
+			}
+			return element.obj.value()
+		},
+		"createTextNode": func(_ *memObject, args []Value) Value {
+			return newTextNode(argString(args, 0)).obj.value()
+		},
+		"createComment": func(_ *memObject, args []Value) Value {
+			node := newMemNode(textClass, commentNode, "")
+			node.text = argString(args, 0)
+			return node.obj.value()
+		},
+		"getElementById": func(this *memObject, args []Value) Value {
+			id := argString(args, 0)
+			for _, element := range this.node.descendants() {
+				if value, ok := element.getAttr("id"); ok && value == id {
+					return element.obj.value()
+				}
+			}
+			return memNull
+		},
+		"elementFromPoint": func(this *memObject, args []Value) Value {
+			return nodeValue(this.node.elementFromPoint(argFloat(args, 0), argFloat(args, 1)))
+		},
+	}),
+	get: func(obj *memObject, name string) (Value, bool) {
+		document := obj.node
+		switch name {
+		case "nodeName":
+			return memString("#document"), true
+		case "documentElement":
+			return nodeValue(document.documentElement()), true
+		case "body":
+			return nodeValue(document.body()), true
+		case "head":
+			if html := document.documentElement(); html != nil {
+				for _, child := range html.children {
+					if child.tag == "head" {
+						return child.obj.value(), true
+					}
+				}
+			}
+			return memNull, true
+		case "activeElement":
+			if document.active != nil {
+				return document.active.obj.value(), true
+			}
+			return nodeValue(document.body()), true
+		case "readyState":
+			return memString("complete"), true
+		}
+		return nil, false
+	},
+}
+
+// mergeMethods combines method tables
+func mergeMethods(tables ...map[string]memMethod) map[string]memMethod {
+	merged := map[string]memMethod{}
+	for _, table := range tables {
+		for name, method := range table {
+			merged[name] = method
+		}
+	}
+	return merged
+}
+
+// elementClass implements HTML elements
+var elementClass = &memClass{
+	name:   "Element",
+	parent: nodeClass,
+	methods: mergeMethods(queryMethods, map[string]memMethod{
+		"getAttribute": func(this *memObject, args []Value) Value {
+			if value, ok := this.node.getAttr(argString(args, 0)); ok {
+				return memString(value)
+			}
+			return memNull
+		},
+		"setAttribute": func(this *memObject, args []Value) Value {
+			this.node.setAttr(argString(args, 0), argString(args, 1))
+			return memUndefined
+		},
+		"hasAttribute": func(this *memObject, args []Value) Value {
+			_, ok := this.node.getAttr(argString(args, 0))
+			return memBool(ok)
+		},
+		"removeAttribute": func(this *memObject, args []Value) Value {
+			this.node.removeAttr(argString(args, 0))
+			return memUndefined
+		},
+		"toggleAttribute": func(this *memObject, args []Value) Value {
+			name := argString(args, 0)
+			_, present := this.node.getAttr(name)
+			want := !present
+			if len(args) > 1 {
+				want = args[1].Truthy()
+			}
+			if want && !present {
+				this.node.setAttr(name, "")
+			} else if !want && present {
+				this.node.removeAttr(name)
+			}
+			return memBool(want)
+		},
+		"getAttributeNames": func(this *memObject, _ []Value) Value {
+			names := make([]string, len(this.node.attrs))
+			for i, attr := range this.node.attrs {
+				names[i] = attr.name
+			}
+			return memValueOf(names)
+		},
+		"closest": func(this *memObject, args []Value) Value {
+			selector := mustParseSelector(argString(args, 0))
+			for node := this.node; node != nil && node.nodeType == elementNode; node = node.parent {
+				if selector.matches(node) {
+					return node.obj.value()
+				}
+			}
+			return memNull
+		},
+		"matches": func(this *memObject, args []Value) Value {
+			return memBool(mustParseSelector(argString(args, 0)).matches(this.node))
+		},
+		"focus": func(this *memObject, _ []Value) Value {
+			this.node.focus()
+			return memUndefined
+		},
+		"blur": func(this *memObject, _ []Value) Value {
+			this.node.blur()
+			return memUndefined
+		},
+		"click": func(this *memObject, _ []Value) Value {
+			this.node.click()
+			return memUndefined
+		},
+		"append": func(this *memObject, args []Value) Value {
+			for _, arg := range args {
+				if child := nodeOf(arg); child != nil {
+					this.node.appendChild(child)
+				} else {
+					this.node.appendChild(newTextNode(jsString(arg)))
+				}
+			}
+			return memUndefined
+		},
+		"getBoundingClientRect": func(this *memObject, _ []Value) Value {
+			return memRectValue(this.node.rect)
+		},
+		"scrollIntoView": func(this *memObject, args []Value) Value {
+			this.node.scrollIntoView(argAt(args, 0))
+			return memUndefined
+		},
+		"scrollTo": func(this *memObject, args []Value) Value {
+			left, top := scrollArgs(args, this.node.scrollLeft, this.node.scrollTop)
+			this.node.scrollTo(left, top)
+			return memUndefined
+		},
+		"scrollBy": func(this *memObject, args []Value) Value {
+			left, top := scrollArgs(args, 0, 0)
+			this.node.scrollTo(this.node.scrollLeft+left, this.node.scrollTop+top)
+			return memUndefined
+		},
+		"animate": func(this *memObject, args []Value) Value {
+			animation := newMemAnimation(this.node, argAt(args, 0), argAt(args, 1))
+			this.node.animations = append(this.node.animations, animation)
+			return animation.value()
+		},
+		"getAnimations": func(this *memObject, _ []Value) Value {
+			var running []Value
+			for _, animation := range this.node.animations {
+				if state := animation.get("playState").String(); state == "running" || state == "paused" {
+					running = append(running, animation.value())
+				}
+			}
+			return newMemArray(running).value()
+		},
+		"attachShadow": func(this *memObject, args []Value) Value {
+			mode := argAt(args, 0).Get("mode").String()
+			if mode != "open" && mode != "closed" {
+				panic("dom: attachShadow requires the mode open or closed")
+			}
+			return this.node.attachShadow(mode).obj.value()
+		},
+	}),
+	get: func(obj *memObject, name string) (Value, bool) {
+		node := obj.node
+		switch name {
+		case "nodeName", "tagName":
+			return memString(strings.ToUpper(node.tag)), true
+		case "localName":
+			return memString(node.tag), true
+		case "id", "className", "title", "lang", "name", "type", "href", "src", "placeholder", "role":
+			attr := name
+			if name == "className" {
+				attr = "class"
+			}
+			value, _ := node.getAttr(attr)
+			if name == "type" && value == "" && node.tag == "input" {
+				value = "text"
+			}
+			return memString(value), true
+		case "hidden", "disabled", "draggable", "selected", "multiple", "readOnly", "required":
+			if name == "draggable" {
+				value, _ := node.getAttr("draggable")
+				return memBool(value == "true"), true
+			}
+			if name == "selected" && node.selected != nil {
+				return memBool(*node.selected), true
+			}
+			_, ok := node.getAttr(strings.ToLower(name))
+			return memBool(ok), true
+		case "value":
+			return memString(node.formValue()), true
+		case "checked":
+			return memBool(node.isChecked()), true
+		case "selectedIndex":
+			selected := node.selectedOption()
+			for i, option := range node.options() {
+				if option == selected {
+					return memNumber(float64(i)), true
+				}
+			}
+			return memNumber(-1), true
+		case "options":
+			var items []Value
+			for _, option := range node.options() {
+				items = append(items, option.obj.value())
+			}
+			return newMemArray(items).value(), true
+		case "tabIndex":
+			if value, ok := node.getAttr("tabindex"); ok {
+				if n, err := strconv.Atoi(value); err == nil {
+					return memNumber(float64(n)), true
+				}
+			}
+			switch node.tag {
+			case "a", "button", "input", "select", "textarea":
+				return memNumber(0), true
+			}
+			return memNumber(-1), true
+		case "scrollTop":
+			return memNumber(node.scrollTop), true
+		case "scrollLeft":
+			return memNumber(node.scrollLeft), true
+		case "scrollWidth", "scrollHeight":
+			width, height := node.scrollSize()
+			if name == "scrollWidth" {
+				return memNumber(width), true
+			}
+			return memNumber(height), true
+		case "offsetWidth", "clientWidth":
+			return memNumber(node.rect.width), true
+		case "offsetHeight", "clientHeight":
+			return memNumber(node.rect.height), true
+		case "offsetTop", "offsetLeft":
+			var parent memRect
+			if node.parent != nil {
+				parent = node.parent.rect
+			}
+			if name == "offsetTop" {
+				return memNumber(node.rect.top - parent.top), true
+			}
+			return memNumber(node.rect.left - parent.left), true
+		case "innerHTML":
+			return memString(serializeChildren(node)), true
+		case "outerHTML":
+			return memString(serializeNode(node)), true
+		case "children":
+			var items []Value
+			for _, child := range node.elementChildren() {
+				items = append(items, child.obj.value())
+			}
+			return newMemArray(items).value(), true
+		case "childElementCount":
+			return memNumber(float64(len(node.elementChildren()))), true
+		case "firstElementChild", "lastElementChild":
+			elements := node.elementChildren()
+			if len(elements) == 0 {
+				return memNull, true
+			}
+			if name == "firstElementChild" {
+				return elements[0].obj.value(), true
+			}
+			return elements[len(elements)-1].obj.value(), true
+		case "nextElementSibling", "previousElementSibling":
+			if node.parent == nil {
+				return memNull, true
+			}
+			siblings := node.parent.elementChildren()
+			for i, sibling := range siblings {
+				if sibling != node {
+					continue
+				}
+				if name == "nextElementSibling" && i+1 < len(siblings) {
+					return siblings[i+1].obj.value(), true
+				}
+				if name == "previousElementSibling" && i > 0 {
+					return siblings[i-1].obj.value(), true
+				}
+			}
+			return memNull, true
+		case "style":
+			if style, ok := obj.props["__style"]; ok {
+				return style, true
+			}
+			style := newMemObject(styleClass)
+			style.node = node
+			obj.props["__style"] = style.value()
+			return style.value(), true
+		case "classList":
+			if classList, ok := obj.props["__classList"]; ok {
+				return classList, true
+			}
+			classList := newMemObject(classListClass)
+			classList.node = node
+			obj.props["__classList"] = classList.value()
+			return classList.value(), true
+		case "dataset":
+			dataset := newMemObject(datasetClass)
+			dataset.node = node
+			return dataset.value(), true
+		case "shadowRoot":
+			// Closed shadow roots are only reachable through attachShadow
+			if node.shadow != nil && node.shadow.obj.props["__mode"].String() == "open" {
+				return node.shadow.obj.value(), true
+			}
+			return memNull, true
+		}
+		return nil, false
+	},
+}
+
+// setElementProperty handles assignments to element properties
+func setElementProperty(obj *memObject, name string, value Value) bool {
+	node := obj.node
+	switch name {
+	case "id", "className", "title", "lang", "name", "type", "href", "src", "placeholder", "role":
+		attr := name
+		if name == "className" {
+			attr = "class"
+		}
+		node.setAttr(attr, jsString(value))
+		return true
+	case "hidden", "disabled", "multiple", "readOnly", "required":
+		if value.Truthy() {
+			node.setAttr(strings.ToLower(name), "")
+		} else {
+			node.removeAttr(strings.ToLower(name))
+		}
+		return true
+	case "draggable":
+		node.setAttr("draggable", strconv.FormatBool(value.Truthy()))
+		return true
+	case "selected":
+		selected := value.Truthy()
+		node.selected = &selected
+		return true
+	case "value":
+		node.setFormValue(jsString(value))
+		return true
+	case "checked":
+		node.setChecked(value.Truthy())
+		return true
+	case "tabIndex":
+		node.setAttr("tabindex", jsString(value))
+		return true
+	case "innerHTML":
+		node.removeChildren()
+		for _, child := range parseHTML(jsString(value)) {
+			node.appendChild(child)
+		}
+		return true
+	case "style":
+		node.setAttr("style", jsString(value))
+		return true
+	case "scrollTop":
+		node.scrollTo(node.scrollLeft, argFloat([]Value{value}, 0))
+		return true
+	case "scrollLeft":
+		node.scrollTo(argFloat([]Value{value}, 0), node.scrollTop)
+		return true
+	}
+	return false
+}
+
+// The classes refer to one another through the nodes they create, so the
+// links that would form initialization cycles are made here
+func init() {
+	textClass.parent = nodeClass
+	elementClass.set = setElementProperty
+}
+
+// styleClass implements CSSStyleDeclaration
+var styleClass = &memClass{
+	name: "CSSStyleDeclaration",
+	methods: map[string]memMethod{
+		"setProperty": func(this *memObject, args []Value) Value {
+			this.node.setStyle(argString(args, 0), argString(args, 1))
+			return memUndefined
+		},
+		"getPropertyValue": func(this *memObject, args []Value) Value {
+			return memString(this.node.getStyle(argString(args, 0)))
+		},
+		"removeProperty": func(this *memObject, args []Value) Value {
+			old := this.node.getStyle(argString(args, 0))
+			this.node.setStyle(argString(args, 0), "")
+			return memString(old)
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		switch name {
+		case "cssText":
+			return memString(serializeStyles(obj.node.styles)), true
+		case "length":
+			return memNumber(float64(len(obj.node.styles))), true
+		}
+		return memString(obj.node.getStyle(name)), true
+	},
+	set: func(obj *memObject, name string, value Value) bool {
+		if name == "cssText" {
+			obj.node.setAttr("style", jsString(value))
+			return true
+		}
+		obj.node.setStyle(name, jsString(value))
+		return true
+	},
+}
+
+// classListClass implements DOMTokenList for the class attribute
+var classListClass = &memClass{
+	name: "DOMTokenList",
+	methods: map[string]memMethod{
+		"add": func(this *memObject, args []Value) Value {
+			this.node.addClasses(stringArgs(args)...)
+			return memUndefined
+		},
+		"remove": func(this *memObject, args []Value) Value {
+			this.node.removeClasses(stringArgs(args)...)
+			return memUndefined
+		},
+		"toggle": func(this *memObject, args []Value) Value {
+			name := argString(args, 0)
+			want := !this.node.hasClass(name)
+			if len(args) > 1 {
+				want = args[1].Truthy()
+			}
+			if want {
+				this.node.addClasses(name)
+			} else {
+				this.node.removeClasses(name)
+			}
+			return memBool(want)
+		},
+		"contains": func(this *memObject, args []Value) Value {
+			return memBool(this.node.hasClass(argString(args, 0)))
+		},
+		"replace": func(this *memObject, args []Value) Value {
+			old, replacement := argString(args, 0), argString(args, 1)
+			classes := this.node.classes()
+			for i, class := range classes {
+				if class == old {
+					classes[i] = replacement
+					this.node.setClasses(classes)
+					return memBool(true)
+				}
+			}
+			return memBool(false)
+		},
+		"item": func(this *memObject, args []Value) Value {
+			classes := this.node.classes()
+			i := int(argFloat(args, 0))
+			if i < 0 || i >= len(classes) {
+				return memNull
+			}
+			return memString(classes[i])
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		switch name {
+		case "length":
+			return memNumber(float64(len(obj.node.classes()))), true
+		case "value":
+			value, _ := obj.node.getAttr("class")
+			return memString(value), true
+		}
+		return nil, false
+	},
+}
+
+// datasetClass implements DOMStringMap over data-* attributes
+var datasetClass = &memClass{
+	name: "DOMStringMap",
+	get: func(obj *memObject, name string) (Value, bool) {
+		if value, ok := obj.node.getAttr("data-" + cssPropertyName(name)); ok {
+			return memString(value), true
+		}
+		return memUndefined, true
+	},
+	set: func(obj *memObject, name string, value Value) bool {
+		obj.node.setAttr("data-"+cssPropertyName(name), jsString(value))
+		return true
+	},
+}
+
+// memAnimation tracks a Web Animations API animation on the virtual clock
+type memAnimation struct {
+	backend *MemoryBackend
+	timer   int
+}
+
+// animationClass implements Animation
+var animationClass = &memClass{
+	name:   "Animation",
+	parent: eventTargetClass,
+	methods: map[string]memMethod{
+		"play": func(this *memObject, _ []Value) Value {
+			if this.get("playState").String() != "finished" {
+				this.props["playState"] = memString("running")
+			}
+			return memUndefined
+		},
+		"pause": func(this *memObject, _ []Value) Value {
+			if this.get("playState").String() == "running" {
+				this.props["playState"] = memString("paused")
+			}
+			return memUndefined
+		},
+		"finish": func(this *memObject, _ []Value) Value {
+			finishMemAnimation(this)
+			return memUndefined
+		},
+		"cancel": func(this *memObject, _ []Value) Value {
+			animation := this.data.(*memAnimation)
+			if animation.backend != nil {
+				animation.backend.removeTimer(animation.timer)
+			}
+			if state := this.get("playState").String(); state == "running" || state == "paused" {
+				this.props["playState"] = memString("idle")
+				dispatchMemEvent(this, newMemEvent([]Value{memString("cancel")}, nil))
+			}
+			return memUndefined
+		},
+	},
+}
+
+// keyframeEffectClass implements KeyframeEffect, giving back the keyframes
+// an animation was started with
+var keyframeEffectClass = &memClass{
+	name: "KeyframeEffect",
+	methods: map[string]memMethod{
+		"getKeyframes": func(this *memObject, _ []Value) Value {
+			return this.data.(Value)
+		},
+	},
+}
+
+// newMemAnimation starts an animation that finishes after its duration on the
+// virtual clock, or right away when the element is not in a document
+func newMemAnimation(node *memNode, keyframes, options Value) *memObject {
+	duration := 0.0
+	switch options.Type() {
+	case TypeNumber:
+		duration = options.Float()
+	case TypeObject:
+		if d := options.Get("duration"); d.Type() == TypeNumber {
+			duration = d.Float()
+		}
+		if d := options.Get("delay"); d.Type() == TypeNumber {
+			duration += d.Float()
+		}
+	}
+
+	obj := newMemObject(animationClass)
+	obj.props["playState"] = memString("running")
+	obj.props["onfinish"] = memNull
+	obj.props["oncancel"] = memNull
+
+	effect := newMemObject(keyframeEffectClass)
+	effect.data = keyframes
+	obj.props["effect"] = effect.value()
+
+	animation := &memAnimation{}
+	obj.data = animation
+
+	if document := node.ownerDocument(); document != nil {
+		animation.backend = document.backend
+		finish := newMemFunction(func(_ Value, _ []Value) Value {
+			finishMemAnimation(obj)
+			return memUndefined
+		}).value()
+		animation.timer = document.backend.addTimer(finish, duration, false, nil)
+	} else {
+		obj.props["playState"] = memString("finished")
+	}
+
+	return obj
+}
+
+// finishMemAnimation completes an animation and fires its finish handlers
+func finishMemAnimation(obj *memObject) {
+	state := obj.get("playState").String()
+	if state == "finished" || state == "idle" {
+		return
+	}
+
+	animation := obj.data.(*memAnimation)
+	if animation.backend != nil {
+		animation.backend.removeTimer(animation.timer)
+	}
+
+	obj.props["playState"] = memString("finished")
+	dispatchMemEvent(obj, newMemEvent([]Value{memString("finish")}, nil))
+}
+
+// scrollSize returns the size of the node's content, which is its own box
+// grown to take in the boxes of its descendants
+func (n *memNode) scrollSize() (float64, float64) {
+	width, height := n.rect.width, n.rect.height
+	for _, child := range n.descendants() {
+		width = maxFloat(width, child.rect.left+child.rect.width-n.rect.left)
+		height = maxFloat(height, child.rect.top+child.rect.height-n.rect.top)
+	}
+	return width, height
+}
+
+// scrollTo scrolls the node's content, keeping within its scroll size, and
+// dispatches a scroll event if the position changed
+func (n *memNode) scrollTo(left, top float64) {
+	width, height := n.scrollSize()
+	left = maxFloat(0, minFloat(left, width-n.rect.width))
+	top = maxFloat(0, minFloat(top, height-n.rect.height))
+	if left == n.scrollLeft && top == n.scrollTop {
+		return
+	}
+
+	n.scrollLeft, n.scrollTop = left, top
+	dispatchMemEvent(n.obj, newMemEvent([]Value{memString("scroll")}, nil))
+}
+
+// scrollIntoView scrolls the window so that the node's box is aligned as
+// requested by a boolean or ScrollIntoViewOptions argument. Boxes are not
+// moved, since the memory document does no layout
+func (n *memNode) scrollIntoView(arg Value) {
+	document := n.ownerDocument()
+	if document == nil || document.backend == nil {
+		return
+	}
+
+	block, inline := "start", "nearest"
+	switch arg.Type() {
+	case TypeBoolean:
+		if !arg.Bool() {
+			block = "end"
+		}
+	case TypeObject:
+		if value := arg.Get("block"); !value.IsUndefined() {
+			block = jsString(value)
+		}
+		if value := arg.Get("inline"); !value.IsUndefined() {
+			inline = jsString(value)
+		}
+	}
+
+	m := document.backend
+	width, height := m.viewport()
+	x := m.window.get("scrollX").Float() + alignDelta(inline, n.rect.left, n.rect.width, width)
+	y := m.window.get("scrollY").Float() + alignDelta(block, n.rect.top, n.rect.height, height)
+	m.scrollWindow(x, y)
+}
+
+// alignDelta returns how far to scroll to align a box starting at start with
+// the given size inside a viewport of the given size
+func alignDelta(align string, start, size, viewport float64) float64 {
+	switch align {
+	case "start":
+		return start
+	case "center":
+		return start + size/2 - viewport/2
+	case "end":
+		return start + size - viewport
+	case "nearest":
+		if start < 0 {
+			return start
+		}
+		if start+size > viewport {
+			return minFloat(start, start+size-viewport)
+		}
+	}
+	return 0
+}
+
+// scrollArgs reads the (x, y) or ({left, top}) arguments of scrollTo and
+// scrollBy, using the defaults for missing coordinates
+func scrollArgs(args []Value, left, top float64) (float64, float64) {
+	if options := argAt(args, 0); options.Type() == TypeObject {
+		if value := options.Get("left"); !value.IsUndefined() {
+			left = value.Float()
+		}
+		if value := options.Get("top"); !value.IsUndefined() {
+			top = value.Float()
+		}
+		return left, top
+	}
+	return argFloat(args, 0), argFloat(args, 1)
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// elementFromPoint returns the topmost element whose box, as set with
+// SetLayout, contains the point. Elements later in tree order are taken to
+// be on top. Elements hidden with display: none, themselves or through an
+// ancestor, and elements whose pointer-events is none are skipped
+func (n *memNode) elementFromPoint(x, y float64) *memNode {
+	elements := n.descendants()
+	for i := len(elements) - 1; i >= 0; i-- {
+		element := elements[i]
+		rect := element.rect
+		if rect.width <= 0 || rect.height <= 0 ||
+			x < rect.left || x >= rect.left+rect.width || y < rect.top || y >= rect.top+rect.height {
+			continue
+		}
+
+		// pointer-events is inherited, so the nearest value set decides
+		hit, pointerEvents := true, ""
+		for node := element; node != nil && node.nodeType == elementNode; node = node.parent {
+			if pointerEvents == "" {
+				pointerEvents = node.getStyle("pointer-events")
+			}
+			if node.getStyle("display") == "none" {
+				hit = false
+				break
+			}
+		}
+		if hit && pointerEvents != "none" {
+			return element
+		}
+	}
+	return nil
+}
+
+// SetLayout sets the box reported for an element by getBoundingClientRect.
+// The memory document does no layout of its own, so every box is empty
+// until a test assigns one
+func (m *MemoryBackend) SetLayout(element Element, left, top, width, height float64) {
+	if node := nodeOf(element.El); node != nil {
+		node.rect = memRect{left, top, width, height}
+		m.scheduleLayoutCheck()
+	}
+}
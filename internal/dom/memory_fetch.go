@@ -0,0 +1,250 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+)
+
+// fetch for the memory window. Requests are served in process by the
+// http.Handler set as MemoryBackend.FetchHandler, and the returned promise
+// settles before fetch returns. Without a handler every request fails the
+// way a browser reports a network error
+
+// memResponse is the state behind a Response object
+type memResponse struct {
+	status  int
+	url     string
+	body    []byte
+	headers Value
+}
+
+// memAbortSignal is the state behind an AbortSignal object
+type memAbortSignal struct {
+	aborted bool
+	reason  Value
+}
+
+// memError creates an error object with the given name and message
+func memError(name, message string) Value {
+	return memObjectOf(map[string]interface{}{"name": name, "message": message})
+}
+
+// fetch implements window.fetch
+func (m *MemoryBackend) fetch(args []Value) Value {
+	method := "GET"
+	header := http.Header{}
+	var body []byte
+	var signal *memAbortSignal
+
+	if init, ok := argAt(args, 1).(memValue); ok && init.obj != nil {
+		if value := init.Get("method"); value.Type() == TypeString {
+			method = strings.ToUpper(value.String())
+		}
+		copyMemHeaders(header, init.Get("headers"))
+		if value := init.Get("body"); !isNullish(value) {
+			body = memBody(value)
+		}
+		if value, ok := init.Get("signal").(memValue); ok && value.obj != nil {
+			signal, _ = value.obj.data.(*memAbortSignal)
+		}
+	}
+
+	if signal != nil && signal.aborted {
+		return rejectedMemPromise(signal.reason)
+	}
+	if m.FetchHandler == nil {
+		return rejectedMemPromise(memError("TypeError", "Failed to fetch"))
+	}
+
+	target, err := m.history.current().url.Parse(argString(args, 0))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return rejectedMemPromise(memError("TypeError", "Failed to parse URL from "+argString(args, 0)))
+	}
+
+	request := httptest.NewRequest(method, target.String(), bytes.NewReader(body))
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	m.FetchHandler.ServeHTTP(recorder, request)
+	result := recorder.Result()
+	content, _ := io.ReadAll(result.Body)
+
+	response := newMemObject(responseClass)
+	response.data = &memResponse{
+		status:  result.StatusCode,
+		url:     target.String(),
+		body:    content,
+		headers: newMemHeaders(result.Header).value(),
+	}
+	return resolvedMemPromise(response.value())
+}
+
+// memBody returns the bytes of a request body: the contents of an
+// ArrayBuffer or a Uint8Array, or the value converted to a string
+func memBody(value Value) []byte {
+	if obj := value.(memValue).obj; obj != nil {
+		switch data := obj.data.(type) {
+		case *memArrayBuffer:
+			return append([]byte(nil), data.bytes...)
+		case *memUint8Array:
+			return append([]byte(nil), data.bytes...)
+		}
+	}
+	return []byte(jsString(value))
+}
+
+// copyMemHeaders adds the headers held by a Headers object or a plain object
+// to dst
+func copyMemHeaders(dst http.Header, init Value) {
+	value, ok := init.(memValue)
+	if !ok || value.obj == nil {
+		return
+	}
+
+	if src, ok := value.obj.data.(http.Header); ok {
+		for name, values := range src {
+			dst[name] = append(dst[name], values...)
+		}
+		return
+	}
+	for name, item := range value.obj.props {
+		dst.Add(name, jsString(item))
+	}
+}
+
+// responseClass implements Response objects
+var responseClass = &memClass{
+	name: "Response",
+	methods: map[string]memMethod{
+		"text": func(this *memObject, _ []Value) Value {
+			return resolvedMemPromise(memString(string(this.data.(*memResponse).body)))
+		},
+		"arrayBuffer": func(this *memObject, _ []Value) Value {
+			body := append([]byte(nil), this.data.(*memResponse).body...)
+			return resolvedMemPromise(newMemArrayBuffer(body).value())
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		response := obj.data.(*memResponse)
+		switch name {
+		case "status":
+			return memNumber(float64(response.status)), true
+		case "statusText":
+			return memString(http.StatusText(response.status)), true
+		case "ok":
+			return memBool(response.status >= 200 && response.status < 300), true
+		case "url":
+			return memString(response.url), true
+		case "redirected":
+			return memBool(false), true
+		case "headers":
+			return response.headers, true
+		}
+		return nil, false
+	},
+}
+
+// newMemHeaders creates a Headers object holding a copy of header
+func newMemHeaders(header http.Header) *memObject {
+	copied := http.Header{}
+	for name, values := range header {
+		copied[name] = append([]string(nil), values...)
+	}
+
+	obj := newMemObject(headersClass)
+	obj.data = copied
+	return obj
+}
+
+// headersClass implements Headers objects
+var headersClass = &memClass{
+	name: "Headers",
+	methods: map[string]memMethod{
+		"get": func(this *memObject, args []Value) Value {
+			values := this.data.(http.Header).Values(argString(args, 0))
+			if len(values) == 0 {
+				return memNull
+			}
+			return memString(strings.Join(values, ", "))
+		},
+		"has": func(this *memObject, args []Value) Value {
+			return memBool(len(this.data.(http.Header).Values(argString(args, 0))) > 0)
+		},
+		"set": func(this *memObject, args []Value) Value {
+			this.data.(http.Header).Set(argString(args, 0), argString(args, 1))
+			return memUndefined
+		},
+		"append": func(this *memObject, args []Value) Value {
+			this.data.(http.Header).Add(argString(args, 0), argString(args, 1))
+			return memUndefined
+		},
+		"delete": func(this *memObject, args []Value) Value {
+			this.data.(http.Header).Del(argString(args, 0))
+			return memUndefined
+		},
+		"forEach": func(this *memObject, args []Value) Value {
+			header := this.data.(http.Header)
+			names := make([]string, 0, len(header))
+			for name := range header {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				value := strings.Join(header[name], ", ")
+				callMem(argAt(args, 0), memUndefined, []Value{memString(value), memString(strings.ToLower(name)), this.value()})
+			}
+			return memUndefined
+		},
+	},
+}
+
+// abortSignalClass implements AbortSignal objects
+var abortSignalClass = &memClass{
+	name:   "AbortSignal",
+	parent: eventTargetClass,
+	get: func(obj *memObject, name string) (Value, bool) {
+		signal := obj.data.(*memAbortSignal)
+		switch name {
+		case "aborted":
+			return memBool(signal.aborted), true
+		case "reason":
+			return signal.reason, true
+		}
+		return nil, false
+	},
+}
+
+// newMemAbortController creates an AbortController and its signal
+func newMemAbortController(_ []Value) Value {
+	signal := newMemObject(abortSignalClass)
+	signal.data = &memAbortSignal{reason: memUndefined}
+
+	controller := newMemObject(objectClass)
+	controller.props["signal"] = signal.value()
+	controller.props["abort"] = newMemFunction(func(_ Value, args []Value) Value {
+		state := signal.data.(*memAbortSignal)
+		if state.aborted {
+			return memUndefined
+		}
+
+		state.aborted = true
+		state.reason = argAt(args, 0)
+		if state.reason.IsUndefined() {
+			state.reason = memError("AbortError", "signal is aborted without reason")
+		}
+		dispatchMemEvent(signal, newMemEvent([]Value{memString("abort")}, memEventDefaults["Event"]))
+		return memUndefined
+	}).value()
+
+	return controller.value()
+}
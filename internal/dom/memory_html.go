@@ -0,0 +1,721 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// memSelector is a parsed selector list such as "li.todo-item, .done > span"
+type memSelector []memComplex
+
+// memComplex is a chain of compound selectors joined by combinators,
+// stored right to left
+type memComplex []memStep
+
+// memStep is a compound selector and the combinator linking it to the next
+// step on its left (' ', '>', '+', '~' or 0 for the leftmost step)
+type memStep struct {
+	compound   memCompound
+	combinator byte
+}
+
+// memCompound is a run of simple selectors that all apply to one element
+type memCompound struct {
+	tag     string
+	ids     []string
+	classes []string
+	attrs   []memAttrSelector
+	pseudos []memPseudo
+}
+
+// memAttrSelector is an attribute selector such as [data-id="3"]
+type memAttrSelector struct {
+	name  string
+	op    string
+	value string
+}
+
+// memPseudo is a pseudo-class such as :checked or :not(.done)
+type memPseudo struct {
+	name string
+	not  memSelector
+}
+
+// mustParseSelector parses a selector, panicking like the browser throws a
+// SyntaxError for invalid input
+func mustParseSelector(text string) memSelector {
+	selector, err := parseSelector(text)
+	if err != nil {
+		panic(err)
+	}
+	return selector
+}
+
+// parseSelector parses a selector list
+func parseSelector(text string) (memSelector, error) {
+	p := &selectorParser{text: text}
+	selector, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.text) {
+		return nil, fmt.Errorf("dom: invalid selector %q", text)
+	}
+	return selector, nil
+}
+
+// selectorParser is a small recursive descent parser for CSS selectors
+type selectorParser struct {
+	text string
+	pos  int
+}
+
+func (p *selectorParser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.text) && strings.IndexByte(" \t\n\r\f", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) fail() error {
+	return fmt.Errorf("dom: invalid selector %q", p.text)
+}
+
+func (p *selectorParser) parseList() (memSelector, error) {
+	var selector memSelector
+	for {
+		p.skipSpace()
+		complex, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, complex)
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			return selector, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseComplex() (memComplex, error) {
+	var steps []memStep
+	var combinator byte
+
+	for {
+		compound, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, memStep{compound: compound, combinator: combinator})
+
+		spaced := p.skipSpace()
+		switch c := p.peek(); c {
+		case '>', '+', '~':
+			p.pos++
+			p.skipSpace()
+			combinator = c
+		case 0, ',', ')':
+			// Reverse so matching can start from the subject element
+			for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+				steps[i], steps[j] = steps[j], steps[i]
+			}
+			return steps, nil
+		default:
+			if !spaced {
+				return nil, p.fail()
+			}
+			combinator = ' '
+		}
+	}
+}
+
+func (p *selectorParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c == '\\' && p.pos+1 < len(p.text) {
+			p.pos += 2
+			continue
+		}
+		if c == '-' || c == '_' || c >= 0x80 || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return strings.ReplaceAll(p.text[start:p.pos], "\\", "")
+}
+
+func (p *selectorParser) parseCompound() (memCompound, error) {
+	var compound memCompound
+	empty := true
+
+	if p.peek() == '*' {
+		p.pos++
+		empty = false
+	} else if tag := p.parseIdent(); tag != "" {
+		compound.tag = strings.ToLower(tag)
+		empty = false
+	}
+
+	for {
+		switch p.peek() {
+		case '#':
+			p.pos++
+			id := p.parseIdent()
+			if id == "" {
+				return compound, p.fail()
+			}
+			compound.ids = append(compound.ids, id)
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return compound, p.fail()
+			}
+			compound.classes = append(compound.classes, class)
+		case '[':
+			p.pos++
+			attr, err := p.parseAttr()
+			if err != nil {
+				return compound, err
+			}
+			compound.attrs = append(compound.attrs, attr)
+		case ':':
+			p.pos++
+			pseudo, err := p.parsePseudo()
+			if err != nil {
+				return compound, err
+			}
+			compound.pseudos = append(compound.pseudos, pseudo)
+		default:
+			if empty {
+				return compound, p.fail()
+			}
+			return compound, nil
+		}
+		empty = false
+	}
+}
+
+func (p *selectorParser) parseAttr() (memAttrSelector, error) {
+	p.skipSpace()
+	attr := memAttrSelector{name: strings.ToLower(p.parseIdent())}
+	if attr.name == "" {
+		return attr, p.fail()
+	}
+	p.skipSpace()
+
+	if p.peek() == ']' {
+		p.pos++
+		return attr, nil
+	}
+
+	if c := p.peek(); strings.IndexByte("~^$*|", c) >= 0 {
+		attr.op = string(c)
+		p.pos++
+	}
+	if p.peek() != '=' {
+		return attr, p.fail()
+	}
+	attr.op += "="
+	p.pos++
+	p.skipSpace()
+
+	if quote := p.peek(); quote == '"' || quote == '\'' {
+		end := strings.IndexByte(p.text[p.pos+1:], quote)
+		if end < 0 {
+			return attr, p.fail()
+		}
+		attr.value = p.text[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else {
+		attr.value = p.parseIdent()
+	}
+
+	p.skipSpace()
+	if p.peek() != ']' {
+		return attr, p.fail()
+	}
+	p.pos++
+	return attr, nil
+}
+
+func (p *selectorParser) parsePseudo() (memPseudo, error) {
+	pseudo := memPseudo{name: strings.ToLower(p.parseIdent())}
+
+	switch pseudo.name {
+	case "root", "first-child", "last-child", "only-child", "checked", "focus",
+		"disabled", "enabled", "empty", "hover", "active", "focus-within":
+		return pseudo, nil
+	case "not":
+		if p.peek() != '(' {
+			return pseudo, p.fail()
+		}
+		p.pos++
+		not, err := p.parseList()
+		if err != nil {
+			return pseudo, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return pseudo, p.fail()
+		}
+		p.pos++
+		pseudo.not = not
+		return pseudo, nil
+	}
+	return pseudo, p.fail()
+}
+
+// matches reports whether the element matches any selector in the list
+func (s memSelector) matches(element *memNode) bool {
+	for _, complex := range s {
+		if complex.matches(element, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks step i against element and then walks left through the chain
+func (c memComplex) matches(element *memNode, i int) bool {
+	if element == nil || element.nodeType != elementNode || !c[i].compound.matches(element) {
+		return false
+	}
+	if i == len(c)-1 {
+		return true
+	}
+
+	switch c[i].combinator {
+	case '>':
+		return c.matches(element.parent, i+1)
+	case ' ':
+		for ancestor := element.parent; ancestor != nil; ancestor = ancestor.parent {
+			if c.matches(ancestor, i+1) {
+				return true
+			}
+		}
+	case '+':
+		return c.matches(previousElement(element), i+1)
+	case '~':
+		for sibling := previousElement(element); sibling != nil; sibling = previousElement(sibling) {
+			if c.matches(sibling, i+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// previousElement returns the previous element sibling
+func previousElement(element *memNode) *memNode {
+	if element.parent == nil {
+		return nil
+	}
+	var previous *memNode
+	for _, sibling := range element.parent.children {
+		if sibling == element {
+			return previous
+		}
+		if sibling.nodeType == elementNode {
+			previous = sibling
+		}
+	}
+	return nil
+}
+
+// matches checks every simple selector of the compound
+func (c memCompound) matches(element *memNode) bool {
+	if c.tag != "" && c.tag != element.tag {
+		return false
+	}
+	for _, id := range c.ids {
+		if value, _ := element.getAttr("id"); value != id {
+			return false
+		}
+	}
+	for _, class := range c.classes {
+		if !element.hasClass(class) {
+			return false
+		}
+	}
+	for _, attr := range c.attrs {
+		if !attr.matches(element) {
+			return false
+		}
+	}
+	for _, pseudo := range c.pseudos {
+		if !pseudo.matches(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches applies the attribute operator
+func (a memAttrSelector) matches(element *memNode) bool {
+	value, ok := element.getAttr(a.name)
+	if !ok {
+		return false
+	}
+
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "~=":
+		for _, word := range strings.Fields(value) {
+			if word == a.value {
+				return true
+			}
+		}
+		return false
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	case "|=":
+		return value == a.value || strings.HasPrefix(value, a.value+"-")
+	}
+	return false
+}
+
+// matches evaluates the pseudo-class
+func (p memPseudo) matches(element *memNode) bool {
+	switch p.name {
+	case "root":
+		return element.parent != nil && element.parent.nodeType == documentNode
+	case "first-child":
+		return previousElement(element) == nil
+	case "last-child":
+		if element.parent == nil {
+			return true
+		}
+		siblings := element.parent.elementChildren()
+		return siblings[len(siblings)-1] == element
+	case "only-child":
+		return element.parent == nil || len(element.parent.elementChildren()) == 1
+	case "checked":
+		if element.tag == "option" {
+			return element.parent != nil && element.obj.get("selected").Truthy()
+		}
+		return element.tag == "input" && element.isChecked()
+	case "focus":
+		document := element.ownerDocument()
+		return document != nil && document.active == element
+	case "focus-within":
+		document := element.ownerDocument()
+		return document != nil && document.active != nil && element.contains(document.active)
+	case "disabled", "enabled":
+		_, disabled := element.getAttr("disabled")
+		return disabled == (p.name == "disabled")
+	case "empty":
+		for _, child := range element.children {
+			if child.nodeType == elementNode || child.nodeType == textNode && child.text != "" {
+				return false
+			}
+		}
+		return true
+	case "hover", "active":
+		return false
+	case "not":
+		return !p.not.matches(element)
+	}
+	return false
+}
+
+// voidElements never have children or closing tags
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// rawTextElements contain text that is not parsed as markup
+var rawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// impliedEnd lists the open elements a start tag closes implicitly
+var impliedEnd = map[string][]string{
+	"li":     {"li"},
+	"option": {"option"},
+	"p":      {"p"},
+	"tr":     {"tr", "td", "th"},
+	"td":     {"td", "th"},
+	"th":     {"td", "th"},
+	"dt":     {"dt", "dd"},
+	"dd":     {"dt", "dd"},
+}
+
+// parseHTML parses an HTML fragment into nodes. It handles what templates in
+// this repository produce rather than the full HTML5 algorithm
+func parseHTML(text string) []*memNode {
+	root := newElement("template")
+	stack := []*memNode{root}
+	current := func() *memNode { return stack[len(stack)-1] }
+
+	for len(text) > 0 {
+		lt := strings.IndexByte(text, '<')
+		if lt != 0 {
+			if lt < 0 {
+				lt = len(text)
+			}
+			current().appendChild(newTextNode(html.UnescapeString(text[:lt])))
+			text = text[lt:]
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "<!--"):
+			end := strings.Index(text, "-->")
+			if end < 0 {
+				end = len(text) - 3
+			}
+			comment := newMemNode(textClass, commentNode, "")
+			comment.text = text[4:end]
+			current().appendChild(comment)
+			text = text[end+3:]
+
+		case strings.HasPrefix(text, "<!"):
+			end := strings.IndexByte(text, '>')
+			if end < 0 {
+				end = len(text) - 1
+			}
+			text = text[end+1:]
+
+		case strings.HasPrefix(text, "</"):
+			end := strings.IndexByte(text, '>')
+			if end < 0 {
+				end = len(text) - 1
+			}
+			tag := strings.ToLower(strings.TrimSpace(text[2:end]))
+			text = text[end+1:]
+
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].tag == tag {
+					stack = stack[:i]
+					break
+				}
+			}
+
+		default:
+			element, rest, selfClosing, ok := parseStartTag(text)
+			if !ok {
+				current().appendChild(newTextNode("<"))
+				text = text[1:]
+				continue
+			}
+			text = rest
+
+			if closes, ok := impliedEnd[element.tag]; ok {
+				for i := len(stack) - 1; i > 0; i-- {
+					if contains(closes, stack[i].tag) {
+						stack = stack[:i]
+						break
+					}
+					if stack[i].tag == "ul" || stack[i].tag == "ol" || stack[i].tag == "select" || stack[i].tag == "table" {
+						break
+					}
+				}
+			}
+
+			current().appendChild(element)
+
+			if rawTextElements[element.tag] {
+				end := strings.Index(strings.ToLower(text), "</"+element.tag)
+				if end < 0 {
+					end = len(text)
+				}
+				raw := text[:end]
+				if element.tag == "textarea" || element.tag == "title" {
+					raw = html.UnescapeString(raw)
+				}
+				if raw != "" {
+					element.appendChild(newTextNode(raw))
+				}
+				text = text[end:]
+				if close := strings.IndexByte(text, '>'); close >= 0 {
+					text = text[close+1:]
+				}
+				continue
+			}
+
+			if !selfClosing && !voidElements[element.tag] {
+				stack = append(stack, element)
+			}
+		}
+	}
+
+	nodes := append([]*memNode(nil), root.children...)
+	root.removeChildren()
+	return nodes
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStartTag parses "<tag attr=value ...>" at the start of text
+func parseStartTag(text string) (element *memNode, rest string, selfClosing, ok bool) {
+	pos := 1
+	start := pos
+	for pos < len(text) && !isHTMLSpace(text[pos]) && text[pos] != '>' && text[pos] != '/' {
+		pos++
+	}
+	if pos == start {
+		return nil, text, false, false
+	}
+	element = newElement(text[start:pos])
+
+	for {
+		for pos < len(text) && isHTMLSpace(text[pos]) {
+			pos++
+		}
+		if pos >= len(text) {
+			return element, "", false, true
+		}
+
+		switch text[pos] {
+		case '>':
+			return element, text[pos+1:], selfClosing, true
+		case '/':
+			selfClosing = true
+			pos++
+			continue
+		}
+
+		nameStart := pos
+		for pos < len(text) && !isHTMLSpace(text[pos]) && text[pos] != '=' && text[pos] != '>' && text[pos] != '/' {
+			pos++
+		}
+		name := text[nameStart:pos]
+
+		for pos < len(text) && isHTMLSpace(text[pos]) {
+			pos++
+		}
+
+		value := ""
+		if pos < len(text) && text[pos] == '=' {
+			pos++
+			for pos < len(text) && isHTMLSpace(text[pos]) {
+				pos++
+			}
+			if pos < len(text) && (text[pos] == '"' || text[pos] == '\'') {
+				quote := text[pos]
+				end := strings.IndexByte(text[pos+1:], quote)
+				if end < 0 {
+					end = len(text) - pos - 1
+				}
+				value = text[pos+1 : pos+1+end]
+				pos += end + 2
+			} else {
+				valueStart := pos
+				for pos < len(text) && !isHTMLSpace(text[pos]) && text[pos] != '>' {
+					pos++
+				}
+				value = text[valueStart:pos]
+			}
+		}
+
+		if _, exists := element.getAttr(name); !exists {
+			element.setAttr(name, html.UnescapeString(value))
+		}
+		selfClosing = false
+	}
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// serializeNode returns the outer HTML of a node
+func serializeNode(node *memNode) string {
+	var sb strings.Builder
+	writeNode(&sb, node)
+	return sb.String()
+}
+
+// serializeChildren returns the inner HTML of a node
+func serializeChildren(node *memNode) string {
+	var sb strings.Builder
+	for _, child := range node.children {
+		writeNode(&sb, child)
+	}
+	return sb.String()
+}
+
+// writeNode writes the HTML of a node and its subtree
+func writeNode(sb *strings.Builder, node *memNode) {
+	switch node.nodeType {
+	case textNode:
+		if node.parent != nil && rawTextElements[node.parent.tag] && node.parent.tag != "textarea" && node.parent.tag != "title" {
+			sb.WriteString(node.text)
+		} else {
+			sb.WriteString(escapeHTML(node.text, false))
+		}
+		return
+	case commentNode:
+		sb.WriteString("<!--" + node.text + "-->")
+		return
+	case documentNode:
+		for _, child := range node.children {
+			writeNode(sb, child)
+		}
+		return
+	}
+
+	sb.WriteString("<" + node.tag)
+	for _, attr := range node.attrs {
+		value := attr.value
+		if attr.name == "style" {
+			value, _ = node.getAttr("style")
+		}
+		sb.WriteString(" " + attr.name + `="` + escapeHTML(value, true) + `"`)
+	}
+	sb.WriteString(">")
+
+	if voidElements[node.tag] {
+		return
+	}
+	for _, child := range node.children {
+		writeNode(sb, child)
+	}
+	sb.WriteString("</" + node.tag + ">")
+}
+
+// escapeHTML escapes text the way the browser serializes it
+func escapeHTML(text string, attribute bool) string {
+	replacer := textEscaper
+	if attribute {
+		replacer = attributeEscaper
+	}
+	return replacer.Replace(text)
+}
+
+var (
+	textEscaper      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\u00a0", "&nbsp;")
+	attributeEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "\u00a0", "&nbsp;")
+)
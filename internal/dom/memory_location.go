@@ -0,0 +1,259 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Session history for the memory window. location and history share a list
+// of entries; pushState and replaceState change it silently, while history
+// traversal and hash assignments fire popstate and hashchange from a zero
+// delay timer, as a browser fires them asynchronously
+
+// memHistory is the session history of the memory window
+type memHistory struct {
+	entries []memHistoryEntry
+	index   int
+}
+
+// memHistoryEntry is one entry of the session history
+type memHistoryEntry struct {
+	url   *url.URL
+	state Value
+}
+
+// memStartURL is the address of a new memory window
+const memStartURL = "http://localhost/"
+
+// newMemHistory creates a history holding the start address
+func newMemHistory() *memHistory {
+	start, _ := url.Parse(memStartURL)
+	return &memHistory{entries: []memHistoryEntry{{url: start, state: memNull}}}
+}
+
+// current returns the active entry
+func (h *memHistory) current() memHistoryEntry {
+	return h.entries[h.index]
+}
+
+// push adds an entry after the active one, dropping any forward entries
+func (h *memHistory) push(entry memHistoryEntry) {
+	h.entries = append(h.entries[:h.index+1], entry)
+	h.index++
+}
+
+// resolve parses ref relative to the active entry
+func (h *memHistory) resolve(ref string) *url.URL {
+	base := h.current().url
+	if ref == "" {
+		copied := *base
+		return &copied
+	}
+
+	resolved, err := base.Parse(ref)
+	if err != nil || resolved.Host != base.Host || resolved.Scheme != base.Scheme {
+		panic("dom: cannot navigate the memory window to " + ref)
+	}
+	return resolved
+}
+
+// SetLocation replaces the address of the current history entry without
+// firing any event, as if the page had been loaded from rawURL
+func (m *MemoryBackend) SetLocation(rawURL string) {
+	m.history.entries[m.history.index].url = m.history.resolve(rawURL)
+}
+
+// traverse moves delta entries through the history and fires popstate, and
+// hashchange when only the fragment differs
+func (m *MemoryBackend) traverse(delta int) {
+	target := m.history.index + delta
+	if delta == 0 || target < 0 || target >= len(m.history.entries) {
+		return
+	}
+
+	from := m.history.current().url
+	m.history.index = target
+	to := m.history.current()
+
+	m.firePopState(to.state)
+	if sameDocument(from, to.url) && from.Fragment != to.url.Fragment {
+		m.fireHashChange(from, to.url)
+	}
+}
+
+// setHash moves to a new entry differing only in its fragment, like an
+// assignment to location.hash or a click on an in-page link
+func (m *MemoryBackend) setHash(hash string) {
+	from := m.history.current().url
+	to := *from
+	to.Fragment = strings.TrimPrefix(hash, "#")
+	to.RawFragment = ""
+	if to.Fragment == from.Fragment {
+		return
+	}
+
+	m.history.push(memHistoryEntry{url: &to, state: memNull})
+	m.firePopState(memNull)
+	m.fireHashChange(from, &to)
+}
+
+// firePopState fires popstate for the entry holding state
+func (m *MemoryBackend) firePopState(state Value) {
+	event := newMemEvent([]Value{memString("popstate")}, memEventDefaults["PopStateEvent"])
+	event.props["state"] = state
+	m.fireLater(event)
+}
+
+// fireHashChange fires hashchange for a move between two addresses
+func (m *MemoryBackend) fireHashChange(from, to *url.URL) {
+	event := newMemEvent([]Value{memString("hashchange")}, memEventDefaults["HashChangeEvent"])
+	event.props["oldURL"] = memString(from.String())
+	event.props["newURL"] = memString(to.String())
+	m.fireLater(event)
+}
+
+// fireLater dispatches event on the window from a zero delay timer
+func (m *MemoryBackend) fireLater(event *memObject) {
+	fire := newMemFunction(func(_ Value, _ []Value) Value {
+		dispatchMemEvent(m.window, event)
+		return memUndefined
+	}).value()
+	m.addTimer(fire, 0, false, nil)
+}
+
+// sameDocument reports whether two addresses differ at most in their fragment
+func sameDocument(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && a.Host == b.Host && a.Path == b.Path && a.RawQuery == b.RawQuery
+}
+
+// locationClass implements window.location
+var locationClass = &memClass{
+	name: "Location",
+	methods: map[string]memMethod{
+		"assign": func(this *memObject, args []Value) Value {
+			assignMemLocation(this.data.(*MemoryBackend), argString(args, 0))
+			return memUndefined
+		},
+		"replace": func(this *memObject, args []Value) Value {
+			m := this.data.(*MemoryBackend)
+			m.history.entries[m.history.index] = memHistoryEntry{url: m.history.resolve(argString(args, 0)), state: memNull}
+			return memUndefined
+		},
+		"reload": func(_ *memObject, _ []Value) Value {
+			return memUndefined
+		},
+		"toString": func(this *memObject, _ []Value) Value {
+			return memString(this.data.(*MemoryBackend).history.current().url.String())
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		u := obj.data.(*MemoryBackend).history.current().url
+		switch name {
+		case "href":
+			return memString(u.String()), true
+		case "origin":
+			return memString(u.Scheme + "://" + u.Host), true
+		case "protocol":
+			return memString(u.Scheme + ":"), true
+		case "host":
+			return memString(u.Host), true
+		case "hostname":
+			return memString(u.Hostname()), true
+		case "port":
+			return memString(u.Port()), true
+		case "pathname":
+			path := u.EscapedPath()
+			if path == "" {
+				path = "/"
+			}
+			return memString(path), true
+		case "search":
+			if u.RawQuery == "" {
+				return memString(""), true
+			}
+			return memString("?" + u.RawQuery), true
+		case "hash":
+			if u.Fragment == "" {
+				return memString(""), true
+			}
+			return memString("#" + u.EscapedFragment()), true
+		}
+		return nil, false
+	},
+	set: func(obj *memObject, name string, value Value) bool {
+		m := obj.data.(*MemoryBackend)
+		switch name {
+		case "hash":
+			m.setHash(jsString(value))
+			return true
+		case "href":
+			assignMemLocation(m, jsString(value))
+			return true
+		}
+		return false
+	},
+}
+
+// assignMemLocation navigates the memory window. Only moves within the
+// document, which change the fragment, are possible
+func assignMemLocation(m *MemoryBackend, ref string) {
+	from := m.history.current().url
+	to := m.history.resolve(ref)
+	if !sameDocument(from, to) {
+		panic("dom: the memory window cannot load another document: " + ref)
+	}
+	m.setHash(to.Fragment)
+}
+
+// historyClass implements window.history
+var historyClass = &memClass{
+	name: "History",
+	methods: map[string]memMethod{
+		"pushState": func(this *memObject, args []Value) Value {
+			h := this.data.(*MemoryBackend).history
+			h.push(memHistoryEntry{url: h.resolve(optionalString(argAt(args, 2))), state: argAt(args, 0)})
+			return memUndefined
+		},
+		"replaceState": func(this *memObject, args []Value) Value {
+			h := this.data.(*MemoryBackend).history
+			h.entries[h.index] = memHistoryEntry{url: h.resolve(optionalString(argAt(args, 2))), state: argAt(args, 0)}
+			return memUndefined
+		},
+		"back": func(this *memObject, _ []Value) Value {
+			this.data.(*MemoryBackend).traverse(-1)
+			return memUndefined
+		},
+		"forward": func(this *memObject, _ []Value) Value {
+			this.data.(*MemoryBackend).traverse(1)
+			return memUndefined
+		},
+		"go": func(this *memObject, args []Value) Value {
+			this.data.(*MemoryBackend).traverse(int(argFloat(args, 0)))
+			return memUndefined
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		h := obj.data.(*MemoryBackend).history
+		switch name {
+		case "length":
+			return memNumber(float64(len(h.entries))), true
+		case "state":
+			return h.current().state, true
+		}
+		return nil, false
+	},
+}
+
+// newMemLocation creates the location and history objects of the window
+func (m *MemoryBackend) newMemLocation() (location, history *memObject) {
+	m.history = newMemHistory()
+
+	location = newMemObject(locationClass)
+	location.data = m
+	history = newMemObject(historyClass)
+	history.data = m
+	return location, history
+}
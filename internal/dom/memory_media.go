@@ -0,0 +1,146 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Media queries for the memory window. matchMedia understands lists of
+// "(feature: value)" conditions joined by "and", with min- and max-width and
+// height compared to the viewport and every other feature looked up in the
+// values set with SetMediaFeature. A list whose result changes fires change
+// at once, when SetMediaFeature or SetViewport is called
+
+// memMediaDefaults are the media features of a new memory window
+var memMediaDefaults = map[string]string{
+	"prefers-color-scheme":   "light",
+	"prefers-reduced-motion": "no-preference",
+	"prefers-contrast":       "no-preference",
+	"hover":                  "hover",
+	"pointer":                "fine",
+}
+
+// memMediaQueryList is the state behind a MediaQueryList
+type memMediaQueryList struct {
+	backend *MemoryBackend
+	query   string
+	matches bool
+}
+
+// mediaQueryListClass implements MediaQueryList
+var mediaQueryListClass = &memClass{
+	name:   "MediaQueryList",
+	parent: eventTargetClass,
+	methods: map[string]memMethod{
+		// The legacy listener methods of older Safari versions
+		"addListener": func(this *memObject, args []Value) Value {
+			return callMem(this.get("addEventListener"), this.value(), []Value{memString("change"), argAt(args, 0)})
+		},
+		"removeListener": func(this *memObject, args []Value) Value {
+			return callMem(this.get("removeEventListener"), this.value(), []Value{memString("change"), argAt(args, 0)})
+		},
+	},
+	get: func(obj *memObject, name string) (Value, bool) {
+		list := obj.data.(*memMediaQueryList)
+		switch name {
+		case "matches":
+			return memBool(list.matches), true
+		case "media":
+			return memString(list.query), true
+		}
+		return nil, false
+	},
+}
+
+// SetMediaFeature sets the value of a media feature, such as
+// "prefers-color-scheme" to "dark", and fires change on the media query
+// lists whose result changes
+func (m *MemoryBackend) SetMediaFeature(name, value string) {
+	m.mediaFeatures[name] = value
+	m.checkMediaQueries()
+}
+
+// matchMedia creates a MediaQueryList for query
+func (m *MemoryBackend) matchMedia(query string) Value {
+	list := &memMediaQueryList{backend: m, query: query}
+	list.matches = m.evaluateMedia(query)
+
+	obj := newMemObject(mediaQueryListClass)
+	obj.data = list
+	m.mediaQueries = append(m.mediaQueries, obj)
+	return obj.value()
+}
+
+// checkMediaQueries fires change on every list whose result changed
+func (m *MemoryBackend) checkMediaQueries() {
+	for _, obj := range append([]*memObject(nil), m.mediaQueries...) {
+		list := obj.data.(*memMediaQueryList)
+		matches := m.evaluateMedia(list.query)
+		if matches == list.matches {
+			continue
+		}
+
+		list.matches = matches
+		event := newMemEvent([]Value{memString("change")}, nil)
+		event.props["matches"] = memBool(matches)
+		event.props["media"] = memString(list.query)
+		dispatchMemEvent(obj, event)
+	}
+}
+
+// evaluateMedia reports whether the window matches query
+func (m *MemoryBackend) evaluateMedia(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	negate := strings.HasPrefix(query, "not ")
+	query = strings.TrimPrefix(query, "not ")
+	query = strings.TrimPrefix(strings.TrimPrefix(query, "only "), "screen and ")
+
+	matches := true
+	for _, condition := range strings.Split(query, " and ") {
+		condition = strings.TrimSpace(condition)
+		if condition == "all" || condition == "screen" {
+			continue
+		}
+		if !m.evaluateMediaFeature(strings.TrimSuffix(strings.TrimPrefix(condition, "("), ")")) {
+			matches = false
+		}
+	}
+	return matches != negate
+}
+
+// evaluateMediaFeature reports whether a single "feature: value" holds
+func (m *MemoryBackend) evaluateMediaFeature(condition string) bool {
+	name, value, _ := strings.Cut(condition, ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+	width, height := m.viewport()
+	for prefix, compare := range map[string]func(actual, limit float64) bool{
+		"min-": func(actual, limit float64) bool { return actual >= limit },
+		"max-": func(actual, limit float64) bool { return actual <= limit },
+	} {
+		var actual float64
+		switch strings.TrimPrefix(name, prefix) {
+		case "width":
+			actual = width
+		case "height":
+			actual = height
+		default:
+			continue
+		}
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		limit, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 64)
+		return err == nil && compare(actual, limit)
+	}
+
+	actual, ok := m.mediaFeatures[name]
+	if value == "" {
+		// A bare feature matches any value but "none" and "no-preference"
+		return ok && actual != "none" && actual != "no-preference"
+	}
+	return ok && actual == value
+}
@@ -0,0 +1,610 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Observers for the memory document. Mutation records are collected as the
+// tree changes and delivered from a zero delay timer, standing in for the
+// microtask a browser uses. Intersection and resize observers work on the
+// boxes assigned with SetLayout and are checked after observe and after every
+// SetLayout or SetViewport call
+
+// memMutation is a change to report to mutation observers
+type memMutation struct {
+	kind          string // "childList", "attributes" or "characterData"
+	attributeName string
+	oldValue      string
+	added         []*memNode
+	removed       []*memNode
+	previous      *memNode
+	next          *memNode
+}
+
+// memMutationOptions are the options given to MutationObserver.observe
+type memMutationOptions struct {
+	childList             bool
+	attributes            bool
+	characterData         bool
+	subtree               bool
+	attributeOldValue     bool
+	characterDataOldValue bool
+	attributeFilter       []string
+}
+
+// memRegistration is a mutation observer watching a node
+type memRegistration struct {
+	observer *memMutationObserver
+	options  memMutationOptions
+}
+
+// memMutationObserver is the state behind a MutationObserver object
+type memMutationObserver struct {
+	obj      *memObject
+	backend  *MemoryBackend
+	callback Value
+	records  []Value
+	targets  []*memNode
+	timer    int // pending delivery, 0 when none
+}
+
+// wants reports whether a registration with these options reports change
+func (o memMutationOptions) wants(change memMutation) bool {
+	switch change.kind {
+	case "childList":
+		return o.childList
+	case "characterData":
+		return o.characterData
+	case "attributes":
+		if !o.attributes {
+			return false
+		}
+		if o.attributeFilter == nil {
+			return true
+		}
+		for _, name := range o.attributeFilter {
+			if name == change.attributeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// queueMutation records change on target for every interested observer
+func queueMutation(target *memNode, change memMutation) {
+	var seen map[*memMutationObserver]bool
+
+	for node := target; node != nil; node = node.parent {
+		for _, registration := range node.registrations {
+			if node != target && !registration.options.subtree {
+				continue
+			}
+			if !registration.options.wants(change) || seen[registration.observer] {
+				continue
+			}
+
+			if seen == nil {
+				seen = map[*memMutationObserver]bool{}
+			}
+			seen[registration.observer] = true
+
+			keepOld := (change.kind == "attributes" && registration.options.attributeOldValue) ||
+				(change.kind == "characterData" && registration.options.characterDataOldValue)
+			registration.observer.enqueue(newMutationRecord(target, change, keepOld))
+		}
+	}
+}
+
+// newMutationRecord builds a MutationRecord object
+func newMutationRecord(target *memNode, change memMutation, keepOld bool) Value {
+	record := newMemObject(objectClass)
+	record.props["type"] = memString(change.kind)
+	record.props["target"] = target.obj.value()
+	record.props["addedNodes"] = memNodeList(change.added)
+	record.props["removedNodes"] = memNodeList(change.removed)
+	record.props["previousSibling"] = nodeValue(change.previous)
+	record.props["nextSibling"] = nodeValue(change.next)
+	record.props["attributeName"] = memNull
+	record.props["oldValue"] = memNull
+
+	if change.kind == "attributes" {
+		record.props["attributeName"] = memString(change.attributeName)
+	}
+	if keepOld {
+		record.props["oldValue"] = memString(change.oldValue)
+	}
+	return record.value()
+}
+
+// memNodeList wraps nodes in an array
+func memNodeList(nodes []*memNode) Value {
+	items := make([]Value, len(nodes))
+	for i, node := range nodes {
+		items[i] = node.obj.value()
+	}
+	return newMemArray(items).value()
+}
+
+// enqueue adds a record and schedules its delivery
+func (o *memMutationObserver) enqueue(record Value) {
+	o.records = append(o.records, record)
+	if o.timer != 0 {
+		return
+	}
+
+	deliver := newMemFunction(func(_ Value, _ []Value) Value {
+		o.timer = 0
+		if len(o.records) == 0 {
+			return memUndefined
+		}
+
+		records := o.records
+		o.records = nil
+		callMem(o.callback, o.obj.value(), []Value{newMemArray(records).value(), o.obj.value()})
+		return memUndefined
+	})
+	o.timer = o.backend.addTimer(deliver.value(), 0, false, nil)
+}
+
+// observe starts watching target, replacing an earlier registration for it
+func (o *memMutationObserver) observe(target *memNode, options memMutationOptions) {
+	for _, registration := range target.registrations {
+		if registration.observer == o {
+			registration.options = options
+			return
+		}
+	}
+
+	target.registrations = append(target.registrations, &memRegistration{o, options})
+	o.targets = append(o.targets, target)
+}
+
+// disconnect stops watching every target and drops undelivered records
+func (o *memMutationObserver) disconnect() {
+	for _, target := range o.targets {
+		for i, registration := range target.registrations {
+			if registration.observer == o {
+				target.registrations = append(target.registrations[:i], target.registrations[i+1:]...)
+				break
+			}
+		}
+	}
+
+	o.targets = nil
+	o.records = nil
+	if o.timer != 0 {
+		o.backend.removeTimer(o.timer)
+		o.timer = 0
+	}
+}
+
+// parseMutationOptions reads a MutationObserverInit dictionary, applying the
+// implied attributes and characterData flags
+func parseMutationOptions(value Value) memMutationOptions {
+	var options memMutationOptions
+	if isNullish(value) {
+		return options
+	}
+
+	flag := func(name string) (bool, bool) {
+		v := value.Get(name)
+		if v.IsUndefined() {
+			return false, false
+		}
+		return v.Truthy(), true
+	}
+
+	options.childList, _ = flag("childList")
+	options.subtree, _ = flag("subtree")
+	options.attributeOldValue, _ = flag("attributeOldValue")
+	options.characterDataOldValue, _ = flag("characterDataOldValue")
+
+	filter := value.Get("attributeFilter")
+	if !isNullish(filter) {
+		options.attributeFilter = []string{}
+		for i := 0; i < filter.Length(); i++ {
+			options.attributeFilter = append(options.attributeFilter, strings.ToLower(jsString(filter.Index(i))))
+		}
+	}
+
+	attributes, set := flag("attributes")
+	if !set && (options.attributeOldValue || options.attributeFilter != nil) {
+		attributes = true
+	}
+	options.attributes = attributes
+
+	characterData, set := flag("characterData")
+	if !set && options.characterDataOldValue {
+		characterData = true
+	}
+	options.characterData = characterData
+
+	return options
+}
+
+// mutationObserverClass implements MutationObserver objects
+var mutationObserverClass = &memClass{
+	name: "MutationObserver",
+	methods: map[string]memMethod{
+		"observe": func(this *memObject, args []Value) Value {
+			target := nodeOf(argAt(args, 0))
+			if target == nil {
+				panic("dom: MutationObserver.observe target is not a node")
+			}
+			options := parseMutationOptions(argAt(args, 1))
+			if !options.childList && !options.attributes && !options.characterData {
+				panic("dom: MutationObserver.observe needs childList, attributes or characterData")
+			}
+			this.data.(*memMutationObserver).observe(target, options)
+			return memUndefined
+		},
+		"disconnect": func(this *memObject, _ []Value) Value {
+			this.data.(*memMutationObserver).disconnect()
+			return memUndefined
+		},
+		"takeRecords": func(this *memObject, _ []Value) Value {
+			observer := this.data.(*memMutationObserver)
+			records := observer.records
+			observer.records = nil
+			return newMemArray(records).value()
+		},
+	},
+}
+
+// newMemMutationObserver creates a MutationObserver object
+func (m *MemoryBackend) newMemMutationObserver(args []Value) Value {
+	observer := &memMutationObserver{backend: m, callback: argAt(args, 0)}
+	observer.obj = newMemObject(mutationObserverClass)
+	observer.obj.data = observer
+	return observer.obj.value()
+}
+
+// memObservation is a target of an intersection or resize observer with the
+// state it was last reported in
+type memObservation struct {
+	node     *memNode
+	reported bool
+	state    int     // intersection: threshold index * 2 + intersecting
+	width    float64 // resize: last reported size
+	height   float64
+}
+
+// memLayoutObserver is the state behind an IntersectionObserver or
+// ResizeObserver object
+type memLayoutObserver struct {
+	obj        *memObject
+	backend    *MemoryBackend
+	callback   Value
+	resize     bool
+	root       *memNode
+	margin     [4]string // top, right, bottom, left
+	thresholds []float64
+	targets    []*memObservation
+}
+
+// observe starts watching target
+func (o *memLayoutObserver) observe(target *memNode) {
+	for _, observation := range o.targets {
+		if observation.node == target {
+			return
+		}
+	}
+
+	o.targets = append(o.targets, &memObservation{node: target})
+	o.backend.addLayoutObserver(o)
+	o.backend.scheduleLayoutCheck()
+}
+
+// unobserve stops watching target
+func (o *memLayoutObserver) unobserve(target *memNode) {
+	for i, observation := range o.targets {
+		if observation.node == target {
+			o.targets = append(o.targets[:i], o.targets[i+1:]...)
+			break
+		}
+	}
+	if len(o.targets) == 0 {
+		o.backend.removeLayoutObserver(o)
+	}
+}
+
+// disconnect stops watching every target
+func (o *memLayoutObserver) disconnect() {
+	o.targets = nil
+	o.backend.removeLayoutObserver(o)
+}
+
+// check collects the entries for targets whose state changed and calls the
+// observer's callback with them
+func (o *memLayoutObserver) check() {
+	var entries []Value
+	for _, observation := range o.targets {
+		var entry Value
+		if o.resize {
+			entry = o.checkSize(observation)
+		} else {
+			entry = o.checkIntersection(observation)
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) > 0 {
+		callMem(o.callback, o.obj.value(), []Value{newMemArray(entries).value(), o.obj.value()})
+	}
+}
+
+// checkSize returns a ResizeObserverEntry if the target's size changed
+func (o *memLayoutObserver) checkSize(observation *memObservation) Value {
+	r := observation.node.rect
+	if observation.reported && observation.width == r.width && observation.height == r.height {
+		return nil
+	}
+
+	observation.reported = true
+	observation.width, observation.height = r.width, r.height
+
+	size := func() Value {
+		return newMemArray([]Value{memObjectOf(map[string]interface{}{
+			"inlineSize": r.width, "blockSize": r.height,
+		})}).value()
+	}
+	return memObjectOf(map[string]interface{}{
+		"target":         observation.node.obj.value(),
+		"contentRect":    memRectValue(memRect{0, 0, r.width, r.height}),
+		"contentBoxSize": size(),
+		"borderBoxSize":  size(),
+	})
+}
+
+// checkIntersection returns an IntersectionObserverEntry if the target
+// crossed a threshold or started or stopped intersecting
+func (o *memLayoutObserver) checkIntersection(observation *memObservation) Value {
+	target := observation.node.rect
+	rootBounds, connected := o.rootBounds(observation.node)
+
+	// Edge-adjacent boxes still count as intersecting
+	left := math.Max(target.left, rootBounds.left)
+	top := math.Max(target.top, rootBounds.top)
+	right := math.Min(target.left+target.width, rootBounds.left+rootBounds.width)
+	bottom := math.Min(target.top+target.height, rootBounds.top+rootBounds.height)
+	intersecting := connected && right >= left && bottom >= top
+
+	intersection := memRect{}
+	ratio := 0.0
+	if intersecting {
+		intersection = memRect{left, top, right - left, bottom - top}
+		if area := target.width * target.height; area > 0 {
+			ratio = intersection.width * intersection.height / area
+		} else {
+			ratio = 1
+		}
+	}
+
+	index := 0
+	for _, threshold := range o.thresholds {
+		if ratio > threshold || (intersecting && ratio == threshold) {
+			index++
+		}
+	}
+
+	state := index * 2
+	if intersecting {
+		state++
+	}
+	if observation.reported && observation.state == state {
+		return nil
+	}
+	observation.reported = true
+	observation.state = state
+
+	return memObjectOf(map[string]interface{}{
+		"target":             observation.node.obj.value(),
+		"isIntersecting":     intersecting,
+		"intersectionRatio":  ratio,
+		"time":               o.backend.now,
+		"boundingClientRect": memRectValue(target),
+		"intersectionRect":   memRectValue(intersection),
+		"rootBounds":         memRectValue(rootBounds),
+	})
+}
+
+// rootBounds returns the root box grown by the root margin, and whether the
+// target is inside the root at all
+func (o *memLayoutObserver) rootBounds(target *memNode) (memRect, bool) {
+	var bounds memRect
+	var connected bool
+	if o.root != nil {
+		bounds = o.root.rect
+		connected = o.root != target && o.root.contains(target)
+	} else {
+		width, height := o.backend.viewport()
+		bounds = memRect{0, 0, width, height}
+		connected = target.ownerDocument() != nil
+	}
+
+	top := marginPixels(o.margin[0], bounds.height)
+	right := marginPixels(o.margin[1], bounds.width)
+	bottom := marginPixels(o.margin[2], bounds.height)
+	left := marginPixels(o.margin[3], bounds.width)
+
+	bounds.left -= left
+	bounds.top -= top
+	bounds.width += left + right
+	bounds.height += top + bottom
+	return bounds, connected
+}
+
+// marginPixels resolves a root margin length such as "10px" or "5%"
+func marginPixels(length string, size float64) float64 {
+	if strings.HasSuffix(length, "%") {
+		n, _ := strconv.ParseFloat(strings.TrimSuffix(length, "%"), 64)
+		return n * size / 100
+	}
+	n, _ := strconv.ParseFloat(strings.TrimSuffix(length, "px"), 64)
+	return n
+}
+
+// parseRootMargin splits a CSS margin shorthand into its four sides
+func parseRootMargin(margin string) [4]string {
+	parts := strings.Fields(margin)
+	switch len(parts) {
+	case 1:
+		return [4]string{parts[0], parts[0], parts[0], parts[0]}
+	case 2:
+		return [4]string{parts[0], parts[1], parts[0], parts[1]}
+	case 3:
+		return [4]string{parts[0], parts[1], parts[2], parts[1]}
+	case 4:
+		return [4]string{parts[0], parts[1], parts[2], parts[3]}
+	}
+	return [4]string{"0px", "0px", "0px", "0px"}
+}
+
+// memRectValue converts a box to a DOMRect-like object
+func memRectValue(r memRect) Value {
+	return memObjectOf(map[string]interface{}{
+		"x": r.left, "y": r.top, "top": r.top, "left": r.left,
+		"right": r.left + r.width, "bottom": r.top + r.height, "width": r.width, "height": r.height,
+	})
+}
+
+// layoutObserverClass implements IntersectionObserver and ResizeObserver
+// objects
+var layoutObserverClass = &memClass{
+	name: "Observer",
+	methods: map[string]memMethod{
+		"observe": func(this *memObject, args []Value) Value {
+			target := nodeOf(argAt(args, 0))
+			if target == nil {
+				panic("dom: observe target is not an element")
+			}
+			this.data.(*memLayoutObserver).observe(target)
+			return memUndefined
+		},
+		"unobserve": func(this *memObject, args []Value) Value {
+			if target := nodeOf(argAt(args, 0)); target != nil {
+				this.data.(*memLayoutObserver).unobserve(target)
+			}
+			return memUndefined
+		},
+		"disconnect": func(this *memObject, _ []Value) Value {
+			this.data.(*memLayoutObserver).disconnect()
+			return memUndefined
+		},
+	},
+}
+
+// newMemIntersectionObserver creates an IntersectionObserver object
+func (m *MemoryBackend) newMemIntersectionObserver(args []Value) Value {
+	observer := &memLayoutObserver{
+		backend:    m,
+		callback:   argAt(args, 0),
+		margin:     parseRootMargin(""),
+		thresholds: []float64{0},
+	}
+
+	if options := argAt(args, 1); !isNullish(options) {
+		if root := options.Get("root"); !isNullish(root) {
+			observer.root = nodeOf(root)
+		}
+		if margin := options.Get("rootMargin"); !margin.IsUndefined() {
+			observer.margin = parseRootMargin(jsString(margin))
+		}
+		if threshold := options.Get("threshold"); !threshold.IsUndefined() {
+			observer.thresholds = nil
+			if threshold.Type() == TypeNumber {
+				observer.thresholds = []float64{threshold.Float()}
+			} else {
+				for i := 0; i < threshold.Length(); i++ {
+					observer.thresholds = append(observer.thresholds, threshold.Index(i).Float())
+				}
+			}
+		}
+	}
+
+	observer.obj = newMemObject(layoutObserverClass)
+	observer.obj.data = observer
+	observer.obj.props["root"] = nodeValue(observer.root)
+	observer.obj.props["rootMargin"] = memString(strings.Join(observer.margin[:], " "))
+	return observer.obj.value()
+}
+
+// newMemResizeObserver creates a ResizeObserver object
+func (m *MemoryBackend) newMemResizeObserver(args []Value) Value {
+	observer := &memLayoutObserver{backend: m, callback: argAt(args, 0), resize: true}
+	observer.obj = newMemObject(layoutObserverClass)
+	observer.obj.data = observer
+	return observer.obj.value()
+}
+
+// addLayoutObserver registers an observer for layout checks
+func (m *MemoryBackend) addLayoutObserver(o *memLayoutObserver) {
+	for _, observer := range m.layoutObservers {
+		if observer == o {
+			return
+		}
+	}
+	m.layoutObservers = append(m.layoutObservers, o)
+}
+
+// removeLayoutObserver stops layout checks for an observer
+func (m *MemoryBackend) removeLayoutObserver(o *memLayoutObserver) {
+	for i, observer := range m.layoutObservers {
+		if observer == o {
+			m.layoutObservers = append(m.layoutObservers[:i], m.layoutObservers[i+1:]...)
+			return
+		}
+	}
+}
+
+// scheduleLayoutCheck checks the layout observers from a zero delay timer
+func (m *MemoryBackend) scheduleLayoutCheck() {
+	if m.layoutTimer != 0 || len(m.layoutObservers) == 0 {
+		return
+	}
+
+	check := newMemFunction(func(_ Value, _ []Value) Value {
+		m.layoutTimer = 0
+		for _, observer := range append([]*memLayoutObserver(nil), m.layoutObservers...) {
+			observer.check()
+		}
+		return memUndefined
+	})
+	m.layoutTimer = m.addTimer(check.value(), 0, false, nil)
+}
+
+// viewport returns the size of the window
+func (m *MemoryBackend) viewport() (float64, float64) {
+	return m.window.get("innerWidth").Float(), m.window.get("innerHeight").Float()
+}
+
+// scrollWindow moves the window's scroll position and dispatches a scroll
+// event if it changed
+func (m *MemoryBackend) scrollWindow(x, y float64) {
+	x, y = maxFloat(0, x), maxFloat(0, y)
+	if x == m.window.get("scrollX").Float() && y == m.window.get("scrollY").Float() {
+		return
+	}
+
+	m.window.props["scrollX"] = memNumber(x)
+	m.window.props["scrollY"] = memNumber(y)
+	dispatchMemEvent(m.window, newMemEvent([]Value{memString("scroll")}, nil))
+}
+
+// SetViewport sets the window size reported by innerWidth and innerHeight
+// and used as the root of intersection observers
+func (m *MemoryBackend) SetViewport(width, height float64) {
+	m.window.props["innerWidth"] = memNumber(width)
+	m.window.props["innerHeight"] = memNumber(height)
+	m.scheduleLayoutCheck()
+	m.checkMediaQueries()
+	dispatchMemEvent(m.window, newMemEvent([]Value{memString("resize")}, nil))
+}
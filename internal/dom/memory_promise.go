@@ -0,0 +1,217 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import "sync"
+
+// Promises for the memory window. A promise runs its reactions as soon as it
+// settles, or straight away when then is called on a settled promise, rather
+// than from a microtask. Go code waiting on a promise therefore never depends
+// on the virtual clock moving. Promises may be settled from any goroutine and
+// run their reactions there
+
+// memPromiseState is the state of a memory promise
+type memPromiseState int
+
+const (
+	memPending memPromiseState = iota
+	memFulfilled
+	memRejected
+)
+
+// memPromise is the state behind a Promise object
+type memPromise struct {
+	mu        sync.Mutex
+	state     memPromiseState
+	value     Value
+	locked    bool // resolved with another promise, waiting for it
+	reactions []func()
+}
+
+// newMemPromise creates a pending promise
+func newMemPromise() (*memObject, *memPromise) {
+	obj := newMemObject(promiseClass)
+	promise := &memPromise{value: memUndefined}
+	obj.data = promise
+	return obj, promise
+}
+
+// resolvedMemPromise creates a promise fulfilled with value
+func resolvedMemPromise(value Value) Value {
+	obj, promise := newMemPromise()
+	promise.resolve(value)
+	return obj.value()
+}
+
+// rejectedMemPromise creates a promise rejected with reason
+func rejectedMemPromise(reason Value) Value {
+	obj, promise := newMemPromise()
+	promise.reject(reason)
+	return obj.value()
+}
+
+// resolve fulfills the promise, or makes it follow value when value is
+// itself a promise
+func (p *memPromise) resolve(value Value) {
+	p.mu.Lock()
+	if p.state != memPending || p.locked {
+		p.mu.Unlock()
+		return
+	}
+
+	if v, ok := value.(memValue); ok && v.obj != nil {
+		if other, ok := v.obj.data.(*memPromise); ok {
+			p.locked = true
+			p.mu.Unlock()
+			other.subscribe(func() {
+				if other.state == memFulfilled {
+					p.settle(memFulfilled, other.value)
+				} else {
+					p.settle(memRejected, other.value)
+				}
+			})
+			return
+		}
+	}
+
+	p.mu.Unlock()
+	p.settle(memFulfilled, value)
+}
+
+// reject rejects the promise with reason
+func (p *memPromise) reject(reason Value) {
+	p.mu.Lock()
+	blocked := p.state != memPending || p.locked
+	p.mu.Unlock()
+
+	if !blocked {
+		p.settle(memRejected, reason)
+	}
+}
+
+// settle records the outcome and runs the waiting reactions
+func (p *memPromise) settle(state memPromiseState, value Value) {
+	p.mu.Lock()
+	if p.state != memPending {
+		p.mu.Unlock()
+		return
+	}
+	p.state = state
+	p.value = value
+	reactions := p.reactions
+	p.reactions = nil
+	p.mu.Unlock()
+
+	for _, reaction := range reactions {
+		reaction()
+	}
+}
+
+// subscribe runs fn once the promise has settled
+func (p *memPromise) subscribe(fn func()) {
+	p.mu.Lock()
+	if p.state == memPending {
+		p.reactions = append(p.reactions, fn)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	fn()
+}
+
+// then chains the handlers and returns the derived promise
+func (p *memPromise) then(onFulfilled, onRejected Value) Value {
+	obj, derived := newMemPromise()
+
+	p.subscribe(func() {
+		handler := onFulfilled
+		if p.state == memRejected {
+			handler = onRejected
+		}
+
+		if handler.Type() != TypeFunction {
+			if p.state == memFulfilled {
+				derived.resolve(p.value)
+			} else {
+				derived.reject(p.value)
+			}
+			return
+		}
+
+		derived.resolve(callMem(handler, memUndefined, []Value{p.value}))
+	})
+
+	return obj.value()
+}
+
+// resolvers returns the resolve and reject functions handed to an executor
+func (p *memPromise) resolvers() (resolve, reject Value) {
+	resolve = newMemFunction(func(_ Value, args []Value) Value {
+		p.resolve(argAt(args, 0))
+		return memUndefined
+	}).value()
+	reject = newMemFunction(func(_ Value, args []Value) Value {
+		p.reject(argAt(args, 0))
+		return memUndefined
+	}).value()
+	return resolve, reject
+}
+
+// promiseClass implements Promise objects. Its methods are set in init, as
+// they create promises of the class themselves
+var promiseClass = &memClass{name: "Promise"}
+
+func init() {
+	promiseClass.methods = map[string]memMethod{
+		"then": func(this *memObject, args []Value) Value {
+			return this.data.(*memPromise).then(argAt(args, 0), argAt(args, 1))
+		},
+		"catch": func(this *memObject, args []Value) Value {
+			return this.data.(*memPromise).then(memUndefined, argAt(args, 0))
+		},
+		"finally": func(this *memObject, args []Value) Value {
+			p := this.data.(*memPromise)
+			onFinally := argAt(args, 0)
+			if onFinally.Type() != TypeFunction {
+				return p.then(memUndefined, memUndefined)
+			}
+
+			obj, derived := newMemPromise()
+			p.subscribe(func() {
+				callMem(onFinally, memUndefined, nil)
+				if p.state == memFulfilled {
+					derived.resolve(p.value)
+				} else {
+					derived.reject(p.value)
+				}
+			})
+			return obj.value()
+		},
+	}
+}
+
+// newMemPromiseConstructor creates window.Promise with its resolve and
+// reject helpers
+func newMemPromiseConstructor() Value {
+	constructor := newMemConstructor(func(args []Value) Value {
+		obj, promise := newMemPromise()
+		resolve, reject := promise.resolvers()
+		callMem(argAt(args, 0), memUndefined, []Value{resolve, reject})
+		return obj.value()
+	})
+
+	statics := constructor.(memValue).obj.props
+	statics["resolve"] = newMemFunction(func(_ Value, args []Value) Value {
+		value := argAt(args, 0)
+		if v, ok := value.(memValue); ok && v.obj != nil && v.obj.class == promiseClass {
+			return value
+		}
+		return resolvedMemPromise(value)
+	}).value()
+	statics["reject"] = newMemFunction(func(_ Value, args []Value) Value {
+		return rejectedMemPromise(argAt(args, 0))
+	}).value()
+
+	return constructor
+}
@@ -0,0 +1,350 @@
+package dom
+
+import (
+	"strings"
+)
+
+// MutationRecord describes one change seen by a MutationObserver
+type MutationRecord struct {
+	Type          string // "childList", "attributes" or "characterData"
+	Target        Element
+	AddedNodes    []Element
+	RemovedNodes  []Element
+	AttributeName string
+	// OldValue is set when AttributeOldValue or CharacterDataOldValue was
+	// requested
+	OldValue string
+}
+
+// MutationObserverOptions selects the changes a MutationObserver reports.
+// At least one of ChildList, Attributes and CharacterData must be set
+type MutationObserverOptions struct {
+	ChildList             bool
+	Attributes            bool
+	CharacterData         bool
+	Subtree               bool
+	AttributeOldValue     bool
+	CharacterDataOldValue bool
+	AttributeFilter       []string // only report these attributes
+}
+
+// MutationCallback receives the changes recorded since the last call
+type MutationCallback func(records []MutationRecord, observer *MutationObserver)
+
+// MutationObserver reports changes to the DOM tree
+type MutationObserver struct {
+	obj      Value
+	callback Func
+	active   bool
+}
+
+// NewMutationObserver creates an observer that calls callback with batches of
+// changes. Call Observe to choose what to watch and Disconnect to stop and
+// release the callback
+func NewMutationObserver(callback MutationCallback) *MutationObserver {
+	observer := &MutationObserver{active: true}
+	observer.callback = FuncOf(func(_ Value, args []Value) interface{} {
+		if len(args) > 0 {
+			callback(mutationRecords(args[0]), observer)
+		}
+		return nil
+	})
+
+	observer.obj = Global().Get("MutationObserver").New(observer.callback)
+	return observer
+}
+
+// Observe starts watching target. Observing the same target again replaces
+// its options
+func (o *MutationObserver) Observe(target Element, options MutationObserverOptions) {
+	if !o.Active() || !target.Exists() {
+		return
+	}
+
+	init := Global().Get("Object").New()
+	init.Set("childList", options.ChildList)
+	init.Set("subtree", options.Subtree)
+	if options.Attributes {
+		init.Set("attributes", true)
+	}
+	if options.CharacterData {
+		init.Set("characterData", true)
+	}
+	if options.AttributeOldValue {
+		init.Set("attributeOldValue", true)
+	}
+	if options.CharacterDataOldValue {
+		init.Set("characterDataOldValue", true)
+	}
+	if options.AttributeFilter != nil {
+		filter := Global().Get("Array").New(len(options.AttributeFilter))
+		for i, name := range options.AttributeFilter {
+			filter.SetIndex(i, name)
+		}
+		init.Set("attributeFilter", filter)
+	}
+
+	o.obj.Call("observe", target.El, init)
+}
+
+// TakeRecords returns the changes not yet passed to the callback and removes
+// them from the queue
+func (o *MutationObserver) TakeRecords() []MutationRecord {
+	if !o.Active() {
+		return nil
+	}
+	return mutationRecords(o.obj.Call("takeRecords"))
+}
+
+// Disconnect stops the observer and releases its callback
+func (o *MutationObserver) Disconnect() {
+	if !o.Active() {
+		return
+	}
+
+	o.active = false
+	o.obj.Call("disconnect")
+	o.callback.Release()
+}
+
+// Active reports whether the observer has not been disconnected
+func (o *MutationObserver) Active() bool {
+	return o != nil && o.active
+}
+
+// mutationRecords converts an array of MutationRecord objects
+func mutationRecords(list Value) []MutationRecord {
+	records := make([]MutationRecord, list.Length())
+	for i := range records {
+		record := list.Index(i)
+		records[i] = MutationRecord{
+			Type:          record.Get("type").String(),
+			Target:        wrapElement(record.Get("target")),
+			AddedNodes:    elementList(record.Get("addedNodes")),
+			RemovedNodes:  elementList(record.Get("removedNodes")),
+			AttributeName: optionalString(record.Get("attributeName")),
+			OldValue:      optionalString(record.Get("oldValue")),
+		}
+	}
+	return records
+}
+
+// elementList converts a NodeList to elements
+func elementList(list Value) []Element {
+	if isNullish(list) {
+		return nil
+	}
+
+	elements := make([]Element, list.Length())
+	for i := range elements {
+		elements[i] = wrapElement(list.Index(i))
+	}
+	return elements
+}
+
+// optionalString returns the string value, or "" for null and undefined
+func optionalString(value Value) string {
+	if isNullish(value) {
+		return ""
+	}
+	return value.String()
+}
+
+// IntersectionEntry reports how much of a target is visible inside the root
+type IntersectionEntry struct {
+	Target         Element
+	IsIntersecting bool
+	// Ratio is the visible fraction of the target, from 0 to 1
+	Ratio float64
+	// Time is the timestamp of the change in milliseconds
+	Time float64
+	// BoundingRect is the target's box, IntersectionRect its visible part and
+	// RootBounds the root's box grown by the root margin
+	BoundingRect     Rect
+	IntersectionRect Rect
+	RootBounds       Rect
+}
+
+// IntersectionObserverOptions configures an IntersectionObserver
+type IntersectionObserverOptions struct {
+	Root       Element   // scrolling ancestor to intersect with; the viewport when empty
+	RootMargin string    // CSS margin around the root, such as "200px 0px"
+	Threshold  []float64 // ratios at which to report; 0 when empty
+}
+
+// IntersectionCallback receives the targets whose visibility changed
+type IntersectionCallback func(entries []IntersectionEntry, observer *IntersectionObserver)
+
+// IntersectionObserver reports when elements enter or leave the viewport or
+// a scrolling ancestor
+type IntersectionObserver struct {
+	obj      Value
+	callback Func
+	active   bool
+}
+
+// NewIntersectionObserver creates an observer that calls callback when an
+// observed target crosses one of the thresholds. Each target is reported once
+// right after Observe with its current state
+func NewIntersectionObserver(callback IntersectionCallback, options IntersectionObserverOptions) *IntersectionObserver {
+	observer := &IntersectionObserver{active: true}
+	observer.callback = FuncOf(func(_ Value, args []Value) interface{} {
+		if len(args) == 0 {
+			return nil
+		}
+
+		list := args[0]
+		entries := make([]IntersectionEntry, list.Length())
+		for i := range entries {
+			entry := list.Index(i)
+			entries[i] = IntersectionEntry{
+				Target:         wrapElement(entry.Get("target")),
+				IsIntersecting: entry.Get("isIntersecting").Bool(),
+				Ratio:          entry.Get("intersectionRatio").Float(),
+				Time:           entry.Get("time").Float(),
+
+				BoundingRect:     rectOf(entry.Get("boundingClientRect")),
+				IntersectionRect: rectOf(entry.Get("intersectionRect")),
+				RootBounds:       rectOf(entry.Get("rootBounds")),
+			}
+		}
+		callback(entries, observer)
+		return nil
+	})
+
+	init := Global().Get("Object").New()
+	if options.Root.Exists() {
+		init.Set("root", options.Root.El)
+	}
+	if strings.TrimSpace(options.RootMargin) != "" {
+		init.Set("rootMargin", options.RootMargin)
+	}
+	if len(options.Threshold) > 0 {
+		threshold := Global().Get("Array").New(len(options.Threshold))
+		for i, ratio := range options.Threshold {
+			threshold.SetIndex(i, ratio)
+		}
+		init.Set("threshold", threshold)
+	}
+
+	observer.obj = Global().Get("IntersectionObserver").New(observer.callback, init)
+	return observer
+}
+
+// Observe starts watching target
+func (o *IntersectionObserver) Observe(target Element) {
+	if o.Active() && target.Exists() {
+		o.obj.Call("observe", target.El)
+	}
+}
+
+// Unobserve stops watching target
+func (o *IntersectionObserver) Unobserve(target Element) {
+	if o.Active() && target.Exists() {
+		o.obj.Call("unobserve", target.El)
+	}
+}
+
+// Disconnect stops watching every target and releases the callback
+func (o *IntersectionObserver) Disconnect() {
+	if !o.Active() {
+		return
+	}
+
+	o.active = false
+	o.obj.Call("disconnect")
+	o.callback.Release()
+}
+
+// Active reports whether the observer has not been disconnected
+func (o *IntersectionObserver) Active() bool {
+	return o != nil && o.active
+}
+
+// ResizeEntry reports the new size of an observed element
+type ResizeEntry struct {
+	Target Element
+	// Width and Height are the size of the content box
+	Width  float64
+	Height float64
+	// BorderWidth and BorderHeight are the size of the border box
+	BorderWidth  float64
+	BorderHeight float64
+}
+
+// ResizeCallback receives the targets whose size changed
+type ResizeCallback func(entries []ResizeEntry, observer *ResizeObserver)
+
+// ResizeObserver reports changes to the size of elements
+type ResizeObserver struct {
+	obj      Value
+	callback Func
+	active   bool
+}
+
+// NewResizeObserver creates an observer that calls callback when an observed
+// target changes size. Each target is reported once right after Observe
+func NewResizeObserver(callback ResizeCallback) *ResizeObserver {
+	observer := &ResizeObserver{active: true}
+	observer.callback = FuncOf(func(_ Value, args []Value) interface{} {
+		if len(args) == 0 {
+			return nil
+		}
+
+		list := args[0]
+		entries := make([]ResizeEntry, list.Length())
+		for i := range entries {
+			entry := list.Index(i)
+			rect := entry.Get("contentRect")
+			entries[i] = ResizeEntry{
+				Target:       wrapElement(entry.Get("target")),
+				Width:        rect.Get("width").Float(),
+				Height:       rect.Get("height").Float(),
+				BorderWidth:  rect.Get("width").Float(),
+				BorderHeight: rect.Get("height").Float(),
+			}
+
+			// borderBoxSize is missing in older browsers
+			if sizes := entry.Get("borderBoxSize"); !isNullish(sizes) && sizes.Length() > 0 {
+				size := sizes.Index(0)
+				entries[i].BorderWidth = size.Get("inlineSize").Float()
+				entries[i].BorderHeight = size.Get("blockSize").Float()
+			}
+		}
+		callback(entries, observer)
+		return nil
+	})
+
+	observer.obj = Global().Get("ResizeObserver").New(observer.callback)
+	return observer
+}
+
+// Observe starts watching target
+func (o *ResizeObserver) Observe(target Element) {
+	if o.Active() && target.Exists() {
+		o.obj.Call("observe", target.El)
+	}
+}
+
+// Unobserve stops watching target
+func (o *ResizeObserver) Unobserve(target Element) {
+	if o.Active() && target.Exists() {
+		o.obj.Call("unobserve", target.El)
+	}
+}
+
+// Disconnect stops watching every target and releases the callback
+func (o *ResizeObserver) Disconnect() {
+	if !o.Active() {
+		return
+	}
+
+	o.active = false
+	o.obj.Call("disconnect")
+	o.callback.Release()
+}
+
+// Active reports whether the observer has not been disconnected
+func (o *ResizeObserver) Active() bool {
+	return o != nil && o.active
+}
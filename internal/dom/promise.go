@@ -0,0 +1,177 @@
+package dom
+
+import "fmt"
+
+// JSError is an error thrown or a rejection reason from JavaScript
+type JSError struct {
+	Value Value // the reason, usually an Error object
+}
+
+// settled is the outcome of a Promise
+type settled struct {
+	value    Value // fulfillment value, or the rejection reason
+	rejected bool
+}
+
+// watchPromise returns a channel receiving the outcome of promise. The
+// callbacks only send on a buffered channel, so they return at once and never
+// hold up the event loop; the receiving goroutine runs once they have
+func watchPromise(promise Value) <-chan settled {
+	outcome := make(chan settled, 1)
+
+	var onFulfilled, onRejected Func
+	settle := func(args []Value, rejected bool) {
+		value := Undefined()
+		if len(args) > 0 {
+			value = args[0]
+		}
+		outcome <- settled{value: value, rejected: rejected}
+
+		onFulfilled.Release()
+		onRejected.Release()
+	}
+
+	onFulfilled = FuncOf(func(_ Value, args []Value) interface{} {
+		settle(args, false)
+		return nil
+	})
+	onRejected = FuncOf(func(_ Value, args []Value) interface{} {
+		settle(args, true)
+		return nil
+	})
+
+	promise.Call("then", onFulfilled, onRejected)
+	return outcome
+}
+
+// Await waits for promise to settle and returns the value it was fulfilled
+// with. A rejection is returned as a *JSError holding the reason. Values that
+// are not promises are returned as they are.
+//
+// Await blocks the calling goroutine while the event loop settles the
+// promise, so it must not be called directly inside an event handler or
+// another callback from JavaScript; start a goroutine there instead
+func Await(promise Value) (Value, error) {
+	if promise.Type() != TypeObject || promise.Get("then").Type() != TypeFunction {
+		return promise, nil
+	}
+
+	outcome := <-watchPromise(promise)
+	if outcome.rejected {
+		return nil, &JSError{Value: outcome.value}
+	}
+	return outcome.value, nil
+}
+
+// AsyncFunc wraps fn as a JavaScript function that returns a Promise, for
+// exports whose callers expect one. fn runs synchronously inside the call and
+// the promise settles before the call returns: it is fulfilled with the result
+// of fn, or rejected with an Error carrying the message of its error. A result
+// ValueOf cannot convert rejects the promise, and a panic in fn is reported and
+// rejects it as well. A promise returned by fn is followed instead:
+//
+//	dom.Global().Set("setTheme", dom.AsyncFunc(func(_ dom.Value, args []dom.Value) (interface{}, error) {
+//		if err := themes.Set(args[0].String(), themes.Mode()); err != nil {
+//			return nil, err
+//		}
+//		return themes.Current(), nil
+//	}))
+//
+// Like signals, stores and the DOM, fn belongs to the event loop goroutine
+// and must not block it. Only work that really waits, on Fetch or a channel,
+// starts a goroutine of its own, and it comes back to the event loop with
+// SetTimeout before it touches any state:
+//
+//	dom.Go(func() {
+//		todos, err := dom.FetchJSON[[]Todo](context.Background(), "GET", url, nil)
+//		dom.GetWindow().SetTimeout(func() {
+//			if err != nil {
+//				dom.ReportError(err, "refresh")
+//				return
+//			}
+//			store.Set(todos)
+//		}, 0)
+//	})
+//
+// The Func must be released once JavaScript no longer calls it
+func AsyncFunc(fn func(this Value, args []Value) (interface{}, error)) Func {
+	return FuncOf(func(this Value, args []Value) interface{} {
+		var resolve, reject Value
+		executor := FuncOf(func(_ Value, resolvers []Value) interface{} {
+			resolve, reject = resolvers[0], resolvers[1]
+			return nil
+		})
+		promise := Global().Get("Promise").New(executor)
+		executor.Release()
+
+		var result Value
+		var err error
+		func() {
+			defer func() {
+				if value := recover(); value != nil {
+					err = reportPanic(value, "async function")
+				}
+			}()
+			var value interface{}
+			if value, err = fn(this, args); err == nil {
+				result, err = resultValue(value)
+			}
+		}()
+
+		if err != nil {
+			reject.Invoke(errorValue(err))
+		} else {
+			resolve.Invoke(result)
+		}
+		return promise
+	})
+}
+
+// resultValue converts the result of an async function to a Value, returning
+// an error for a Go value JavaScript cannot hold, such as a struct
+func resultValue(result interface{}) (value Value, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("dom: async function result: %v", recovered)
+		}
+	}()
+	return ValueOf(result), nil
+}
+
+// errorValue converts err into a JavaScript Error. A *JSError returned as it
+// is passes its reason through unchanged
+func errorValue(err error) Value {
+	if jsErr, ok := err.(*JSError); ok {
+		return jsErr.Value
+	}
+	return Global().Get("Error").New(err.Error())
+}
+
+// Name returns the name of the Error, such as "TypeError", or "" when the
+// reason is not an Error
+func (e *JSError) Name() string {
+	if e.Value.Type() == TypeObject {
+		if name := e.Value.Get("name"); name.Type() == TypeString {
+			return name.String()
+		}
+	}
+	return ""
+}
+
+// Message returns the message of the Error, or the reason converted to a
+// string when it is not an Error
+func (e *JSError) Message() string {
+	if e.Value.Type() == TypeObject {
+		if message := e.Value.Get("message"); message.Type() == TypeString {
+			return message.String()
+		}
+	}
+	return e.Value.String()
+}
+
+func (e *JSError) Error() string {
+	if name := e.Name(); name != "" {
+		return name + ": " + e.Message()
+	}
+	return e.Message()
+}
@@ -0,0 +1,87 @@
+package dom
+
+import (
+	"errors"
+	"testing"
+)
+
+// callAsync calls an AsyncFunc wrapping fn and waits for its promise
+func callAsync(fn func(this Value, args []Value) (interface{}, error), args ...interface{}) (Value, error) {
+	async := AsyncFunc(fn)
+	defer async.Release()
+	return Await(async.Invoke(args...))
+}
+
+func TestAsyncFuncResolvesWithTheResult(t *testing.T) {
+	value, err := callAsync(func(_ Value, args []Value) (interface{}, error) {
+		return args[0].Int() * 2, nil
+	}, 21)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.Int() != 42 {
+		t.Errorf("resolved with %v, want 42", value)
+	}
+}
+
+func TestAsyncFuncRunsInsideTheCall(t *testing.T) {
+	ran := false
+	async := AsyncFunc(func(Value, []Value) (interface{}, error) {
+		ran = true
+		return "done", nil
+	})
+	defer async.Release()
+
+	promise := async.Invoke()
+	if !ran {
+		t.Fatal("fn did not run inside the call")
+	}
+	if value, err := Await(promise); err != nil || value.String() != "done" {
+		t.Errorf("Await = %v, %v", value, err)
+	}
+}
+
+func TestAsyncFuncRejectsWithTheError(t *testing.T) {
+	_, err := callAsync(func(Value, []Value) (interface{}, error) {
+		return nil, errors.New("no todos")
+	})
+	var jsErr *JSError
+	if !errors.As(err, &jsErr) || jsErr.Message() != "no todos" {
+		t.Errorf("err = %v, want the rejection message", err)
+	}
+}
+
+func TestAsyncFuncRejectsAResultItCannotConvert(t *testing.T) {
+	reports := captureReports(t)
+
+	_, err := callAsync(func(Value, []Value) (interface{}, error) {
+		return struct{ Count int }{3}, nil
+	})
+	if err == nil {
+		t.Fatal("a struct result resolved the promise")
+	}
+	if len(*reports) != 0 {
+		t.Errorf("conversion failure reported as a panic: %+v", *reports)
+	}
+}
+
+func TestAsyncFuncReportsPanics(t *testing.T) {
+	reports := captureReports(t)
+
+	_, err := callAsync(func(Value, []Value) (interface{}, error) {
+		panic("lost connection")
+	})
+	if err == nil {
+		t.Fatal("a panic resolved the promise")
+	}
+	if len(*reports) != 1 || (*reports)[0].Source != "async function" {
+		t.Errorf("reports = %+v, want one from the async function", *reports)
+	}
+}
+
+func TestAwaitPassesOtherValuesThrough(t *testing.T) {
+	value, err := Await(ValueOf("plain"))
+	if err != nil || value.String() != "plain" {
+		t.Errorf("Await = %v, %v", value, err)
+	}
+}
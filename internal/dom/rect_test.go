@@ -0,0 +1,109 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import "testing"
+
+// resetWindow scrolls the window back and restores its size after the test
+func resetWindow(t *testing.T) *MemoryBackend {
+	t.Helper()
+	memory := backend.(*MemoryBackend)
+	t.Cleanup(func() {
+		GetWindow().ScrollTo(0, 0)
+		memory.SetViewport(1024, 768)
+	})
+	return memory
+}
+
+func TestRectContainsAndIntersects(t *testing.T) {
+	r := Rect{X: 10, Y: 10, Width: 20, Height: 20, Top: 10, Right: 30, Bottom: 30, Left: 10}
+
+	if !r.Contains(10, 10) || !r.Contains(29, 29) || r.Contains(30, 20) || r.Contains(20, 5) {
+		t.Error("Contains should include the top left edge and exclude the bottom right one")
+	}
+	if !r.Intersects(Rect{Top: 25, Right: 40, Bottom: 40, Left: 25}) {
+		t.Error("overlapping boxes don't intersect")
+	}
+	if r.Intersects(Rect{Top: 30, Right: 40, Bottom: 40, Left: 10}) {
+		t.Error("touching boxes intersect")
+	}
+}
+
+func TestElementGeometry(t *testing.T) {
+	memory := resetWindow(t)
+	root := newContainer(t)
+	root.SetHTML(`<ul><li></li></ul>`)
+	list, item := root.QuerySelector("ul"), root.QuerySelector("li")
+	memory.SetLayout(list, 10, 20, 100, 50)
+	memory.SetLayout(item, 15, 30, 80, 200)
+
+	want := Rect{X: 10, Y: 20, Width: 100, Height: 50, Top: 20, Right: 110, Bottom: 70, Left: 10}
+	if got := list.GetRect(); got != want {
+		t.Errorf("rect %+v, want %+v", got, want)
+	}
+	if list.OffsetWidth() != 100 || list.ClientHeight() != 50 || item.OffsetTop() != 10 || item.OffsetLeft() != 5 {
+		t.Errorf("offset size %vx%v, item at (%v, %v)", list.OffsetWidth(), list.ClientHeight(), item.OffsetLeft(), item.OffsetTop())
+	}
+	if list.ScrollWidth() != 100 || list.ScrollHeight() != 210 {
+		t.Errorf("scroll size %vx%v, want the item included", list.ScrollWidth(), list.ScrollHeight())
+	}
+}
+
+func TestElementScrolling(t *testing.T) {
+	memory := resetWindow(t)
+	list := newContainer(t)
+	list.SetHTML(`<li></li>`)
+	memory.SetLayout(list, 0, 0, 100, 50)
+	memory.SetLayout(list.QuerySelector("li"), 0, 0, 300, 200)
+	scrolls := 0
+	list.AddEventListener("scroll", func() { scrolls++ })
+
+	list.SetScrollTop(40).SetScrollLeft(500)
+	if list.ScrollTop() != 40 || list.ScrollLeft() != 200 {
+		t.Errorf("scrolled to (%v, %v), want (200, 40)", list.ScrollLeft(), list.ScrollTop())
+	}
+	list.SetScrollTop(40)
+	if scrolls != 2 {
+		t.Errorf("%d scroll events, want one per change", scrolls)
+	}
+}
+
+func TestScrollIntoViewAlignsTheElement(t *testing.T) {
+	memory := resetWindow(t)
+	memory.SetViewport(400, 300)
+	item := newContainer(t)
+	memory.SetLayout(item, 0, 1000, 100, 40)
+
+	item.ScrollIntoView()
+	if GetWindow().ScrollY() != 1000 {
+		t.Errorf("scrolled to %v, want the top aligned", GetWindow().ScrollY())
+	}
+
+	GetWindow().ScrollTo(0, 0)
+	item.ScrollIntoViewWithOptions(ScrollIntoViewOptions{Behavior: "smooth", Block: "center"})
+	if GetWindow().ScrollY() != 870 {
+		t.Errorf("scrolled to %v, want the item centred", GetWindow().ScrollY())
+	}
+
+	GetWindow().ScrollTo(0, 0)
+	item.ScrollIntoViewWithOptions(ScrollIntoViewOptions{Block: "nearest"})
+	if GetWindow().ScrollY() != 740 {
+		t.Errorf("scrolled to %v, want the bottom aligned", GetWindow().ScrollY())
+	}
+}
+
+func TestWindowViewport(t *testing.T) {
+	memory := resetWindow(t)
+	window := GetWindow()
+	memory.SetViewport(400, 300)
+
+	window.ScrollTo(10, 100)
+	window.ScrollBy(5, -150)
+	if window.InnerWidth() != 400 || window.InnerHeight() != 300 || window.DevicePixelRatio() != 1 {
+		t.Errorf("viewport %vx%v at ratio %v", window.InnerWidth(), window.InnerHeight(), window.DevicePixelRatio())
+	}
+	if window.ScrollX() != 15 || window.ScrollY() != 0 {
+		t.Errorf("scrolled to (%v, %v), want (15, 0)", window.ScrollX(), window.ScrollY())
+	}
+}
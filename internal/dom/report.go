@@ -0,0 +1,205 @@
+package dom
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrorReport describes a panic recovered from Go code called by JavaScript,
+// or an error the package could not return to its caller
+type ErrorReport struct {
+	Err    error       // the panic value, converted to an error when it is not one
+	Value  interface{} // the panic value as given to panic, or Err
+	Stack  string      // stack trace of the goroutine that panicked or failed
+	Source string      // what was running: "callback", "async function", "effect", "animation" or "goroutine"
+	Panic  bool        // whether Value was recovered from a panic rather than reported as an error
+}
+
+// Reporter receives the panics recovered and the errors reported by the
+// package
+type Reporter interface {
+	Report(report ErrorReport)
+}
+
+// ReporterFunc adapts a function to the Reporter interface
+type ReporterFunc func(report ErrorReport)
+
+// Report calls f
+func (f ReporterFunc) Report(report ErrorReport) {
+	f(report)
+}
+
+// ConsoleReporter logs the error and its stack trace with console.error
+var ConsoleReporter Reporter = ReporterFunc(func(report ErrorReport) {
+	kind := "error"
+	if report.Panic {
+		kind = "panic"
+	}
+	Global().Get("console").Call("error", fmt.Sprintf("%s in %s: %v\n\n%s", kind, report.Source, report.Err, report.Stack))
+})
+
+// ErrorBanner is a Reporter that shows a dismissible alert at the top of the
+// page, so users learn that something failed rather than facing a page that
+// silently stopped responding. The banner is a div with the class
+// "error-banner" holding a message and a close button, left for the page to
+// style
+type ErrorBanner struct {
+	// Message is shown in place of the error text when set
+	Message string
+}
+
+// Report shows the banner, or updates the one already shown
+func (b ErrorBanner) Report(report ErrorReport) {
+	document := Document()
+
+	banner, ok := document.TryQuerySelector(".error-banner")
+	if !ok {
+		banner = document.CreateElement("div").
+			SetAttribute("class", "error-banner").
+			SetAttribute("role", "alert")
+		banner.AppendChild(document.CreateElement("span").SetAttribute("class", "error-banner-message"))
+
+		dismiss := document.CreateElement("button").
+			SetAttribute("class", "error-banner-close").
+			SetAttribute("aria-label", "Dismiss").
+			SetText("×")
+		dismiss.AddEventListener("click", banner.Remove)
+		banner.AppendChild(dismiss)
+
+		document.QuerySelector("body").AppendChild(banner)
+	}
+
+	message := b.Message
+	if message == "" {
+		message = report.Err.Error()
+	}
+	banner.QuerySelector(".error-banner-message").SetText(message)
+}
+
+// reporter is an entry in the list of reporters. Entries are compared by
+// pointer, since reporters such as ReporterFunc are not comparable
+type reporter struct {
+	Reporter
+}
+
+// reporters receive every recovered panic and reported error, in order
+var reporters = []*reporter{{ConsoleReporter}}
+
+// AddReporter adds r to the reporters of recovered panics and reported
+// errors, after
+// ConsoleReporter and any added before, and returns a function removing it:
+//
+//	dom.AddReporter(dom.ReporterFunc(func(report dom.ErrorReport) {
+//		go sendToErrorTracker(report.Err, report.Stack)
+//	}))
+func AddReporter(r Reporter) (remove func()) {
+	entry := &reporter{r}
+	reporters = append(reporters, entry)
+
+	return func() {
+		for i, e := range reporters {
+			if e == entry {
+				reporters = append(reporters[:i:i], reporters[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SetReporters replaces every reporter, including ConsoleReporter. With no
+// reporters recovered panics and reported errors are dropped
+func SetReporters(rs ...Reporter) {
+	reporters = nil
+	for _, r := range rs {
+		reporters = append(reporters, &reporter{r})
+	}
+}
+
+// Recover reports a panic in progress and stops it. It must be deferred
+// directly, at the top of a goroutine for example:
+//
+//	go func() {
+//		defer dom.Recover()
+//		// ...
+//	}()
+func Recover() {
+	if value := recover(); value != nil {
+		reportPanic(value, "goroutine")
+	}
+}
+
+// Guard runs fn, reporting a panic instead of letting it crash the program.
+// It returns false when fn panicked
+func Guard(fn func()) (ok bool) {
+	defer func() {
+		if value := recover(); value != nil {
+			reportPanic(value, "goroutine")
+			ok = false
+		}
+	}()
+
+	fn()
+	return true
+}
+
+// Go runs fn on a new goroutine guarded by Recover
+func Go(fn func()) {
+	go func() {
+		defer Recover()
+		fn()
+	}()
+}
+
+// guardCallback wraps a callback so a panic is reported and JavaScript
+// receives undefined instead of the runtime crashing
+func guardCallback(fn func(this Value, args []Value) interface{}) func(this Value, args []Value) interface{} {
+	return func(this Value, args []Value) (result interface{}) {
+		defer func() {
+			if value := recover(); value != nil {
+				reportPanic(value, "callback")
+				result = Undefined()
+			}
+		}()
+
+		return fn(this, args)
+	}
+}
+
+// ReportError hands an error that has no caller to return to to every
+// reporter. source names what was running, such as "settings form"
+func ReportError(err error, source string) {
+	notifyReporters(ErrorReport{Err: err, Value: err, Stack: string(debug.Stack()), Source: source})
+}
+
+// reportPanic hands a recovered panic to every reporter
+func reportPanic(value interface{}, source string) error {
+	err, ok := value.(error)
+	if !ok {
+		err = fmt.Errorf("%v", value)
+	}
+
+	notifyReporters(ErrorReport{
+		Err:    err,
+		Value:  value,
+		Stack:  string(debug.Stack()),
+		Source: source,
+		Panic:  true,
+	})
+	return err
+}
+
+// notifyReporters delivers r to every reporter
+func notifyReporters(r ErrorReport) {
+	for _, entry := range append([]*reporter(nil), reporters...) {
+		deliverReport(entry.Reporter, r)
+	}
+}
+
+// deliverReport calls a reporter, ignoring a panic from the reporter itself
+// so one faulty reporter cannot stop the others
+func deliverReport(reporter Reporter, report ErrorReport) {
+	defer func() {
+		recover()
+	}()
+	reporter.Report(report)
+}
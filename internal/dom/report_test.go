@@ -0,0 +1,88 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCallbackPanicReachesEveryReporter(t *testing.T) {
+	first := captureReports(t)
+	var second []ErrorReport
+	AddReporter(ReporterFunc(func(report ErrorReport) { second = append(second, report) }))
+
+	fn := FuncOf(func(Value, []Value) interface{} { panic("broken handler") })
+	defer fn.Release()
+	if result := fn.Invoke(); !result.IsUndefined() {
+		t.Errorf("callback returned %v after panicking, want undefined", result)
+	}
+
+	for _, reports := range [][]ErrorReport{*first, second} {
+		if len(reports) != 1 || reports[0].Source != "callback" || !reports[0].Panic || reports[0].Err.Error() != "broken handler" {
+			t.Errorf("reports = %+v, want the callback panic", reports)
+		}
+	}
+}
+
+func TestAddReporterRemove(t *testing.T) {
+	captureReports(t)
+	added := 0
+	remove := AddReporter(ReporterFunc(func(ErrorReport) { added++ }))
+
+	ReportError(errors.New("first"), "test")
+	remove()
+	remove()
+	ReportError(errors.New("second"), "test")
+
+	if added != 1 || len(reporters) != 1 {
+		t.Errorf("removed reporter ran %d times, %d reporters left", added, len(reporters))
+	}
+}
+
+func TestConsoleReporterTellsErrorsFromPanics(t *testing.T) {
+	saved := reporters
+	t.Cleanup(func() { reporters = saved })
+	SetReporters(ConsoleReporter)
+	memory := backend.(*MemoryBackend)
+	memory.Console = nil
+
+	ReportError(errors.New("unknown preset"), "animation")
+	Guard(func() { panic("nil map") })
+
+	if len(memory.Console) != 2 ||
+		!strings.HasPrefix(memory.Console[0], "error: error in animation: unknown preset") ||
+		!strings.HasPrefix(memory.Console[1], "error: panic in goroutine: nil map") {
+		t.Errorf("console = %q", memory.Console)
+	}
+}
+
+func TestErrorBannerReusesTheBanner(t *testing.T) {
+	saved := reporters
+	t.Cleanup(func() { reporters = saved })
+	SetReporters(ErrorBanner{})
+	body := Document().QuerySelector("body")
+	t.Cleanup(func() {
+		for _, banner := range body.QuerySelectorAll(".error-banner") {
+			banner.Remove()
+		}
+	})
+
+	ReportError(errors.New("first"), "test")
+	ReportError(errors.New("second"), "test")
+
+	banners := body.QuerySelectorAll(".error-banner")
+	if len(banners) != 1 {
+		t.Fatalf("%d banners shown, want 1", len(banners))
+	}
+	if got := banners[0].QuerySelector(".error-banner-message").GetText(); got != "second" {
+		t.Errorf("banner says %q, want the latest error", got)
+	}
+
+	banners[0].QuerySelector(".error-banner-close").El.Call("click")
+	if body.QuerySelector(".error-banner").Exists() {
+		t.Error("dismissed banner still shown")
+	}
+}
@@ -0,0 +1,269 @@
+package dom
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RouterMode selects where a Router keeps the current path
+type RouterMode int
+
+const (
+	// HashMode keeps the path in the fragment, as in /#/active. It works
+	// with any static file server
+	HashMode RouterMode = iota
+	// HistoryMode keeps the path in the address itself, as in /active,
+	// using pushState. The server must answer every route with the page
+	HistoryMode
+)
+
+// Params holds the values of the :name segments of a matched route, and
+// the rest of the path under "*" for a route ending in a wildcard
+type Params map[string]string
+
+// RouteHandler is called when its route matches the current path
+type RouteHandler func(params Params)
+
+// route is a registered pattern split into segments
+type route struct {
+	segments []string
+	handler  RouteHandler
+}
+
+// Router maps paths to handlers and keeps the address bar and the back and
+// forward buttons in step with the application. Patterns are matched segment
+// by segment; a segment starting with a colon captures a parameter and a
+// final * captures the rest of the path:
+//
+//	router := dom.NewRouter(dom.HashMode).
+//		Handle("/", showAll).
+//		Handle("/tag/:name", func(params dom.Params) { showTag(params["name"]) })
+//	router.Start()
+//
+//	router.Navigate("/tag/work") // the address becomes /#/tag/work
+//
+// Routes are tried in the order they were registered
+type Router struct {
+	mode     RouterMode
+	base     string
+	routes   []route
+	notFound func(path string)
+	path     string
+	started  bool
+	scope    *Scope
+}
+
+// NewRouter creates a router with no routes
+func NewRouter(mode RouterMode) *Router {
+	return &Router{mode: mode, scope: NewScope()}
+}
+
+// SetBase sets the prefix the application is served under in HistoryMode,
+// such as "/app". Paths given to and reported by the router exclude it
+func (r *Router) SetBase(base string) *Router {
+	r.base = strings.TrimSuffix(base, "/")
+	return r
+}
+
+// Handle registers handler for paths matching pattern
+func (r *Router) Handle(pattern string, handler RouteHandler) *Router {
+	r.routes = append(r.routes, route{segments: splitPath(pattern), handler: handler})
+	return r
+}
+
+// NotFound sets the handler called for paths no route matches
+func (r *Router) NotFound(handler func(path string)) *Router {
+	r.notFound = handler
+	return r
+}
+
+// Start listens for history changes and dispatches the current path
+func (r *Router) Start() *Router {
+	if r.started {
+		return r
+	}
+	r.started = true
+
+	onChange := func(Value) {
+		if path := r.locationPath(); path != r.path {
+			r.dispatch(path)
+		}
+	}
+
+	// Fragment changes typed into the address bar fire hashchange, and in
+	// some browsers only that
+	r.scope.Listen(newListener(Global(), "popstate", onChange))
+	if r.mode == HashMode {
+		r.scope.Listen(newListener(Global(), "hashchange", onChange))
+	}
+
+	r.dispatch(r.locationPath())
+	return r
+}
+
+// Stop removes the router's listeners
+func (r *Router) Stop() {
+	r.scope.Release()
+	r.scope = NewScope()
+	r.started = false
+}
+
+// Path returns the path that was last dispatched
+func (r *Router) Path() string {
+	if !r.started {
+		return r.locationPath()
+	}
+	return r.path
+}
+
+// Navigate adds a history entry for path and dispatches it. Navigating to
+// the current path does nothing
+func (r *Router) Navigate(path string) {
+	path = cleanPath(path)
+	if r.started && path == r.path {
+		return
+	}
+
+	Global().Get("history").Call("pushState", nil, "", r.Href(path))
+	r.dispatch(path)
+}
+
+// Replace dispatches path in place of the current history entry
+func (r *Router) Replace(path string) {
+	path = cleanPath(path)
+	Global().Get("history").Call("replaceState", nil, "", r.Href(path))
+	r.dispatch(path)
+}
+
+// Back goes to the previous history entry
+func (r *Router) Back() {
+	Global().Get("history").Call("back")
+}
+
+// Forward goes to the next history entry
+func (r *Router) Forward() {
+	Global().Get("history").Call("forward")
+}
+
+// Href returns the address to use in a link to path
+func (r *Router) Href(path string) string {
+	path = cleanPath(path)
+	if r.mode == HashMode {
+		return "#" + path
+	}
+	return r.base + path
+}
+
+// InterceptLinks makes clicks on links to application paths inside root
+// navigate with the router instead of loading a page. Only HistoryMode needs
+// it, since fragment links already update the path in HashMode
+func (r *Router) InterceptLinks(root Element) *Listener {
+	return root.On("click", "a[href]", func(link Element, event Event) {
+		mouse := event.Mouse()
+		if r.mode != HistoryMode || mouse.Button() != 0 || mouse.CtrlKey() || mouse.MetaKey() || mouse.ShiftKey() || mouse.AltKey() {
+			return
+		}
+		if target := link.GetAttribute("target"); target != "" && target != "_self" {
+			return
+		}
+
+		href := link.GetAttribute("href")
+		if !strings.HasPrefix(href, "/") || strings.HasPrefix(href, "//") {
+			return
+		}
+		if r.base != "" {
+			if href != r.base && !strings.HasPrefix(href, r.base+"/") {
+				return
+			}
+			href = strings.TrimPrefix(href, r.base)
+		}
+
+		event.PreventDefault()
+		r.Navigate(href)
+	})
+}
+
+// locationPath reads the path from the address bar
+func (r *Router) locationPath() string {
+	location := Global().Get("location")
+	if r.mode == HashMode {
+		return cleanPath(strings.TrimPrefix(location.Get("hash").String(), "#"))
+	}
+
+	path := location.Get("pathname").String()
+	if r.base != "" {
+		path = strings.TrimPrefix(path, r.base)
+	}
+	return cleanPath(path)
+}
+
+// dispatch calls the handler of the first route matching path
+func (r *Router) dispatch(path string) {
+	r.path = path
+
+	segments := splitPath(path)
+	for _, route := range r.routes {
+		if params, ok := matchRoute(route.segments, segments); ok {
+			route.handler(params)
+			return
+		}
+	}
+
+	if r.notFound != nil {
+		r.notFound(path)
+	}
+}
+
+// matchRoute matches path segments against pattern segments
+func matchRoute(pattern, segments []string) (Params, bool) {
+	params := Params{}
+
+	for i, part := range pattern {
+		if part == "*" && i == len(pattern)-1 {
+			params["*"] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if strings.HasPrefix(part, ":") {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			params[part[1:]] = value
+		} else if part != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, len(pattern) == len(segments)
+}
+
+// cleanPath drops the query string and trailing slash and makes the path
+// absolute
+func cleanPath(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// splitPath splits a clean path into its segments; the root has none
+func splitPath(path string) []string {
+	path = strings.Trim(cleanPath(path), "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,138 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"reflect"
+	"testing"
+)
+
+// startRouter starts a router in mode under base at the page address rawURL,
+// logging the routes it dispatches as "pattern params"
+func startRouter(t *testing.T, mode RouterMode, base, rawURL string) (*Router, *[]string) {
+	t.Helper()
+	backend.(*MemoryBackend).SetLocation(rawURL)
+
+	var log []string
+	handle := func(pattern string) RouteHandler {
+		return func(params Params) {
+			if len(params) == 0 {
+				log = append(log, pattern)
+				return
+			}
+			log = append(log, pattern+" "+params["name"]+params["*"])
+		}
+	}
+
+	r := NewRouter(mode).
+		SetBase(base).
+		Handle("/", handle("/")).
+		Handle("/tag/:name", handle("/tag/:name")).
+		Handle("/files/*", handle("/files/*")).
+		NotFound(func(path string) { log = append(log, "not found "+path) })
+	t.Cleanup(r.Stop)
+	r.Start()
+	return r, &log
+}
+
+func TestRouterMatchesRoutes(t *testing.T) {
+	r, log := startRouter(t, HashMode, "", "http://localhost/#/tag/work")
+
+	r.Navigate("/files/a/b/")
+	r.Navigate("/tag/caf%C3%A9?sort=1")
+	r.Navigate("/tag")
+	r.Navigate("/tag/")
+
+	want := []string{"/tag/:name work", "/files/* a/b", "/tag/:name café", "not found /tag"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("dispatched %q, want %q", *log, want)
+	}
+}
+
+func TestRouterHashMode(t *testing.T) {
+	r, log := startRouter(t, HashMode, "", "http://localhost/")
+
+	r.Navigate("/tag/home")
+	if got := Global().Get("location").Get("hash").String(); got != "#/tag/home" {
+		t.Errorf("hash = %q", got)
+	}
+	if r.Href("tag/x/") != "#/tag/x" {
+		t.Errorf("Href = %q", r.Href("tag/x/"))
+	}
+
+	// A fragment typed into the address bar
+	Global().Get("location").Set("hash", "#/files/notes")
+	runFrames()
+	if r.Path() != "/files/notes" {
+		t.Errorf("path after hash change = %q", r.Path())
+	}
+
+	r.Back()
+	runFrames()
+	want := []string{"/", "/tag/:name home", "/files/* notes", "/tag/:name home"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("dispatched %q, want %q", *log, want)
+	}
+}
+
+func TestRouterHistoryMode(t *testing.T) {
+	r, log := startRouter(t, HistoryMode, "/app/", "http://localhost/app/tag/a")
+
+	r.Navigate("/tag/b")
+	if got := Global().Get("location").Get("pathname").String(); got != "/app/tag/b" {
+		t.Errorf("pathname = %q", got)
+	}
+	r.Replace("/")
+	r.Back()
+	runFrames()
+
+	want := []string{"/tag/:name a", "/tag/:name b", "/", "/tag/:name a"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("dispatched %q, want %q", *log, want)
+	}
+}
+
+func TestRouterNavigateToTheCurrentPathDoesNothing(t *testing.T) {
+	r, log := startRouter(t, HashMode, "", "http://localhost/#/tag/a")
+	history := Global().Get("history")
+	length := history.Get("length").Int()
+
+	r.Navigate("/tag/a/")
+	if len(*log) != 1 {
+		t.Errorf("dispatched %q, want the start only", *log)
+	}
+	if got := history.Get("length").Int(); got != length {
+		t.Errorf("history grew from %d to %d entries", length, got)
+	}
+}
+
+func TestRouterInterceptsLinks(t *testing.T) {
+	r, log := startRouter(t, HistoryMode, "", "http://localhost/")
+	root := newContainer(t)
+	root.SetHTML(`<a href="/tag/link">in app</a><a href="https://example.com/tag/x">away</a><a href="/tag/new" target="_blank">new tab</a>`)
+	r.InterceptLinks(root)
+
+	for _, link := range root.QuerySelectorAll("a") {
+		link.El.Call("click")
+	}
+
+	want := []string{"/", "/tag/:name link"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("dispatched %q, want %q", *log, want)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"":           "/",
+		"/":          "/",
+		"active":     "/active",
+		"/active///": "/active",
+		"/a?b=c#d":   "/a",
+	} {
+		if got := cleanPath(path); got != want {
+			t.Errorf("cleanPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
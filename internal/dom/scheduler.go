@@ -0,0 +1,168 @@
+package dom
+
+// Scheduler batches DOM work into animation frames. Work is queued in two
+// phases: reads, which measure layout (GetRect, offset sizes, scroll
+// positions), and writes, which change the DOM. Each frame runs every queued
+// read before any write so that the browser computes layout at most once,
+// instead of every time a measurement follows a change:
+//
+//	dom.ScheduleRead(func() {
+//		rect := list.GetRect()
+//		dom.ScheduleWrite(func() { ghost.Style().Top(...) })
+//	})
+//
+// Views that need re-rendering are marked with Invalidate and updated once
+// in the write phase, however many times they were marked during the frame.
+type Scheduler struct {
+	reads   []func()
+	writes  []func()
+	dirty   []Updater
+	marked  map[Updater]struct{}
+	frame   *Timer
+	running bool
+}
+
+// Updater is a view that can re-render itself. Components implement it
+// through Base
+type Updater interface {
+	Update()
+}
+
+// NewScheduler creates a scheduler with nothing queued
+func NewScheduler() *Scheduler {
+	return &Scheduler{marked: map[Updater]struct{}{}}
+}
+
+// defaultScheduler backs the package level scheduling functions
+var defaultScheduler = NewScheduler()
+
+// ScheduleRead queues fn for the read phase of the next frame
+func ScheduleRead(fn func()) {
+	defaultScheduler.Read(fn)
+}
+
+// ScheduleWrite queues fn for the write phase of the next frame
+func ScheduleWrite(fn func()) {
+	defaultScheduler.Write(fn)
+}
+
+// Invalidate marks view as needing an update in the next frame
+func Invalidate(view Updater) {
+	defaultScheduler.Invalidate(view)
+}
+
+// FlushFrame runs the work queued for the next frame right away
+func FlushFrame() {
+	defaultScheduler.Flush()
+}
+
+// Read queues fn for the read phase. fn must not change the DOM; queue the
+// changes with Write instead. Reads queued from a write run in the next frame
+func (s *Scheduler) Read(fn func()) {
+	s.reads = append(s.reads, fn)
+	s.request()
+}
+
+// Write queues fn for the write phase. Writes queued from a read run in the
+// same frame, writes queued from a write in the next one
+func (s *Scheduler) Write(fn func()) {
+	s.writes = append(s.writes, fn)
+	s.request()
+}
+
+// Invalidate marks view for an update in the write phase. Marking a view
+// that is already waiting for its update does nothing
+func (s *Scheduler) Invalidate(view Updater) {
+	if view == nil {
+		return
+	}
+	if _, ok := s.marked[view]; ok {
+		return
+	}
+
+	s.marked[view] = struct{}{}
+	s.dirty = append(s.dirty, view)
+	s.request()
+}
+
+// Pending reports whether any work is waiting for a frame
+func (s *Scheduler) Pending() bool {
+	return len(s.reads) > 0 || len(s.writes) > 0 || len(s.dirty) > 0
+}
+
+// Flush runs the queued work now instead of waiting for the frame
+func (s *Scheduler) Flush() {
+	if s.running {
+		return
+	}
+
+	s.frame.Clear()
+	s.frame = nil
+	s.run()
+}
+
+// Cancel drops every queued read, write and update
+func (s *Scheduler) Cancel() {
+	s.frame.Clear()
+	s.frame = nil
+	s.reads = nil
+	s.writes = nil
+	s.dirty = nil
+	s.marked = map[Updater]struct{}{}
+}
+
+// request asks for an animation frame unless one is already pending or the
+// scheduler is flushing, in which case run picks the work up
+func (s *Scheduler) request() {
+	if s.running || s.frame.Active() {
+		return
+	}
+
+	s.frame = GetWindow().RequestAnimationFrame(func(float64) {
+		s.frame = nil
+		s.run()
+	})
+}
+
+// run performs one frame: all reads, then all writes and view updates. When
+// one of them panics the rest of the frame is dropped, and the scheduler
+// still takes new work afterwards
+func (s *Scheduler) run() {
+	s.running = true
+	finished := false
+	defer func() {
+		s.running = false
+		if !finished {
+			s.reads, s.writes, s.dirty = nil, nil, nil
+			s.marked = map[Updater]struct{}{}
+		}
+	}()
+
+	// Reads may queue more reads and writes for this frame
+	for len(s.reads) > 0 {
+		reads := s.reads
+		s.reads = nil
+		for _, fn := range reads {
+			fn()
+		}
+	}
+
+	writes, dirty := s.writes, s.dirty
+	s.writes, s.dirty = nil, nil
+	s.marked = map[Updater]struct{}{}
+
+	for _, fn := range writes {
+		fn()
+	}
+	for _, view := range dirty {
+		view.Update()
+	}
+
+	finished = true
+	s.running = false
+
+	// Work queued by the writes goes to the next frame
+	if s.Pending() {
+		s.request()
+	}
+}
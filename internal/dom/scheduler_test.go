@@ -0,0 +1,103 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import "testing"
+
+// view counts its updates and panics on update while broken is set
+type view struct {
+	updates int
+	broken  bool
+}
+
+func (v *view) Update() {
+	v.updates++
+	if v.broken {
+		panic("broken view")
+	}
+}
+
+// runFrames runs the animation frames and timers waiting on the memory backend
+func runFrames() {
+	backend.(*MemoryBackend).RunTimers()
+}
+
+func TestSchedulerRunsReadsBeforeWrites(t *testing.T) {
+	s := NewScheduler()
+	var order []string
+
+	s.Write(func() { order = append(order, "write") })
+	s.Read(func() {
+		order = append(order, "read")
+		s.Write(func() { order = append(order, "write from read") })
+	})
+	s.Flush()
+
+	want := []string{"read", "write", "write from read"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestSchedulerUpdatesInvalidatedViewsOncePerFrame(t *testing.T) {
+	s := NewScheduler()
+	v := &view{}
+
+	s.Invalidate(v)
+	s.Invalidate(v)
+	if !s.Pending() {
+		t.Fatal("nothing pending after Invalidate")
+	}
+	runFrames()
+
+	if v.updates != 1 {
+		t.Errorf("view updated %d times, want 1", v.updates)
+	}
+	if s.Pending() {
+		t.Error("work still pending after the frame")
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := NewScheduler()
+	v := &view{}
+
+	s.Invalidate(v)
+	s.Cancel()
+	runFrames()
+
+	if v.updates != 0 {
+		t.Errorf("cancelled view updated %d times", v.updates)
+	}
+}
+
+func TestSchedulerKeepsRunningAfterAPanic(t *testing.T) {
+	reports := captureReports(t)
+	s := NewScheduler()
+	bad, good := &view{broken: true}, &view{}
+
+	s.Invalidate(bad)
+	runFrames()
+	if len(*reports) != 1 {
+		t.Fatalf("%d panics reported, want 1", len(*reports))
+	}
+
+	s.Invalidate(good)
+	runFrames()
+	if good.updates != 1 {
+		t.Errorf("view updated %d times after the panic, want 1", good.updates)
+	}
+
+	bad.broken = false
+	s.Invalidate(bad)
+	s.Flush()
+	if bad.updates != 2 {
+		t.Errorf("failed view updated %d times, want 2", bad.updates)
+	}
+}
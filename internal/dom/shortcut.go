@@ -0,0 +1,542 @@
+package dom
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sequenceTimeout is how long in milliseconds a key sequence such as "g a"
+// waits for its next key before starting over
+const sequenceTimeout = 1000
+
+// editableSelector matches the fields where keys type text and are left to
+// the browser unless a shortcut allows them
+const editableSelector = `input:not([type="checkbox"]):not([type="radio"]):not([type="button"]):not([type="submit"]):not([type="reset"]), ` +
+	`textarea, [contenteditable]:not([contenteditable="false"])`
+
+// modifierKeys are pressed on their own on the way to a combination and
+// never end a sequence
+var modifierKeys = map[string]bool{"Shift": true, "Control": true, "Alt": true, "Meta": true, "AltGraph": true, "CapsLock": true}
+
+// keyAliases maps the names accepted in key descriptions to KeyboardEvent.key
+// values, lower-cased
+var keyAliases = map[string]string{
+	"esc":   "escape",
+	"up":    "arrowup",
+	"down":  "arrowdown",
+	"left":  "arrowleft",
+	"right": "arrowright",
+	"del":   "delete",
+	"space": " ",
+	"plus":  "+",
+}
+
+// Shortcut is a key binding
+type Shortcut struct {
+	// Keys describes the keys to press: a combination of modifiers and a key
+	// joined by "+", such as "mod+a", "shift+enter" or "?", or several of them
+	// separated by spaces for a sequence pressed one after the other, such as
+	// "g a". "mod" stands for Cmd on Apple devices and Ctrl elsewhere
+	Keys string
+	// Description is shown in the help overlay. Shortcuts without one are
+	// left out of it
+	Description string
+	// Group is the heading the shortcut is listed under in the help overlay,
+	// "General" by default
+	Group string
+	// Scope is the context the shortcut belongs to. A scoped shortcut only
+	// works while its scope is the current one and then takes precedence over
+	// a global shortcut, one without a scope, bound to the same keys
+	Scope string
+	// AllowInInputs lets the shortcut work while typing in a text field,
+	// where keys are otherwise left to the field
+	AllowInInputs bool
+	// Handler is called when the keys are pressed. A shortcut without one is
+	// only listed in the help overlay, for keys handled elsewhere
+	Handler func(event Event)
+
+	chords []chord
+}
+
+// chord is a key pressed together with modifiers
+type chord struct {
+	ctrl, alt, shift, meta bool
+	key                    string // KeyboardEvent.key, lower-cased
+	code                   string // KeyboardEvent.code, only set for pressed keys
+}
+
+// ShortcutManager dispatches keyboard shortcuts declared up front, and lists
+// them in a help overlay:
+//
+//	shortcuts := dom.NewShortcutManager()
+//	shortcuts.Bind("mod+a", "Mark all todos", markAll)
+//	shortcuts.Bind("g a", "Show all todos", showAll)
+//	shortcuts.Bind("?", "Show keyboard shortcuts", func(dom.Event) { shortcuts.ToggleHelp() })
+//
+// Keys pressed in text fields are left to the field, so Ctrl+A still selects
+// its text, and keys another handler already called PreventDefault for are
+// ignored. Scopes switch shortcuts on and off with the state of the page,
+// such as a list that is being edited
+type ShortcutManager struct {
+	shortcuts []*Shortcut
+	scopes    []string // entered scopes, the current one last
+	pending   []chord  // keys of an unfinished sequence
+	timer     *Timer   // resets pending
+	mac       bool
+	scope     *Scope
+
+	help     Element // overlay, empty when closed
+	helpTrap *FocusTrap
+}
+
+// NewShortcutManager creates a manager listening for keys on the window
+func NewShortcutManager() *ShortcutManager {
+	m := &ShortcutManager{mac: isApple(), scope: NewScope()}
+	m.scope.Listen(newListener(Global(), "keydown", func(value Value) {
+		m.keydown(Event{EventObj: value})
+	}))
+	return m
+}
+
+// isApple reports whether the browser runs on a Mac, iPhone or iPad, where
+// Cmd takes the role Ctrl has elsewhere
+func isApple() bool {
+	navigator := Global().Get("navigator")
+	if isNullish(navigator) {
+		return false
+	}
+	platform := navigator.Get("platform")
+	if platform.Type() != TypeString {
+		return false
+	}
+	return strings.HasPrefix(platform.String(), "Mac") || strings.HasPrefix(platform.String(), "iP")
+}
+
+// Bind adds a global shortcut calling handler when keys are pressed
+func (m *ShortcutManager) Bind(keys, description string, handler func(Event)) *ShortcutManager {
+	return m.Add(Shortcut{Keys: keys, Description: description, Handler: handler})
+}
+
+// Add adds a shortcut. Shortcuts with the same keys and scope as one added
+// before replace it
+func (m *ShortcutManager) Add(shortcut Shortcut) *ShortcutManager {
+	shortcut.chords = m.parse(shortcut.Keys)
+	if len(shortcut.chords) == 0 {
+		return m
+	}
+	m.Remove(shortcut.Keys, shortcut.Scope)
+	m.shortcuts = append(m.shortcuts, &shortcut)
+	return m
+}
+
+// Remove removes the shortcut bound to keys in scope, "" for a global one
+func (m *ShortcutManager) Remove(keys, scope string) {
+	chords := m.parse(keys)
+	for i, shortcut := range m.shortcuts {
+		if shortcut.Scope == scope && sameChords(shortcut.chords, chords) {
+			m.shortcuts = append(m.shortcuts[:i:i], m.shortcuts[i+1:]...)
+			return
+		}
+	}
+}
+
+// Shortcuts returns the shortcuts in the order they were added
+func (m *ShortcutManager) Shortcuts() []Shortcut {
+	shortcuts := make([]Shortcut, len(m.shortcuts))
+	for i, shortcut := range m.shortcuts {
+		shortcuts[i] = *shortcut
+	}
+	return shortcuts
+}
+
+// PushScope makes name the current scope until it is popped
+func (m *ShortcutManager) PushScope(name string) {
+	m.scopes = append(m.scopes, name)
+	m.pending = nil
+}
+
+// PopScope leaves the scope name, going back to the one current before it
+// was pushed. Nothing happens when name was not pushed
+func (m *ShortcutManager) PopScope(name string) {
+	for i := len(m.scopes) - 1; i >= 0; i-- {
+		if m.scopes[i] == name {
+			m.scopes = append(m.scopes[:i:i], m.scopes[i+1:]...)
+			m.pending = nil
+			return
+		}
+	}
+}
+
+// Scope returns the current scope, "" when none was pushed
+func (m *ShortcutManager) Scope() string {
+	if len(m.scopes) == 0 {
+		return ""
+	}
+	return m.scopes[len(m.scopes)-1]
+}
+
+// Label returns how keys are written for the user on this platform, such as
+// "Ctrl+A", or "⌘A" on a Mac
+func (m *ShortcutManager) Label(keys string) string {
+	separator := "+"
+	if m.mac {
+		separator = ""
+	}
+
+	var labels []string
+	for _, pressed := range m.parse(keys) {
+		labels = append(labels, strings.Join(m.chordLabel(pressed), separator))
+	}
+	return strings.Join(labels, " then ")
+}
+
+// Release closes the help overlay and stops listening for keys
+func (m *ShortcutManager) Release() {
+	m.HideHelp()
+	m.timer.Clear()
+	m.scope.Release()
+}
+
+// parse turns a key description into chords. "mod" becomes Cmd or Ctrl
+// depending on the platform
+func (m *ShortcutManager) parse(keys string) []chord {
+	var chords []chord
+	for _, combination := range strings.Fields(strings.ToLower(keys)) {
+		var pressed chord
+
+		parts := strings.Split(combination, "+")
+		if strings.HasSuffix(combination, "++") {
+			parts = append(parts[:len(parts)-2], "+")
+		}
+		for _, part := range parts[:len(parts)-1] {
+			switch part {
+			case "mod":
+				pressed.meta = pressed.meta || m.mac
+				pressed.ctrl = pressed.ctrl || !m.mac
+			case "ctrl", "control":
+				pressed.ctrl = true
+			case "alt", "option":
+				pressed.alt = true
+			case "shift":
+				pressed.shift = true
+			case "meta", "cmd", "command":
+				pressed.meta = true
+			}
+		}
+
+		pressed.key = parts[len(parts)-1]
+		if alias, ok := keyAliases[pressed.key]; ok {
+			pressed.key = alias
+		}
+		if pressed.key == "" {
+			return nil
+		}
+		chords = append(chords, pressed)
+	}
+	return chords
+}
+
+// sameChords reports whether a and b describe the same keys
+func sameChords(a, b []chord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether pressed is the chord c. Shift is not compared for
+// symbols such as "?", which layouts reach with or without it. Letters and
+// digits also match by physical key, since Alt changes the character typed
+// on a Mac
+func (c chord) matches(pressed chord) bool {
+	if c.ctrl != pressed.ctrl || c.alt != pressed.alt || c.meta != pressed.meta {
+		return false
+	}
+	if c.shift != pressed.shift && !isSymbol(c.key) {
+		return false
+	}
+	if c.key == pressed.key {
+		return true
+	}
+
+	if runes := []rune(c.key); len(runes) == 1 {
+		switch {
+		case unicode.IsLetter(runes[0]):
+			return pressed.code == "Key"+strings.ToUpper(c.key)
+		case unicode.IsDigit(runes[0]):
+			return pressed.code == "Digit"+c.key
+		}
+	}
+	return false
+}
+
+// isSymbol reports whether key is a single printable character other than a
+// letter, a digit or a space
+func isSymbol(key string) bool {
+	runes := []rune(key)
+	return len(runes) == 1 && !unicode.IsLetter(runes[0]) && !unicode.IsDigit(runes[0]) && !unicode.IsSpace(runes[0])
+}
+
+// keydown runs the shortcut completed by the key, or waits for the rest of a
+// sequence
+func (m *ShortcutManager) keydown(event Event) {
+	keyboard := event.Keyboard()
+	if event.DefaultPrevented() || keyboard.IsComposing() || modifierKeys[keyboard.Key()] {
+		return
+	}
+
+	pressed := chord{
+		ctrl:  keyboard.CtrlKey(),
+		alt:   keyboard.AltKey(),
+		shift: keyboard.ShiftKey(),
+		meta:  keyboard.MetaKey(),
+		key:   strings.ToLower(keyboard.Key()),
+		code:  keyboard.Code(),
+	}
+
+	// The overlay is modal: only the keys closing it work while it is open
+	if m.HelpOpen() {
+		if pressed.key == "escape" || pressed.key == "?" {
+			event.PreventDefault()
+			m.HideHelp()
+		}
+		return
+	}
+
+	editable := isEditable(event.Target(), pressed.key)
+
+	m.timer.Clear()
+	m.pending = append(m.pending, pressed)
+	shortcut, waiting := m.match(editable)
+	if shortcut == nil && !waiting && len(m.pending) > 1 {
+		// The sequence broke off; the key may start another one
+		m.pending = []chord{pressed}
+		shortcut, waiting = m.match(editable)
+	}
+
+	switch {
+	case shortcut != nil:
+		m.pending = nil
+		event.PreventDefault()
+		shortcut.Handler(event)
+	case waiting:
+		m.timer = GetWindow().SetTimeout(func() { m.pending = nil }, sequenceTimeout)
+	default:
+		m.pending = nil
+	}
+}
+
+// isEditable reports whether key pressed on target is left to a form field:
+// any key in a text field, and any key but Escape in a select, where letters
+// and arrows pick an option
+func isEditable(target Element, key string) bool {
+	if !target.Exists() {
+		return false
+	}
+	nodeType := target.El.Get("nodeType")
+	if nodeType.Type() != TypeNumber || nodeType.Int() != 1 {
+		return false
+	}
+	return target.Closest(editableSelector).Exists() || (key != "escape" && target.Closest("select").Exists())
+}
+
+// match returns the shortcut whose keys are the pending ones, preferring the
+// current scope over global shortcuts, and whether a longer sequence may
+// still match
+func (m *ShortcutManager) match(editable bool) (*Shortcut, bool) {
+	current := m.Scope()
+
+	var global *Shortcut
+	waiting := false
+	for _, shortcut := range m.shortcuts {
+		if shortcut.Handler == nil || (shortcut.Scope != "" && shortcut.Scope != current) || (editable && !shortcut.AllowInInputs) {
+			continue
+		}
+		if len(shortcut.chords) < len(m.pending) || !shortcut.startsWith(m.pending) {
+			continue
+		}
+
+		switch {
+		case len(shortcut.chords) > len(m.pending):
+			waiting = true
+		case shortcut.Scope != "":
+			return shortcut, false
+		case global == nil:
+			global = shortcut
+		}
+	}
+	return global, global == nil && waiting
+}
+
+// startsWith reports whether the first chords of the shortcut match pressed
+func (s *Shortcut) startsWith(pressed []chord) bool {
+	for i := range pressed {
+		if !s.chords[i].matches(pressed[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// chordLabel returns the labels of the modifiers and the key of c, using the
+// Apple symbols on a Mac
+func (m *ShortcutManager) chordLabel(c chord) []string {
+	var labels []string
+	if m.mac {
+		if c.ctrl {
+			labels = append(labels, "⌃")
+		}
+		if c.alt {
+			labels = append(labels, "⌥")
+		}
+		if c.shift {
+			labels = append(labels, "⇧")
+		}
+		if c.meta {
+			labels = append(labels, "⌘")
+		}
+	} else {
+		if c.ctrl {
+			labels = append(labels, "Ctrl")
+		}
+		if c.alt {
+			labels = append(labels, "Alt")
+		}
+		if c.shift {
+			labels = append(labels, "Shift")
+		}
+		if c.meta {
+			labels = append(labels, "Meta")
+		}
+	}
+
+	switch c.key {
+	case "escape":
+		return append(labels, "Esc")
+	case "arrowup":
+		return append(labels, "↑")
+	case "arrowdown":
+		return append(labels, "↓")
+	case "arrowleft":
+		return append(labels, "←")
+	case "arrowright":
+		return append(labels, "→")
+	case " ":
+		return append(labels, "Space")
+	}
+	if runes := []rune(c.key); len(runes) > 0 {
+		return append(labels, strings.ToUpper(string(runes[:1]))+string(runes[1:]))
+	}
+	return labels
+}
+
+// HelpOpen reports whether the help overlay is shown
+func (m *ShortcutManager) HelpOpen() bool {
+	return m.help.Exists()
+}
+
+// ToggleHelp shows the help overlay, or hides it when it is shown
+func (m *ShortcutManager) ToggleHelp() {
+	if m.HelpOpen() {
+		m.HideHelp()
+	} else {
+		m.ShowHelp()
+	}
+}
+
+// ShowHelp opens a dialog listing the shortcuts that have a description,
+// under their group headings. Focus stays in the dialog until Escape, ? or
+// the close button closes it, or the backdrop is clicked
+func (m *ShortcutManager) ShowHelp() {
+	if m.HelpOpen() {
+		return
+	}
+
+	var groups []string
+	rows := map[string][]*VNode{}
+	for _, shortcut := range m.shortcuts {
+		if shortcut.Description == "" {
+			continue
+		}
+		group := shortcut.Group
+		if group == "" {
+			group = "General"
+		}
+		if _, ok := rows[group]; !ok {
+			groups = append(groups, group)
+		}
+		rows[group] = append(rows[group], H("div", Class("shortcut-help-row", true),
+			H("dt", Children(m.keyNodes(shortcut.chords))),
+			H("dd", Text(shortcut.Description)),
+		))
+	}
+
+	sections := []*VNode{
+		H("div", Class("shortcut-help-header", true),
+			H("h2", Attrs{"id": "shortcut-help-title"}, Text("Keyboard shortcuts")),
+			H("button", Attrs{"type": "button", "aria-label": "Close"}, Class("shortcut-help-close", true),
+				On("click", func(Event) { m.HideHelp() }),
+				Text("×"),
+			),
+		),
+	}
+	for _, group := range groups {
+		sections = append(sections, H("section",
+			H("h3", Text(group)),
+			H("dl", Children(rows[group])),
+		))
+	}
+
+	m.help = Document().CreateElement("div")
+	m.help.ClassList().Add("shortcut-help")
+	m.help.Scope().Listen(newListener(m.help.El, "click", func(value Value) {
+		if target := (Event{EventObj: value}).Target(); target.Exists() && target.El.Equal(m.help.El) {
+			m.HideHelp()
+		}
+	}))
+	NewReconciler(m.help).Patch([]*VNode{
+		H("div", Attrs{"role": "dialog", "aria-modal": "true", "aria-labelledby": "shortcut-help-title"},
+			Class("shortcut-help-dialog", true),
+			Children(sections),
+		),
+	})
+	Document().QuerySelector("body").AppendChild(m.help)
+
+	m.helpTrap = NewFocusTrap(m.help.QuerySelector(".shortcut-help-dialog"))
+	m.helpTrap.Activate()
+}
+
+// keyNodes renders chords as kbd elements
+func (m *ShortcutManager) keyNodes(chords []chord) []*VNode {
+	var nodes []*VNode
+	for i, pressed := range chords {
+		if i > 0 {
+			nodes = append(nodes, Text(" then "))
+		}
+		for j, label := range m.chordLabel(pressed) {
+			if j > 0 && !m.mac {
+				nodes = append(nodes, Text("+"))
+			}
+			nodes = append(nodes, H("kbd", Text(label)))
+		}
+	}
+	return nodes
+}
+
+// HideHelp closes the help overlay and gives focus back to the element that
+// had it before
+func (m *ShortcutManager) HideHelp() {
+	if !m.HelpOpen() {
+		return
+	}
+
+	m.helpTrap.Deactivate()
+	m.helpTrap = nil
+	m.help.Remove()
+	m.help = Element{}
+}
@@ -0,0 +1,221 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newShortcuts creates a manager released at the end of the test, on a Mac
+// when mac is set
+func newShortcuts(t *testing.T, mac bool) *ShortcutManager {
+	t.Helper()
+	m := NewShortcutManager()
+	m.mac = mac
+	t.Cleanup(m.Release)
+	return m
+}
+
+// press fires keydown on target for a key description such as "ctrl+a"
+// written with KeyboardEvent.key values, and returns the event
+func press(target Value, keys string) Value {
+	parts := strings.Split(keys, "+")
+	init := map[string]interface{}{"bubbles": true, "cancelable": true, "key": parts[len(parts)-1]}
+	for _, modifier := range parts[:len(parts)-1] {
+		init[modifier+"Key"] = true
+	}
+	if key := init["key"].(string); len(key) == 1 && key >= "a" && key <= "z" {
+		init["code"] = "Key" + strings.ToUpper(key)
+	}
+
+	event := Global().Get("KeyboardEvent").New("keydown", init)
+	target.Call("dispatchEvent", event)
+	return event
+}
+
+// pressAll presses each key of a space separated list on the document
+func pressAll(keys string) {
+	for _, key := range strings.Fields(keys) {
+		press(Global().Get("document"), key)
+	}
+}
+
+func TestShortcutParse(t *testing.T) {
+	pc, mac := newShortcuts(t, false), newShortcuts(t, true)
+
+	for _, test := range []struct {
+		m    *ShortcutManager
+		keys string
+		want []chord
+	}{
+		{pc, "mod+a", []chord{{ctrl: true, key: "a"}}},
+		{mac, "mod+a", []chord{{meta: true, key: "a"}}},
+		{pc, "Ctrl+Shift+Esc", []chord{{ctrl: true, shift: true, key: "escape"}}},
+		{pc, "ctrl++", []chord{{ctrl: true, key: "+"}}},
+		{pc, "alt+plus", []chord{{alt: true, key: "+"}}},
+		{pc, "g  a", []chord{{key: "g"}, {key: "a"}}},
+		{pc, "shift+space", []chord{{shift: true, key: " "}}},
+		{pc, "ctrl+", nil},
+		{pc, "", nil},
+	} {
+		if got := test.m.parse(test.keys); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parse(%q) on mac=%v = %+v, want %+v", test.keys, test.m.mac, got, test.want)
+		}
+	}
+}
+
+func TestShortcutLabel(t *testing.T) {
+	pc, mac := newShortcuts(t, false), newShortcuts(t, true)
+
+	for _, test := range []struct {
+		m          *ShortcutManager
+		keys, want string
+	}{
+		{pc, "mod+shift+a", "Ctrl+Shift+A"},
+		{mac, "mod+shift+a", "⇧⌘A"},
+		{pc, "g a", "G then A"},
+		{pc, "esc", "Esc"},
+		{mac, "alt+up", "⌥↑"},
+		{pc, "?", "?"},
+	} {
+		if got := test.m.Label(test.keys); got != test.want {
+			t.Errorf("Label(%q) on mac=%v = %q, want %q", test.keys, test.m.mac, got, test.want)
+		}
+	}
+}
+
+func TestShortcutDispatch(t *testing.T) {
+	m := newShortcuts(t, false)
+	var ran []string
+	m.Bind("mod+a", "Mark all", func(Event) { ran = append(ran, "mod+a") })
+	m.Bind("?", "Help", func(Event) { ran = append(ran, "?") })
+	m.Bind("alt+n", "New", func(Event) { ran = append(ran, "alt+n") })
+
+	pressAll("a ctrl+a meta+a shift+?")
+	// On a Mac, Alt+N types a dead key; the physical key still matches
+	event := Global().Get("KeyboardEvent").New("keydown", map[string]interface{}{"bubbles": true, "key": "Dead", "code": "KeyN", "altKey": true})
+	Global().Get("document").Call("dispatchEvent", event)
+
+	want := []string{"mod+a", "?", "alt+n"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+}
+
+func TestShortcutSkipsHandledKeys(t *testing.T) {
+	m := newShortcuts(t, false)
+	ran := 0
+	m.Bind("x", "", func(Event) { ran++ })
+
+	root := newContainer(t)
+	root.On("keydown", "button", func(_ Element, event Event) { event.PreventDefault() })
+	root.SetHTML(`<button></button>`)
+
+	if event := press(root.QuerySelector("button").El, "x"); !event.Get("defaultPrevented").Bool() || ran != 0 {
+		t.Errorf("shortcut ran %d times for a handled key", ran)
+	}
+}
+
+func TestShortcutSequences(t *testing.T) {
+	m := newShortcuts(t, false)
+	var ran []string
+	m.Bind("g a", "Show all", func(Event) { ran = append(ran, "g a") })
+	m.Bind("g c", "Show completed", func(Event) { ran = append(ran, "g c") })
+
+	pressAll("g a")
+	pressAll("g g c") // the second g starts over
+	pressAll("g x a")
+
+	press(Global().Get("document"), "g")
+	backend.(*MemoryBackend).Advance(sequenceTimeout * time.Millisecond)
+	press(Global().Get("document"), "a")
+
+	want := []string{"g a", "g c"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+}
+
+func TestShortcutScopes(t *testing.T) {
+	m := newShortcuts(t, false)
+	var ran []string
+	m.Bind("escape", "Close", func(Event) { ran = append(ran, "global") })
+	m.Add(Shortcut{Keys: "esc", Scope: "editing", Handler: func(Event) { ran = append(ran, "editing") }})
+	m.Add(Shortcut{Keys: "s", Scope: "editing", Handler: func(Event) { ran = append(ran, "save") }})
+
+	pressAll("Escape s")
+	m.PushScope("editing")
+	pressAll("Escape s")
+	m.PopScope("editing")
+	pressAll("Escape")
+
+	want := []string{"global", "editing", "save", "global"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	if m.Scope() != "" {
+		t.Errorf("scope %q after popping", m.Scope())
+	}
+}
+
+func TestShortcutLeavesTextFieldsAlone(t *testing.T) {
+	m := newShortcuts(t, false)
+	var ran []string
+	m.Bind("mod+a", "Mark all", func(Event) { ran = append(ran, "mod+a") })
+	m.Add(Shortcut{Keys: "escape", AllowInInputs: true, Handler: func(Event) { ran = append(ran, "escape") }})
+
+	root := newContainer(t)
+	root.SetHTML(`<input type="text"><input type="checkbox"><div contenteditable><b></b></div>`)
+	for _, selector := range []string{`[type="text"]`, "b"} {
+		press(root.QuerySelector(selector).El, "ctrl+a")
+		press(root.QuerySelector(selector).El, "Escape")
+	}
+	press(root.QuerySelector(`[type="checkbox"]`).El, "ctrl+a")
+
+	want := []string{"escape", "escape", "mod+a"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+}
+
+func TestShortcutEscapeLeavesASelect(t *testing.T) {
+	m := newShortcuts(t, false)
+	var ran []string
+	m.Bind("escape", "Close", func(Event) { ran = append(ran, "escape") })
+	m.Bind("x", "Delete", func(Event) { ran = append(ran, "x") })
+
+	root := newContainer(t)
+	root.SetHTML(`<select><option>Blue</option><option>Green</option></select>`)
+	sel := root.QuerySelector("select")
+	sel.El.Call("focus")
+	press(sel.El, "x") // picks an option starting with x
+	press(sel.El, "Escape")
+
+	if !reflect.DeepEqual(ran, []string{"escape"}) {
+		t.Errorf("ran %v, want only escape", ran)
+	}
+}
+
+func TestShortcutHelpOverlay(t *testing.T) {
+	m := newShortcuts(t, false)
+	ran := 0
+	m.Bind("?", "Show keyboard shortcuts", func(Event) { m.ToggleHelp() })
+	m.Bind("x", "Delete", func(Event) { ran++ })
+
+	pressAll("?")
+	if !m.HelpOpen() {
+		t.Fatal("help not shown")
+	}
+	if text := Document().QuerySelector("body").GetText(); !strings.Contains(text, "Delete") {
+		t.Errorf("help lists %q, want the Delete shortcut", text)
+	}
+
+	pressAll("x Escape")
+	if m.HelpOpen() || ran != 0 {
+		t.Errorf("open %v after Escape, x ran %d times", m.HelpOpen(), ran)
+	}
+}
@@ -0,0 +1,251 @@
+package dom
+
+// Reactive state. A Signal holds a value; a Computed derives one from signals
+// and other computed values; an Effect runs a function and runs it again
+// whenever something it read changes. Dependencies are recorded while a
+// computed value or effect runs, so there is nothing to declare:
+//
+//	count := dom.NewSignal(0)
+//	double := dom.NewComputed(func() int { return count.Get() * 2 })
+//	dom.NewEffect(func() { label.SetText(strconv.Itoa(double.Get())) })
+//	count.Set(2) // the effect runs again and shows 4
+//
+// Like the rest of the package this is meant to be used from the event loop
+// goroutine and is not safe for concurrent use.
+
+// observer is a computed value or effect that depends on sources
+type observer interface {
+	// notify is called when a source the observer read has changed
+	notify()
+	// track records that the observer read src
+	track(src *source)
+}
+
+// source is the dependency bookkeeping shared by signals and computed values
+type source struct {
+	observers map[observer]struct{}
+}
+
+// current is the observer whose function is running, if any
+var current observer
+
+// batchDepth counts nested Batch calls; effects are deferred while it is
+// above zero
+var batchDepth int
+
+// pendingEffects are the effects waiting to run when the batch ends
+var pendingEffects []*Effect
+
+// read records that the running observer depends on s
+func (s *source) read() {
+	if current == nil {
+		return
+	}
+	if s.observers == nil {
+		s.observers = map[observer]struct{}{}
+	}
+	s.observers[current] = struct{}{}
+	current.track(s)
+}
+
+// changed notifies every observer of s and runs the effects that became due
+func (s *source) changed() {
+	Batch(func() {
+		for o := range s.observers {
+			o.notify()
+		}
+	})
+}
+
+// Signal is a reactive value
+type Signal[T any] struct {
+	source
+	value T
+}
+
+// NewSignal creates a signal holding initial
+func NewSignal[T any](initial T) *Signal[T] {
+	return &Signal[T]{value: initial}
+}
+
+// Get returns the value and, inside a Computed or Effect, records the dependency
+func (s *Signal[T]) Get() T {
+	s.read()
+	return s.value
+}
+
+// Peek returns the value without recording a dependency
+func (s *Signal[T]) Peek() T {
+	return s.value
+}
+
+// Set stores a new value and updates everything that depends on the signal
+func (s *Signal[T]) Set(value T) {
+	s.value = value
+	s.changed()
+}
+
+// Update sets the value to fn applied to the current value
+func (s *Signal[T]) Update(fn func(T) T) {
+	s.Set(fn(s.value))
+}
+
+// Computed is a value derived from other reactive values. It is evaluated
+// lazily and cached until one of its dependencies changes
+type Computed[T any] struct {
+	source
+	compute func() T
+	value   T
+	dirty   bool
+	deps    []*source
+}
+
+// NewComputed creates a computed value
+func NewComputed[T any](compute func() T) *Computed[T] {
+	return &Computed[T]{compute: compute, dirty: true}
+}
+
+// Get returns the value, recomputing it if a dependency changed, and records
+// the dependency inside a Computed or Effect
+func (c *Computed[T]) Get() T {
+	c.read()
+
+	if c.dirty {
+		untrack(c, c.deps)
+		c.deps = nil
+		c.value = c.evaluate()
+		c.dirty = false
+	}
+
+	return c.value
+}
+
+// evaluate runs the compute function with c as the running observer
+func (c *Computed[T]) evaluate() T {
+	previous := current
+	current = c
+	defer func() { current = previous }()
+
+	return c.compute()
+}
+
+func (c *Computed[T]) notify() {
+	if c.dirty {
+		return
+	}
+	c.dirty = true
+	for o := range c.observers {
+		o.notify()
+	}
+}
+
+func (c *Computed[T]) track(src *source) {
+	c.deps = append(c.deps, src)
+}
+
+// Effect runs a function whenever the reactive values it reads change
+type Effect struct {
+	fn      func()
+	deps    []*source
+	pending bool
+	stopped bool
+}
+
+// NewEffect runs fn now and again after every change to what it read
+func NewEffect(fn func()) *Effect {
+	e := &Effect{fn: fn}
+	e.run()
+	return e
+}
+
+// Stop detaches the effect so it no longer runs
+func (e *Effect) Stop() {
+	e.stopped = true
+	untrack(e, e.deps)
+	e.deps = nil
+}
+
+func (e *Effect) run() {
+	if e.stopped {
+		return
+	}
+
+	untrack(e, e.deps)
+	e.deps = nil
+
+	previous := current
+	current = e
+	defer func() { current = previous }()
+
+	e.fn()
+}
+
+func (e *Effect) notify() {
+	if e.pending || e.stopped {
+		return
+	}
+	e.pending = true
+	pendingEffects = append(pendingEffects, e)
+}
+
+func (e *Effect) track(src *source) {
+	e.deps = append(e.deps, src)
+}
+
+// Batch runs fn and defers effects until it returns, so that several
+// changes cause a single run of each affected effect
+func Batch(fn func()) {
+	batchDepth++
+	defer func() {
+		batchDepth--
+		if batchDepth == 0 {
+			flushEffects()
+		}
+	}()
+
+	fn()
+}
+
+// Untracked runs fn without recording the values it reads as dependencies
+func Untracked(fn func()) {
+	previous := current
+	current = nil
+	defer func() { current = previous }()
+
+	fn()
+}
+
+// flushEffects runs pending effects, including ones queued while flushing
+func flushEffects() {
+	batchDepth++
+	defer func() { batchDepth-- }()
+
+	for len(pendingEffects) > 0 {
+		effects := pendingEffects
+		pendingEffects = nil
+
+		for _, e := range effects {
+			e.pending = false
+			runEffect(e)
+		}
+	}
+}
+
+// runEffect runs a pending effect. A panic is reported rather than passed
+// on, so the effects queued after it still run and run again later
+func runEffect(e *Effect) {
+	defer func() {
+		if value := recover(); value != nil {
+			reportPanic(value, "effect")
+		}
+	}()
+
+	e.run()
+}
+
+// untrack removes o from the observers of each source
+func untrack(o observer, deps []*source) {
+	for _, dep := range deps {
+		delete(dep.observers, o)
+	}
+}
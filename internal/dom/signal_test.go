@@ -0,0 +1,139 @@
+package dom
+
+import "testing"
+
+// captureReports collects the panics reported during the test instead of
+// logging them
+func captureReports(t *testing.T) *[]ErrorReport {
+	t.Helper()
+	saved := reporters
+	t.Cleanup(func() { reporters = saved })
+
+	var reports []ErrorReport
+	SetReporters(ReporterFunc(func(report ErrorReport) {
+		reports = append(reports, report)
+	}))
+	return &reports
+}
+
+func TestComputedIsCachedUntilADependencyChanges(t *testing.T) {
+	count := NewSignal(1)
+	runs := 0
+	double := NewComputed(func() int {
+		runs++
+		return count.Get() * 2
+	})
+
+	if double.Get() != 2 || double.Get() != 2 || runs != 1 {
+		t.Fatalf("value %d after %d runs, want 2 after 1", double.Get(), runs)
+	}
+
+	count.Set(5)
+	if double.Get() != 10 || runs != 2 {
+		t.Errorf("value %d after %d runs, want 10 after 2", double.Get(), runs)
+	}
+}
+
+func TestEffectRunsAfterEachChange(t *testing.T) {
+	name := NewSignal("a")
+	var seen []string
+	effect := NewEffect(func() {
+		seen = append(seen, name.Get())
+	})
+
+	name.Set("b")
+	effect.Stop()
+	name.Set("c")
+
+	if len(seen) != 2 || seen[0] != "a" || seen[1] != "b" {
+		t.Errorf("effect saw %v, want [a b]", seen)
+	}
+}
+
+func TestBatchRunsEffectsOnce(t *testing.T) {
+	a, b := NewSignal(0), NewSignal(0)
+	runs := 0
+	NewEffect(func() {
+		a.Get()
+		b.Get()
+		runs++
+	})
+
+	Batch(func() {
+		a.Set(1)
+		b.Set(1)
+	})
+
+	if runs != 2 {
+		t.Errorf("effect ran %d times, want 2", runs)
+	}
+}
+
+func TestUntrackedReadsAreNotDependencies(t *testing.T) {
+	tracked, ignored := NewSignal(0), NewSignal(0)
+	runs := 0
+	NewEffect(func() {
+		tracked.Get()
+		Untracked(func() { ignored.Get() })
+		runs++
+	})
+
+	ignored.Set(1)
+	if runs != 1 {
+		t.Errorf("effect ran %d times, want 1", runs)
+	}
+}
+
+func TestComputedPanicRestoresTheRunningObserver(t *testing.T) {
+	fail := NewSignal(true)
+	broken := NewComputed(func() int {
+		if fail.Get() {
+			panic("broken")
+		}
+		return 1
+	})
+
+	func() {
+		defer func() { recover() }()
+		broken.Get()
+	}()
+
+	// A read outside any observer must not be recorded against broken
+	other := NewSignal(0)
+	other.Get()
+	if len(other.observers) != 0 {
+		t.Fatal("read after the panic was tracked")
+	}
+
+	fail.Set(false)
+	if broken.Get() != 1 {
+		t.Error("computed did not recover once its dependency changed")
+	}
+}
+
+func TestPanickingEffectDoesNotStopOthers(t *testing.T) {
+	reports := captureReports(t)
+
+	count := NewSignal(0)
+	runs := 0
+	NewEffect(func() {
+		if count.Get() > 0 {
+			panic("failing effect")
+		}
+	})
+	NewEffect(func() {
+		count.Get()
+		runs++
+	})
+
+	for i := 1; i <= 3; i++ {
+		count.Set(i)
+	}
+
+	if runs != 4 {
+		t.Errorf("sibling effect ran %d times, want 4", runs)
+	}
+	if len(*reports) != 3 || (*reports)[0].Source != "effect" {
+		t.Errorf("reports = %+v, want 3 from effects", *reports)
+	}
+}
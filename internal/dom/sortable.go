@@ -0,0 +1,273 @@
+package dom
+
+import "strings"
+
+// SortableOptions configures a Sortable. Empty fields use the defaults
+type SortableOptions struct {
+	// Key is the attribute identifying an item, "data-id" by default
+	Key string
+	// Handle matches the part of an item that starts a drag. The whole item
+	// does when empty
+	Handle string
+	// PlaceholderClass is the class of the element standing in for the item
+	// where it would be dropped, "sortable-placeholder" by default
+	PlaceholderClass string
+	// ScrollContainer is scrolled while dragging near its edges, the window
+	// when empty
+	ScrollContainer Element
+
+	// OnSort is called when an item is dropped at a new place in the
+	// container, or moved there with the keyboard. Indices count the items
+	// matching the selector: fromIndex is where the item was and toIndex
+	// where it is once moved, as if it were removed from the list and then
+	// inserted again
+	OnSort func(fromIndex, toIndex int, key string)
+	// OnReceive is called on the Sortable an item is dropped into from a
+	// linked one. fromIndex is the item's index in from
+	OnReceive func(from *Sortable, fromIndex, toIndex int, key string)
+}
+
+// Sortable lets the user reorder the items of a container by dragging them,
+// with a mouse, pen or finger, or by pressing Alt+ArrowUp and Alt+ArrowDown
+// on a focused item. While dragging, a placeholder shows where the item will
+// land and the item itself is hidden. The DOM is left as it was on drop:
+// the callbacks update the data and the view renders the new order:
+//
+//	dom.NewSortable(list, "li", dom.SortableOptions{
+//		OnSort: func(from, to int, key string) {
+//			item := items[from]
+//			items = append(items[:from], items[from+1:]...)
+//			items = append(items[:to], append([]Item{item}, items[to:]...)...)
+//			render()
+//		},
+//	})
+//
+// Items can be dragged between Sortables joined with Link
+type Sortable struct {
+	container Element
+	selector  string
+	options   SortableOptions
+	draggable *Draggable
+	linked    []*Sortable
+
+	placeholder Element
+	target      *Sortable // Sortable holding the placeholder
+	fromIndex   int
+	display     string // inline display of the item before it was hidden
+}
+
+// NewSortable makes the items of container matching selector sortable
+func NewSortable(container Element, selector string, options SortableOptions) *Sortable {
+	if options.Key == "" {
+		options.Key = "data-id"
+	}
+	if options.PlaceholderClass == "" {
+		options.PlaceholderClass = "sortable-placeholder"
+	}
+
+	s := &Sortable{container: container, selector: selector, options: options}
+	s.draggable = NewDraggable(container, selector, DragOptions{
+		Handle:          options.Handle,
+		ScrollContainer: options.ScrollContainer,
+		OnStart:         s.start,
+		OnMove:          s.move,
+		OnDrop:          s.drop,
+		OnCancel:        func(drag *DragState) { s.end(drag) },
+		OnKeyboardMove:  s.keyboardMove,
+	})
+	return s
+}
+
+// Container returns the element holding the items
+func (s *Sortable) Container() Element {
+	return s.container
+}
+
+// Link lets items be dragged between s and others, in both directions
+func (s *Sortable) Link(others ...*Sortable) {
+	for _, other := range others {
+		if other == s || s.linkedTo(other) {
+			continue
+		}
+		s.linked = append(s.linked, other)
+		other.linked = append(other.linked, s)
+	}
+}
+
+// Items returns the items in the order they are shown, leaving out the
+// placeholder
+func (s *Sortable) Items() []Element {
+	var items []Element
+	for _, item := range s.container.QuerySelectorAll(s.selector) {
+		if !item.ClassList().Contains(s.options.PlaceholderClass) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Release stops the sorting and removes the listeners
+func (s *Sortable) Release() {
+	s.draggable.Release()
+}
+
+// linkedTo reports whether items can be dragged from s to other
+func (s *Sortable) linkedTo(other *Sortable) bool {
+	for _, linked := range s.linked {
+		if linked == other {
+			return true
+		}
+	}
+	return false
+}
+
+// indexOf returns the index of item among the items, or -1
+func (s *Sortable) indexOf(item Element) int {
+	for i, candidate := range s.Items() {
+		if candidate.El.Equal(item.El) {
+			return i
+		}
+	}
+	return -1
+}
+
+// start puts the placeholder in place of the item and hides it
+func (s *Sortable) start(drag *DragState) {
+	item := drag.Item
+	s.fromIndex = s.indexOf(item)
+
+	s.placeholder = Document().CreateElement(strings.ToLower(item.El.Get("tagName").String()))
+	s.placeholder.ClassList().Add(s.options.PlaceholderClass)
+	s.placeholder.SetAttribute("aria-hidden", "true")
+	s.placeholder.Style().SetProperty("height", pixels(item.GetRect().Height))
+	insertBefore(item.El.Get("parentNode"), s.placeholder, item.El)
+	s.target = s
+
+	s.display = item.Style().GetProperty("display")
+	item.Style().Display("none")
+}
+
+// move puts the placeholder before or after the item under the pointer,
+// depending on which half of it the pointer is over. Over a container but
+// not over an item, the placeholder goes to the end of the container
+func (s *Sortable) move(drag *DragState) {
+	target := s.sortableAt(drag.Over)
+	if target == nil || s.placeholder.El.Call("contains", drag.Over.El).Bool() {
+		return
+	}
+	s.target = target
+
+	if over := drag.Over.Closest(target.selector); over.Exists() && target.container.El.Call("contains", over.El).Bool() {
+		rect := over.GetRect()
+		if drag.Y < rect.Top+rect.Height/2 {
+			insertBefore(over.El.Get("parentNode"), s.placeholder, over.El)
+		} else {
+			insertBefore(over.El.Get("parentNode"), s.placeholder, over.El.Get("nextSibling"))
+		}
+		return
+	}
+
+	var last Element
+	for _, item := range target.Items() {
+		if !item.El.Equal(drag.Item.El) {
+			last = item
+		}
+	}
+	if last.Exists() {
+		insertBefore(last.El.Get("parentNode"), s.placeholder, last.El.Get("nextSibling"))
+	} else {
+		insertBefore(target.container.El, s.placeholder, target.container.El.Get("firstChild"))
+	}
+}
+
+// insertBefore moves element into parent before next, or to the end when
+// next is null. Nothing changes when element is already there
+func insertBefore(parent Value, element Element, next Value) {
+	if !isNullish(next) && next.Equal(element.El) {
+		return
+	}
+	parent.Call("insertBefore", element.El, next)
+}
+
+// sortableAt returns s or the linked Sortable whose container holds element
+func (s *Sortable) sortableAt(element Element) *Sortable {
+	if !element.Exists() {
+		return nil
+	}
+
+	for _, candidate := range append([]*Sortable{s}, s.linked...) {
+		if candidate.container.Exists() && candidate.container.El.Call("contains", element.El).Bool() {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// drop reports where the item landed
+func (s *Sortable) drop(drag *DragState) {
+	target := s.target
+	toIndex := s.placeholderIndex(drag.Item)
+	key := drag.Item.GetAttribute(s.options.Key)
+	fromIndex := s.fromIndex
+	s.end(drag)
+
+	switch {
+	case toIndex < 0:
+	case target != s:
+		if target.options.OnReceive != nil {
+			target.options.OnReceive(s, fromIndex, toIndex, key)
+		}
+	case toIndex != fromIndex:
+		if s.options.OnSort != nil {
+			s.options.OnSort(fromIndex, toIndex, key)
+		}
+	}
+}
+
+// placeholderIndex counts the items of the target before the placeholder,
+// leaving out the dragged item
+func (s *Sortable) placeholderIndex(item Element) int {
+	if s.target == nil || !s.placeholder.Exists() {
+		return -1
+	}
+
+	index := 0
+	for _, element := range s.target.container.QuerySelectorAll(s.target.selector + ", ." + s.options.PlaceholderClass) {
+		if element.El.Equal(s.placeholder.El) {
+			return index
+		}
+		if !element.El.Equal(item.El) {
+			index++
+		}
+	}
+	return -1
+}
+
+// end removes the placeholder and shows the item again
+func (s *Sortable) end(drag *DragState) {
+	s.placeholder.Remove()
+	s.placeholder = Element{}
+	s.target = nil
+	drag.Item.Style().Display(s.display)
+}
+
+// keyboardMove moves item by offset within the container and keeps the
+// moved item focused once the view has rendered the new order
+func (s *Sortable) keyboardMove(item Element, offset int) {
+	fromIndex := s.indexOf(item)
+	toIndex := fromIndex + offset
+	if fromIndex < 0 || toIndex < 0 || toIndex >= len(s.Items()) || s.options.OnSort == nil {
+		return
+	}
+
+	key := item.GetAttribute(s.options.Key)
+	s.options.OnSort(fromIndex, toIndex, key)
+
+	FlushFrame()
+	for _, moved := range s.Items() {
+		if moved.GetAttribute(s.options.Key) == key {
+			moved.Focus()
+			return
+		}
+	}
+}
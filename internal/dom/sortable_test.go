@@ -0,0 +1,166 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// sortEvent records a call to OnSort or OnReceive
+type sortEvent struct {
+	from, to int
+	key      string
+}
+
+// newSortList fills a fresh container with one 100x20 row per key, laid out
+// from top, and makes it sortable by a ".grip" handle
+func newSortList(t *testing.T, top float64, keys ...string) (*Sortable, *[]sortEvent) {
+	t.Helper()
+	list := newContainer(t)
+	memory := backend.(*MemoryBackend)
+	memory.SetLayout(list, 0, top, 100, float64(len(keys)*20+20))
+	for i, key := range keys {
+		row := Document().CreateElement("li")
+		row.SetAttribute("data-id", key)
+		row.SetAttribute("tabindex", "0")
+		row.SetHTML(`<span class="grip"></span><button>x</button>`)
+		list.AppendChild(row)
+		memory.SetLayout(row, 0, top+float64(i*20), 100, 20)
+		memory.SetLayout(row.QuerySelector(".grip"), 0, top+float64(i*20), 10, 20)
+	}
+
+	var sorted []sortEvent
+	s := NewSortable(list, "li", SortableOptions{
+		Handle: ".grip",
+		OnSort: func(from, to int, key string) {
+			sorted = append(sorted, sortEvent{from, to, key})
+		},
+	})
+	t.Cleanup(s.Release)
+	return s, &sorted
+}
+
+// pointer fires a pointer event of the given type at x, y on target
+func pointer(target Element, eventType string, x, y float64) {
+	target.El.Call("dispatchEvent", Global().Get("PointerEvent").New(eventType, map[string]interface{}{
+		"bubbles": true, "clientX": x, "clientY": y,
+	}))
+}
+
+// dragRow drags the row with key by its grip to y
+func dragRow(s *Sortable, key string, y float64) {
+	row := s.Container().QuerySelector(fmt.Sprintf(`[data-id="%s"]`, key))
+	rect := row.GetRect()
+	grip := row.QuerySelector(".grip")
+	pointer(grip, "pointerdown", 5, rect.Top+10)
+	pointer(grip, "pointermove", 5, y)
+	pointer(Document().ElementFromPoint(5, y), "pointerup", 5, y)
+}
+
+// keys returns the keys of the items in the order they are shown
+func keys(s *Sortable) []string {
+	var shown []string
+	for _, item := range s.Items() {
+		shown = append(shown, item.GetAttribute("data-id"))
+	}
+	return shown
+}
+
+func TestSortableDragReportsTheNewIndex(t *testing.T) {
+	s, sorted := newSortList(t, 0, "a", "b", "c")
+
+	dragRow(s, "a", 55) // lower half of c
+	dragRow(s, "c", 2)  // upper half of a
+
+	want := []sortEvent{{0, 2, "a"}, {2, 0, "c"}}
+	if !reflect.DeepEqual(*sorted, want) {
+		t.Errorf("sorted %+v, want %+v", *sorted, want)
+	}
+	if !reflect.DeepEqual(keys(s), []string{"a", "b", "c"}) {
+		t.Errorf("items %v, want the DOM left as it was", keys(s))
+	}
+	if s.Container().QuerySelector(".sortable-placeholder").Exists() {
+		t.Error("placeholder left behind")
+	}
+}
+
+func TestSortableShowsAPlaceholderWhileDragging(t *testing.T) {
+	s, sorted := newSortList(t, 0, "a", "b", "c")
+	grip := s.Container().QuerySelector(`[data-id="a"] .grip`)
+
+	pointer(grip, "pointerdown", 5, 10)
+	pointer(grip, "pointermove", 5, 35)
+
+	children := s.Container().El.Get("children")
+	placeholder := Element{El: children.Index(2)}
+	if !placeholder.ClassList().Contains("sortable-placeholder") {
+		t.Errorf("placeholder not after b")
+	}
+	if got := s.Container().QuerySelector(`[data-id="a"]`).Style().GetProperty("display"); got != "none" {
+		t.Errorf("dragged item display %q, want none", got)
+	}
+
+	Global().Get("document").Call("dispatchEvent", Global().Get("KeyboardEvent").New("keydown", map[string]interface{}{"key": "Escape"}))
+	if len(*sorted) != 0 || s.Container().QuerySelector(".sortable-placeholder").Exists() {
+		t.Error("cancelled drag sorted the list or left the placeholder")
+	}
+	if got := s.Container().QuerySelector(`[data-id="a"]`).Style().GetProperty("display"); got != "" {
+		t.Errorf("item display %q after cancelling", got)
+	}
+}
+
+func TestSortableIgnoresPressesOutsideTheHandle(t *testing.T) {
+	s, sorted := newSortList(t, 0, "a", "b")
+	button := s.Container().QuerySelector(`[data-id="a"] button`)
+
+	pointer(button, "pointerdown", 50, 10)
+	pointer(button, "pointermove", 50, 35)
+	pointer(button, "pointerup", 50, 35)
+
+	if len(*sorted) != 0 {
+		t.Errorf("sorted %+v by a press outside the handle", *sorted)
+	}
+}
+
+func TestSortableKeyboardMove(t *testing.T) {
+	s, sorted := newSortList(t, 0, "a", "b", "c")
+
+	press := func(id, key string) {
+		item := s.Container().QuerySelector(fmt.Sprintf(`[data-id="%s"]`, id))
+		item.El.Call("dispatchEvent", Global().Get("KeyboardEvent").New("keydown", map[string]interface{}{
+			"bubbles": true, "altKey": true, "key": key,
+		}))
+	}
+	press("b", "ArrowDown")
+	press("a", "ArrowUp") // already first
+	press("c", "ArrowDown")
+
+	want := []sortEvent{{1, 2, "b"}}
+	if !reflect.DeepEqual(*sorted, want) {
+		t.Errorf("sorted %+v, want %+v", *sorted, want)
+	}
+}
+
+func TestSortableLinkedLists(t *testing.T) {
+	todo, _ := newSortList(t, 0, "a", "b")
+	done, _ := newSortList(t, 100, "c")
+	todo.Link(done)
+
+	var received []sortEvent
+	done.options.OnReceive = func(from *Sortable, fromIndex, toIndex int, key string) {
+		if from != todo {
+			t.Error("OnReceive not given the source list")
+		}
+		received = append(received, sortEvent{fromIndex, toIndex, key})
+	}
+
+	dragRow(todo, "b", 115) // lower half of c
+
+	want := []sortEvent{{1, 1, "b"}}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("received %+v, want %+v", received, want)
+	}
+}
@@ -1,18 +1,14 @@
-//go:build js && wasm
-// +build js,wasm
-
 package dom
 
 import (
 	"encoding/json"
 	"strconv"
-	"syscall/js"
 	"time"
 )
 
 // Storage represents a browser storage object (localStorage or sessionStorage)
 type Storage struct {
-	storageObj js.Value
+	storageObj Value
 }
 
 // StorageEvent represents a storage change event
@@ -32,14 +28,14 @@ var observers = make(map[string][]StorageObserver)
 // LocalStorage returns the browser's localStorage object
 func LocalStorage() Storage {
 	return Storage{
-		storageObj: js.Global().Get("localStorage"),
+		storageObj: Global().Get("localStorage"),
 	}
 }
 
 // SessionStorage returns the browser's sessionStorage object
 func SessionStorage() Storage {
 	return Storage{
-		storageObj: js.Global().Get("sessionStorage"),
+		storageObj: Global().Get("sessionStorage"),
 	}
 }
 
@@ -260,7 +256,7 @@ func (s Storage) notifyObservers(key, oldValue, newValue string) {
 
 // getStorageAreaName returns the name of the storage area
 func (s Storage) getStorageAreaName() string {
-	if s.storageObj.Equal(js.Global().Get("localStorage")) {
+	if s.storageObj.Equal(Global().Get("localStorage")) {
 		return "localStorage"
 	}
 	return "sessionStorage"
@@ -277,7 +273,7 @@ func setupStorageEventListener() {
 
 	eventListenerSet = true
 
-	callback := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+	callback := FuncOf(func(_ Value, args []Value) interface{} {
 		if len(args) > 0 {
 			storageEvent := args[0]
 
@@ -307,7 +303,7 @@ func setupStorageEventListener() {
 		return nil
 	})
 
-	js.Global().Call("addEventListener", "storage", callback)
+	Global().Call("addEventListener", "storage", callback)
 }
 
 // StorageMigrator helps migrate data between schema versions
@@ -0,0 +1,323 @@
+package dom
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Theme is a named set of CSS custom property values. Applying a theme sets
+// its properties on the body, where they override the stylesheet:
+//
+//	themes := dom.NewThemeRegistry(dom.Theme{
+//		Name:   "green",
+//		Swatch: "#10b981",
+//		Vars:   map[string]string{"color-primary": "#10b981"},
+//		Dark:   map[string]string{"color-primary": "#34d399"},
+//	})
+type Theme struct {
+	Name   string            // identifies the theme in storage and pickers
+	Label  string            // shown in pickers, Name when empty
+	Swatch string            // color standing for the theme in pickers
+	Vars   map[string]string // custom properties, without the leading "--"
+	Dark   map[string]string // properties replacing or adding to Vars in dark mode
+}
+
+// ColorMode chooses between the light and dark variants of the themes
+type ColorMode string
+
+// Color modes. SystemMode follows the prefers-color-scheme setting of the
+// operating system and changes along with it
+const (
+	LightMode  ColorMode = "light"
+	DarkMode   ColorMode = "dark"
+	SystemMode ColorMode = "system"
+)
+
+// ErrUnknownTheme is returned when a theme name has not been registered
+var ErrUnknownTheme = errors.New("dom: unknown theme")
+
+// darkSchemeQuery matches when the system prefers a dark color scheme
+const darkSchemeQuery = "(prefers-color-scheme: dark)"
+
+// ThemeRegistry holds the themes of an app and applies the current one in
+// the current color mode. The body gets the class "theme-<name>" and, in dark
+// mode, DarkClass, so stylesheets can style what variables cannot. Theme
+// pickers registered with SyncPicker show the current theme and mode as active
+type ThemeRegistry struct {
+	// DarkClass is the class of the body in dark mode, "dark-theme" by default
+	DarkClass string
+
+	themes     []Theme
+	current    string
+	mode       ColorMode
+	systemDark bool
+
+	media    Value     // list watching darkSchemeQuery, once started
+	listener *Listener // change listener of media
+	applied  []string  // properties set by the last apply
+	class    string    // theme class set by the last apply
+	pickers  []*themePicker
+	changes  []*themeChange
+}
+
+// themePicker is a container of picker options registered with SyncPicker.
+// Entries are compared by pointer, like themeChange
+type themePicker struct {
+	root Element
+}
+
+// themeChange is a function registered with OnChange. Entries are compared
+// by pointer, since functions are not comparable
+type themeChange struct {
+	fn func()
+}
+
+// NewThemeRegistry creates a registry holding themes, the first of which is
+// current, in SystemMode. Nothing is applied until Set, SetTheme, SetMode or
+// Start is called
+func NewThemeRegistry(themes ...Theme) *ThemeRegistry {
+	r := &ThemeRegistry{DarkClass: "dark-theme", mode: SystemMode}
+	for _, theme := range themes {
+		r.Register(theme)
+	}
+	return r
+}
+
+// Register adds a theme, replacing one with the same name
+func (r *ThemeRegistry) Register(theme Theme) {
+	if r.current == "" {
+		r.current = theme.Name
+	}
+
+	for i, existing := range r.themes {
+		if existing.Name == theme.Name {
+			r.themes[i] = theme
+			return
+		}
+	}
+	r.themes = append(r.themes, theme)
+}
+
+// Themes returns the registered themes in the order they were registered
+func (r *ThemeRegistry) Themes() []Theme {
+	return append([]Theme(nil), r.themes...)
+}
+
+// Lookup returns the theme with the given name
+func (r *ThemeRegistry) Lookup(name string) (Theme, bool) {
+	for _, theme := range r.themes {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Current returns the name of the current theme
+func (r *ThemeRegistry) Current() string {
+	return r.current
+}
+
+// SetTheme makes the named theme current and applies it. It returns an error
+// wrapping ErrUnknownTheme if no theme has that name
+func (r *ThemeRegistry) SetTheme(name string) error {
+	if _, ok := r.Lookup(name); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownTheme, name)
+	}
+
+	r.current = name
+	r.apply()
+	return nil
+}
+
+// Set makes the named theme current in the given color mode and applies
+// them together, so the body never shows the new theme in the old mode. An
+// unknown theme changes nothing and returns an error wrapping ErrUnknownTheme
+func (r *ThemeRegistry) Set(name string, mode ColorMode) error {
+	if _, ok := r.Lookup(name); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownTheme, name)
+	}
+
+	r.current = name
+	r.mode = normalizeMode(mode)
+	r.apply()
+	return nil
+}
+
+// Mode returns the color mode
+func (r *ThemeRegistry) Mode() ColorMode {
+	return r.mode
+}
+
+// SetMode sets the color mode and applies it. Unknown modes are taken as
+// SystemMode
+func (r *ThemeRegistry) SetMode(mode ColorMode) {
+	r.mode = normalizeMode(mode)
+	r.apply()
+}
+
+// normalizeMode takes any mode but light and dark as SystemMode
+func normalizeMode(mode ColorMode) ColorMode {
+	if mode != LightMode && mode != DarkMode {
+		return SystemMode
+	}
+	return mode
+}
+
+// IsDark reports whether the themes are shown in their dark variant, which
+// in SystemMode depends on the system setting
+func (r *ThemeRegistry) IsDark() bool {
+	switch r.mode {
+	case LightMode:
+		return false
+	case DarkMode:
+		return true
+	}
+	return r.systemDark
+}
+
+// Start reads the system color scheme, follows its changes and applies the
+// current theme
+func (r *ThemeRegistry) Start() {
+	if r.listener.Active() {
+		return
+	}
+
+	r.media = Global().Call("matchMedia", darkSchemeQuery)
+	r.systemDark = r.media.Get("matches").Bool()
+	r.listener = newListener(r.media, "change", func(event Value) {
+		r.systemDark = event.Get("matches").Bool()
+		if r.mode == SystemMode {
+			r.apply()
+		}
+	})
+	r.apply()
+}
+
+// Stop stops following the system color scheme
+func (r *ThemeRegistry) Stop() {
+	r.listener.Remove()
+}
+
+// OnChange registers fn to run after the theme or the dark variant changes
+// and returns a function removing it
+func (r *ThemeRegistry) OnChange(fn func()) (remove func()) {
+	entry := &themeChange{fn}
+	r.changes = append(r.changes, entry)
+
+	return func() {
+		for i, e := range r.changes {
+			if e == entry {
+				r.changes = append(r.changes[:i:i], r.changes[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SyncPicker keeps the picker options inside root in step with the
+// registry, now and after every change. Elements with a data-theme attribute
+// stand for a theme and elements with a data-color-mode attribute for a
+// color mode. The option of the current one gets the class "active", and a
+// radio button that is the option or inside it is checked. Acting on a
+// choice is left to the app, through a form binding or a click handler
+// calling SetTheme or SetMode. The returned function stops the syncing
+func (r *ThemeRegistry) SyncPicker(root Element) (stop func()) {
+	picker := &themePicker{root: root}
+	r.pickers = append(r.pickers, picker)
+	r.syncPicker(picker)
+
+	return func() {
+		for i, p := range r.pickers {
+			if p == picker {
+				r.pickers = append(r.pickers[:i:i], r.pickers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// apply sets the properties and classes of the current theme and mode on
+// the body, then updates the pickers and runs the change functions
+func (r *ThemeRegistry) apply() {
+	theme, ok := r.Lookup(r.current)
+	if !ok {
+		return
+	}
+	dark := r.IsDark()
+
+	vars := map[string]string{}
+	for name, value := range theme.Vars {
+		vars[name] = value
+	}
+	if dark {
+		for name, value := range theme.Dark {
+			vars[name] = value
+		}
+	}
+
+	body := Document().QuerySelector("body")
+	style := body.Style()
+	for _, name := range r.applied {
+		if _, ok := vars[name]; !ok {
+			style.RemoveVariable(name)
+		}
+	}
+	r.applied = r.applied[:0]
+	for name := range vars {
+		r.applied = append(r.applied, name)
+	}
+	sort.Strings(r.applied)
+	for _, name := range r.applied {
+		style.SetVariable(name, vars[name])
+	}
+
+	if r.class != "" {
+		body.ClassList().Remove(r.class)
+	}
+	r.class = "theme-" + theme.Name
+	body.ClassList().Add(r.class)
+
+	// Native controls and scrollbars follow the color-scheme property
+	scheme := "light"
+	if dark {
+		scheme = "dark"
+		body.ClassList().Add(r.DarkClass)
+	} else {
+		body.ClassList().Remove(r.DarkClass)
+	}
+	Document().QuerySelector(":root").Style().SetProperty("colorScheme", scheme)
+
+	for _, picker := range r.pickers {
+		r.syncPicker(picker)
+	}
+	for _, change := range append([]*themeChange(nil), r.changes...) {
+		change.fn()
+	}
+}
+
+// syncPicker marks the options of the current theme and mode as active
+func (r *ThemeRegistry) syncPicker(picker *themePicker) {
+	mark := func(selector, attr, current string) {
+		for _, option := range picker.root.QuerySelectorAll(selector) {
+			active := option.GetAttribute(attr) == current
+			if active {
+				option.ClassList().Add("active")
+			} else {
+				option.ClassList().Remove("active")
+			}
+
+			radio := option
+			if option.El.Get("type").String() != "radio" {
+				radio = option.QuerySelector("input[type=radio]")
+			}
+			if radio.Exists() {
+				radio.El.Set("checked", active)
+			}
+		}
+	}
+
+	mark("[data-theme]", "data-theme", r.current)
+	mark("[data-color-mode]", "data-color-mode", string(r.mode))
+}
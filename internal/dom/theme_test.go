@@ -0,0 +1,115 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package dom
+
+import (
+	"errors"
+	"testing"
+)
+
+// newThemes creates a started registry with a blue and a green theme and
+// counts the times it applies them
+func newThemes(t *testing.T) (*ThemeRegistry, *int) {
+	t.Helper()
+	r := NewThemeRegistry(
+		Theme{Name: "blue", Vars: map[string]string{"primary": "blue", "radius": "4px"}, Dark: map[string]string{"primary": "navy"}},
+		Theme{Name: "green", Vars: map[string]string{"primary": "green"}},
+	)
+	r.Start()
+	t.Cleanup(r.Stop)
+
+	applied := 0
+	r.OnChange(func() { applied++ })
+	return r, &applied
+}
+
+// bodyVar returns the custom property name on the body
+func bodyVar(name string) string {
+	return Document().QuerySelector("body").Style().GetVariable(name)
+}
+
+func TestThemeSetAppliesThemeAndModeOnce(t *testing.T) {
+	r, applied := newThemes(t)
+
+	if err := r.Set("green", DarkMode); err != nil {
+		t.Fatal(err)
+	}
+	if *applied != 1 {
+		t.Errorf("applied %d times, want 1", *applied)
+	}
+	if r.Current() != "green" || !r.IsDark() {
+		t.Errorf("theme %s dark %v, want green dark", r.Current(), r.IsDark())
+	}
+
+	body := Document().QuerySelector("body").ClassList()
+	if !body.Contains("theme-green") || body.Contains("theme-blue") || !body.Contains("dark-theme") {
+		t.Error("body classes not updated")
+	}
+	if bodyVar("primary") != "green" || bodyVar("radius") != "" {
+		t.Errorf("primary %q radius %q, want the blue properties replaced", bodyVar("primary"), bodyVar("radius"))
+	}
+}
+
+func TestThemeSetRejectsUnknownThemes(t *testing.T) {
+	r, applied := newThemes(t)
+
+	if err := r.Set("pink", DarkMode); !errors.Is(err, ErrUnknownTheme) {
+		t.Errorf("err = %v, want ErrUnknownTheme", err)
+	}
+	if *applied != 0 || r.Current() != "blue" || r.Mode() != SystemMode {
+		t.Errorf("unknown theme changed the registry to %s in %s", r.Current(), r.Mode())
+	}
+}
+
+func TestThemeDarkVariant(t *testing.T) {
+	r, _ := newThemes(t)
+
+	r.SetMode(DarkMode)
+	if bodyVar("primary") != "navy" || bodyVar("radius") != "4px" {
+		t.Errorf("dark primary %q radius %q", bodyVar("primary"), bodyVar("radius"))
+	}
+
+	r.SetMode("sepia")
+	if r.Mode() != SystemMode {
+		t.Errorf("unknown mode taken as %s, want system", r.Mode())
+	}
+}
+
+func TestThemeFollowsTheSystemScheme(t *testing.T) {
+	memory := backend.(*MemoryBackend)
+	t.Cleanup(func() { memory.SetMediaFeature("prefers-color-scheme", "light") })
+	r, _ := newThemes(t)
+
+	memory.SetMediaFeature("prefers-color-scheme", "dark")
+	if !r.IsDark() || bodyVar("primary") != "navy" {
+		t.Error("system dark scheme not followed")
+	}
+
+	r.SetMode(LightMode)
+	memory.SetMediaFeature("prefers-color-scheme", "light")
+	memory.SetMediaFeature("prefers-color-scheme", "dark")
+	if r.IsDark() {
+		t.Error("light mode followed the system scheme")
+	}
+}
+
+func TestThemeSyncPicker(t *testing.T) {
+	r, _ := newThemes(t)
+	root := newContainer(t)
+	root.SetHTML(`<button data-theme="blue"></button><button data-theme="green"></button>` +
+		`<input type="radio" data-color-mode="light"><input type="radio" data-color-mode="dark">`)
+	stop := r.SyncPicker(root)
+	defer stop()
+
+	if err := r.Set("green", LightMode); err != nil {
+		t.Fatal(err)
+	}
+	if !root.QuerySelector(`[data-theme="green"]`).ClassList().Contains("active") ||
+		root.QuerySelector(`[data-theme="blue"]`).ClassList().Contains("active") {
+		t.Error("theme option not marked active")
+	}
+	if !root.QuerySelector(`[data-color-mode="light"]`).El.Get("checked").Bool() {
+		t.Error("light mode radio not checked")
+	}
+}
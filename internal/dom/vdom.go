@@ -0,0 +1,336 @@
+package dom
+
+import "reflect"
+
+// VNode is a virtual DOM node describing an element, a text node or a component
+type VNode struct {
+	Tag       string                 // Element tag name, empty for text nodes
+	Key       string                 // Stable identity among siblings (e.g. a record ID)
+	Attrs     map[string]string      // HTML attributes
+	Props     map[string]interface{} // DOM properties such as value or checked
+	Text      string                 // Content of a text node
+	Children  []*VNode               // Child nodes
+	Events    map[string]func(Event) // Event handlers keyed by event type
+	OnCreate  func(Element)          // Called once the element has been created and inserted
+	Component Component              // Component rendering this node, see ComponentNode
+
+	el    Element
+	state *vnodeState
+}
+
+// vnodeState holds the listeners installed for a rendered element
+type vnodeState struct {
+	handlers  map[string]func(Event)
+	listeners map[string]*Listener
+}
+
+// TextNode creates a virtual text node
+func TextNode(text string) *VNode {
+	return &VNode{Text: text}
+}
+
+// Element returns the DOM element backing a rendered node. For a component
+// node that is the root of whatever the component last rendered
+func (v *VNode) Element() Element {
+	if v.Component != nil {
+		if rendered := v.Component.base().rendered; rendered != nil {
+			return rendered.Element()
+		}
+		return Element{}
+	}
+	return v.el
+}
+
+// isText reports whether the node describes a text node
+func (v *VNode) isText() bool {
+	return v.Tag == "" && v.Component == nil
+}
+
+// Reconciler keeps the children of a container element in sync with a list of virtual nodes
+type Reconciler struct {
+	Container Element
+	children  []*VNode
+	mounted   bool
+}
+
+// NewReconciler creates a reconciler that owns the children of container
+func NewReconciler(container Element) *Reconciler {
+	return &Reconciler{
+		Container: container,
+	}
+}
+
+// Patch updates the container so that its children match the given nodes,
+// applying only the DOM mutations needed to get there
+func (r *Reconciler) Patch(children []*VNode) {
+	if !r.Container.Exists() {
+		return
+	}
+
+	if !r.mounted {
+		// Take ownership of the container
+		r.Container.SetHTML("")
+		r.mounted = true
+	}
+
+	var created []*VNode
+	patchChildren(r.Container, r.children, children, &created)
+	r.children = children
+
+	// Run creation hooks once everything is in place
+	runCreated(created)
+}
+
+// Clear removes every node owned by the reconciler
+func (r *Reconciler) Clear() {
+	r.Patch(nil)
+}
+
+// createNode builds the DOM for a virtual node and its subtree
+func createNode(node *VNode, created *[]*VNode) Element {
+	if node.Component != nil {
+		return createComponent(node, created)
+	}
+
+	document := Global().Get("document")
+
+	if node.isText() {
+		node.el = Element{El: document.Call("createTextNode", node.Text)}
+		return node.el
+	}
+
+	node.el = Element{El: document.Call("createElement", node.Tag)}
+
+	for name, value := range node.Attrs {
+		node.el.SetAttribute(name, value)
+	}
+
+	node.state = &vnodeState{
+		handlers:  map[string]func(Event){},
+		listeners: map[string]*Listener{},
+	}
+	updateEvents(node)
+
+	for _, child := range node.Children {
+		node.el.AppendChild(createNode(child, created))
+	}
+
+	// Properties go last so that a select's value can pick one of its options
+	for name, value := range node.Props {
+		node.el.El.Set(name, value)
+	}
+
+	if node.OnCreate != nil {
+		*created = append(*created, node)
+	}
+
+	return node.el
+}
+
+// patchNode updates the DOM of old so that it matches node
+func patchNode(parent Element, old, node *VNode, created *[]*VNode) {
+	if !sameKind(old, node) {
+		replacement := createNode(node, created)
+		previous := old.Element()
+		unmountNode(old)
+		parent.El.Call("replaceChild", replacement.El, previous.El)
+		previous.Release()
+		return
+	}
+
+	if node.Component != nil {
+		patchComponent(parent, old, node, created)
+		return
+	}
+
+	node.el = old.el
+	node.state = old.state
+
+	if node.isText() {
+		if old.Text != node.Text {
+			node.el.El.Set("nodeValue", node.Text)
+		}
+		return
+	}
+
+	// Attributes
+	for name := range old.Attrs {
+		if _, ok := node.Attrs[name]; !ok {
+			node.el.RemoveAttribute(name)
+		}
+	}
+	for name, value := range node.Attrs {
+		if current, ok := old.Attrs[name]; !ok || current != value {
+			node.el.SetAttribute(name, value)
+		}
+	}
+
+	updateEvents(node)
+
+	patchChildren(node.el, old.Children, node.Children, created)
+
+	// Properties
+	for name, value := range old.Props {
+		if _, ok := node.Props[name]; !ok {
+			node.el.El.Set(name, zeroProp(value))
+		}
+	}
+	// DeepEqual rather than == which panics on slices, maps and funcs. Funcs
+	// never compare equal and are set again on every patch
+	for name, value := range node.Props {
+		if current, ok := old.Props[name]; !ok || !reflect.DeepEqual(current, value) {
+			node.el.El.Set(name, value)
+		}
+	}
+}
+
+// zeroProp returns the value that resets a property no longer set to value:
+// false, "" or 0 for booleans, strings and numbers, and null otherwise
+func zeroProp(value interface{}) interface{} {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return reflect.Zero(reflect.TypeOf(value)).Interface()
+	}
+	return nil
+}
+
+// updateEvents installs listeners for new event types and points existing
+// listeners at the node's current handlers
+func updateEvents(node *VNode) {
+	state := node.state
+	state.handlers = node.Events
+
+	for eventType := range node.Events {
+		if state.listeners[eventType].Active() {
+			continue
+		}
+
+		eventType := eventType
+		state.listeners[eventType] = node.el.AddEventListenerWithEvent(eventType, func(event Event) {
+			if handler, ok := state.handlers[eventType]; ok {
+				handler(event)
+			}
+		})
+	}
+}
+
+// patchChildren reconciles the children of parent, matching keyed nodes by
+// key and unkeyed nodes by position and kind
+func patchChildren(parent Element, oldChildren, newChildren []*VNode, created *[]*VNode) {
+	keyed := map[string]int{}
+	var unkeyed []int
+	for i, child := range oldChildren {
+		if child.Key != "" {
+			keyed[child.Key] = i
+		} else {
+			unkeyed = append(unkeyed, i)
+		}
+	}
+
+	// sources[i] holds the old index reused by newChildren[i], or -1
+	sources := make([]int, len(newChildren))
+	used := make([]bool, len(oldChildren))
+
+	for i, child := range newChildren {
+		sources[i] = -1
+
+		if child.Key != "" {
+			if j, ok := keyed[child.Key]; ok && !used[j] {
+				sources[i] = j
+			}
+		} else {
+			for n, j := range unkeyed {
+				if sameKind(oldChildren[j], child) {
+					sources[i] = j
+					unkeyed = append(unkeyed[:n], unkeyed[n+1:]...)
+					break
+				}
+			}
+		}
+
+		if sources[i] >= 0 {
+			used[sources[i]] = true
+		}
+	}
+
+	// Remove nodes that are no longer present, releasing their listeners
+	for j, child := range oldChildren {
+		if !used[j] {
+			el := child.Element()
+			unmountNode(child)
+			el.Remove()
+		}
+	}
+
+	// Patch reused nodes in place
+	for i, child := range newChildren {
+		if sources[i] >= 0 {
+			patchNode(parent, oldChildren[sources[i]], child, created)
+		}
+	}
+
+	// Nodes whose old indices form the longest increasing run can stay put;
+	// everything else is inserted or moved before its next sibling
+	stable := longestIncreasing(sources)
+	next := Null()
+
+	for i := len(newChildren) - 1; i >= 0; i-- {
+		child := newChildren[i]
+
+		if sources[i] < 0 {
+			parent.El.Call("insertBefore", createNode(child, created).El, next)
+		} else if !stable[i] {
+			parent.El.Call("insertBefore", child.Element().El, next)
+		}
+
+		next = child.Element().El
+	}
+}
+
+// longestIncreasing marks the positions that belong to the longest strictly
+// increasing subsequence of the non-negative values in seq
+func longestIncreasing(seq []int) []bool {
+	stable := make([]bool, len(seq))
+	predecessors := make([]int, len(seq))
+	var tails []int // indices into seq of the smallest tail for each length
+
+	for i, value := range seq {
+		if value < 0 {
+			continue
+		}
+
+		// Binary search for the first tail that is not smaller than value
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if seq[tails[mid]] < value {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+
+		if lo > 0 {
+			predecessors[i] = tails[lo-1]
+		} else {
+			predecessors[i] = -1
+		}
+
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = predecessors[i] {
+			stable[i] = true
+		}
+	}
+
+	return stable
+}
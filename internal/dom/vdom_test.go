@@ -0,0 +1,114 @@
+package dom
+
+import "testing"
+
+// newContainer returns an empty div attached to the body
+func newContainer(t *testing.T) Element {
+	t.Helper()
+	container := Document().CreateElement("div")
+	Document().QuerySelector("body").AppendChild(container)
+	t.Cleanup(container.Remove)
+	return container
+}
+
+// items renders one keyed li per key
+func items(keys ...string) []*VNode {
+	nodes := make([]*VNode, len(keys))
+	for i, key := range keys {
+		nodes[i] = H("li", Key(key), Text(key))
+	}
+	return nodes
+}
+
+func TestPatchReordersKeyedChildren(t *testing.T) {
+	container := newContainer(t)
+	r := NewReconciler(container)
+
+	r.Patch(items("a", "b", "c"))
+	before := container.QuerySelectorAll("li")
+
+	r.Patch(items("c", "a", "b"))
+	after := container.QuerySelectorAll("li")
+
+	if got := container.El.Get("textContent").String(); got != "cab" {
+		t.Fatalf("text = %q, want %q", got, "cab")
+	}
+	// The elements are moved, not recreated
+	if !after[0].El.Equal(before[2].El) || !after[1].El.Equal(before[0].El) || !after[2].El.Equal(before[1].El) {
+		t.Error("keyed elements were not reused")
+	}
+}
+
+func TestPatchAddsAndRemovesChildren(t *testing.T) {
+	container := newContainer(t)
+	r := NewReconciler(container)
+
+	r.Patch(items("a", "b", "c"))
+	r.Patch(items("b", "d"))
+
+	if got := container.El.Get("textContent").String(); got != "bd" {
+		t.Fatalf("text = %q, want %q", got, "bd")
+	}
+
+	r.Clear()
+	if got := len(container.QuerySelectorAll("li")); got != 0 {
+		t.Errorf("%d children left after Clear", got)
+	}
+}
+
+func TestPatchRemovesStaleAttributes(t *testing.T) {
+	container := newContainer(t)
+	r := NewReconciler(container)
+
+	r.Patch([]*VNode{H("li", Attrs{"class": "done", "data-id": "1", "title": "x"})})
+	r.Patch([]*VNode{H("li", Attrs{"data-id": "2"})})
+
+	li := container.QuerySelector("li")
+	if li.GetAttribute("data-id") != "2" {
+		t.Errorf("data-id = %q, want %q", li.GetAttribute("data-id"), "2")
+	}
+	for _, name := range []string{"class", "title"} {
+		if li.El.Call("hasAttribute", name).Bool() {
+			t.Errorf("attribute %s was not removed", name)
+		}
+	}
+}
+
+func TestPatchResetsStaleProps(t *testing.T) {
+	container := newContainer(t)
+	r := NewReconciler(container)
+
+	r.Patch([]*VNode{H("input", Attrs{"type": "checkbox"}, Props{"checked": true, "value": "on"})})
+	r.Patch([]*VNode{H("input", Attrs{"type": "checkbox"})})
+
+	input := container.QuerySelector("input")
+	if input.El.Get("checked").Bool() {
+		t.Error("checked survived the patch")
+	}
+	if got := input.El.Get("value").String(); got != "" {
+		t.Errorf("value = %q, want it reset", got)
+	}
+}
+
+func TestPatchComparesUncomparableProps(t *testing.T) {
+	container := newContainer(t)
+	r := NewReconciler(container)
+
+	// Slices and maps can't be compared with ==; patching must not panic
+	r.Patch([]*VNode{H("div", Props{"items": []interface{}{"a"}, "options": map[string]interface{}{"x": 1}})})
+	r.Patch([]*VNode{H("div", Props{"items": []interface{}{"a", "b"}, "options": map[string]interface{}{"x": 2}})})
+
+	if got := container.QuerySelector("div").El.Get("items").Length(); got != 2 {
+		t.Errorf("items has %d entries, want 2", got)
+	}
+}
+
+func TestLongestIncreasing(t *testing.T) {
+	stable := longestIncreasing([]int{2, 0, 1, -1, 3})
+	want := []bool{false, true, true, false, true}
+	for i := range want {
+		if stable[i] != want[i] {
+			t.Fatalf("longestIncreasing = %v, want %v", stable, want)
+		}
+	}
+}
@@ -0,0 +1,1049 @@
+// Package main implements the WebAssembly client for the Go fullstack framework
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorgasm/internal/dom"
+)
+
+// Todo represents a single todo item
+type Todo struct {
+	ID        string   `json:"id"`        // Unique identifier
+	Text      string   `json:"text"`      // Todo text
+	Completed bool     `json:"completed"` // Completion status
+	CreatedAt int64    `json:"createdAt"` // Creation timestamp
+	Position  int      `json:"position"`  // For reordering
+	Priority  int      `json:"priority"`  // Priority level (1-3)
+	Tags      []string `json:"tags"`      // Tags for categorization
+}
+
+// Preferences are the settings edited in the settings panel. Each field is
+// bound to the panel control named by its form tag
+type Preferences struct {
+	Theme          string `json:"theme" form:"theme" validate:"oneof=blue green purple orange"`
+	AnimationSpeed string `json:"animationSpeed" form:"animation-speed" validate:"oneof=faster normal slower none"`
+	FontSize       string `json:"fontSize" form:"font-size" validate:"oneof=small medium large"`
+	ColorMode      string `json:"colorMode" form:"color-mode" validate:"oneof=light dark system"`
+}
+
+// appThemes are the color themes offered in the settings panel. Dark lists
+// the colors that change in dark mode
+var appThemes = []dom.Theme{
+	{
+		Name:   "blue",
+		Swatch: "#6366f1",
+		Vars:   map[string]string{"color-primary": "#6366f1", "color-primary-dark": "#4f46e5"},
+		Dark:   map[string]string{"color-primary": "#818cf8", "color-primary-dark": "#6366f1"},
+	},
+	{
+		Name:   "green",
+		Swatch: "#10b981",
+		Vars:   map[string]string{"color-primary": "#10b981", "color-primary-dark": "#059669"},
+	},
+	{
+		Name:   "purple",
+		Swatch: "#8b5cf6",
+		Vars:   map[string]string{"color-primary": "#8b5cf6", "color-primary-dark": "#7c3aed"},
+	},
+	{
+		Name:   "orange",
+		Swatch: "#f59e0b",
+		Vars:   map[string]string{"color-primary": "#f59e0b", "color-primary-dark": "#d97706"},
+	},
+}
+
+// Global state
+var (
+	todos         = dom.NewSignal([]Todo{}) // All todos, ordered by position
+	currentFilter = dom.NewSignal("all")    // "all", "active", "completed", "priority" or "tag:<name>"
+	preferences   = dom.NewSignal(Preferences{Theme: "blue", AnimationSpeed: "normal", FontSize: "medium", ColorMode: "system"})
+	settingsForm  *dom.Form[Preferences] // Binds the preferences to the settings panel
+	themes        *dom.ThemeRegistry     // Color themes and the light/dark mode
+	storage       dom.CachedStorage      // Cached storage for better performance
+	todoList      *TodoList              // Todo list component
+	filterBar     *FilterBar             // Filter buttons component
+	settingsPanel *SettingsPanel         // Settings panel component
+	router        *dom.Router            // Maps the address to the current filter
+	shortcuts     *dom.ShortcutManager   // Keyboard shortcuts and their help overlay
+)
+
+// Derived state, recomputed when todos or currentFilter change
+var (
+	visibleTodos = dom.NewComputed(func() []Todo {
+		return filterTodos(todos.Get(), currentFilter.Get())
+	})
+
+	itemsLeftText = dom.NewComputed(func() string {
+		activeCount := 0
+		highPriorityCount := 0
+
+		// Count active todos and high priority todos
+		for _, todo := range todos.Get() {
+			if !todo.Completed {
+				activeCount++
+				if todo.Priority >= 2 {
+					highPriorityCount++
+				}
+			}
+		}
+
+		text := strconv.Itoa(activeCount) + " items left"
+		if activeCount == 1 {
+			text = "1 item left"
+		}
+
+		// Add high priority count if any
+		if highPriorityCount > 0 {
+			text += fmt.Sprintf(" (%d high priority)", highPriorityCount)
+		}
+
+		return text
+	})
+
+	hasCompleted = dom.NewComputed(func() bool {
+		for _, todo := range todos.Get() {
+			if todo.Completed {
+				return true
+			}
+		}
+		return false
+	})
+)
+
+// Storage keys
+const (
+	todosKey         = "gowasm-todos"
+	filterKey        = "gowasm-filter"
+	preferencesKey   = "gowasm-preferences"
+	schemaVersionKey = "gowasm-schema-version"
+
+	// Settings were stored one per key before Preferences
+	themeKey     = "gowasm-theme"
+	animSpeedKey = "gowasm-anim-speed"
+	fontSizeKey  = "gowasm-font-size"
+
+	// Dark mode was an on/off setting before the color mode
+	darkModeKey = "gowasm-dark-mode"
+)
+
+/**
+ * Initialize the application and setup event handlers
+ */
+func initialize() {
+	// Tell the user when a handler fails instead of leaving the page
+	// unresponsive
+	dom.AddReporter(dom.ErrorBanner{Message: "Something went wrong. Your todos are saved; reload the page if the app stops responding."})
+
+	// Initialize cached storage
+	storage = dom.NewCachedStorage(dom.LocalStorage(), 5*time.Minute)
+
+	// Register the color themes and follow the system color scheme
+	themes = dom.NewThemeRegistry(appThemes...)
+	themes.Start()
+
+	// Run storage migration if needed
+	migrator := dom.NewStorageMigrator(storage.Storage)
+	migrator.RunMigration(2, migrateTodoSchema)
+
+	// Replace the static markup with components
+	mountComponents()
+
+	// Load saved preferences
+	loadPreferences()
+
+	// Load todos
+	loadTodos()
+
+	// Show the filter named by the address
+	setupRouter()
+
+	// Keep the page in sync with the state
+	setupEffects()
+
+	// Setup event listeners
+	setupEventListeners()
+
+	// Hide loading indicator
+	document := dom.Document()
+	loading := document.GetElementById("loading")
+	loading.ClassList().Add("hidden")
+
+	// Log initialization
+	fmt.Println("Go WebAssembly Todo App initialized with enhanced features")
+}
+
+/**
+ * Load todos from localStorage
+ */
+func loadTodos() {
+	// Get todos from localStorage or initialize empty array
+	var loaded []Todo
+	err := storage.GetJSON(todosKey, &loaded)
+	if err != nil || loaded == nil {
+		loaded = []Todo{}
+	}
+
+	// Sort todos by position property
+	sortTodosByPosition(loaded)
+
+	todos.Set(loaded)
+}
+
+/**
+ * Sort todos by their position property
+ */
+func sortTodosByPosition(list []Todo) {
+	// Simple bubble sort (for small arrays it's fine)
+	n := len(list)
+	for i := 0; i < n-1; i++ {
+		for j := 0; j < n-i-1; j++ {
+			if list[j].Position > list[j+1].Position {
+				list[j], list[j+1] = list[j+1], list[j]
+			}
+		}
+	}
+}
+
+/**
+ * Save todos to localStorage
+ */
+func saveTodos(list []Todo) bool {
+	err := storage.SetJSON(todosKey, list)
+	return err == nil
+}
+
+/**
+ * Apply fn to the todo with the given ID and store the result
+ */
+func updateTodo(id string, fn func(todo *Todo)) bool {
+	list := append([]Todo(nil), todos.Peek()...)
+	for i := range list {
+		if list[i].ID == id {
+			fn(&list[i])
+			todos.Set(list)
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Remove the todos matching fn
+ */
+func removeTodos(fn func(todo Todo) bool) {
+	list := []Todo{}
+	for _, todo := range todos.Peek() {
+		if !fn(todo) {
+			list = append(list, todo)
+		}
+	}
+	todos.Set(list)
+}
+
+/**
+ * Add a new todo
+ */
+func addTodo(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	// Find the highest position value
+	highestPosition := 0
+	for _, todo := range todos.Peek() {
+		if todo.Position > highestPosition {
+			highestPosition = todo.Position
+		}
+	}
+
+	// Create new todo
+	newTodo := Todo{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
+		Text:      processTodoText(text),
+		Completed: false,
+		CreatedAt: time.Now().Unix(),
+		Position:  highestPosition + 1,
+		Priority:  extractPriority(text),
+		Tags:      extractTags(text),
+	}
+
+	// Add to list
+	todos.Update(func(list []Todo) []Todo {
+		return append(append([]Todo(nil), list...), newTodo)
+	})
+
+	// Make sure the new todo can be seen
+	todoList.Reveal(newTodo.ID)
+	dom.Announce("Added todo: "+newTodo.Text, dom.Polite)
+
+	return true
+}
+
+/**
+ * Process todo text to extract metadata (priority, tags)
+ */
+func processTodoText(text string) string {
+	// Remove priority marker
+	for _, p := range []string{"!!!", "!!", "!"} {
+		text = strings.Replace(text, p, "", 1)
+	}
+
+	// Remove tags
+	words := strings.Fields(text)
+	cleanedWords := []string{}
+
+	for _, word := range words {
+		if !strings.HasPrefix(word, "#") {
+			cleanedWords = append(cleanedWords, word)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(cleanedWords, " "))
+}
+
+/**
+ * Extract priority from todo text (!, !!, !!!)
+ */
+func extractPriority(text string) int {
+	if strings.Contains(text, "!!!") {
+		return 3 // High priority
+	} else if strings.Contains(text, "!!") {
+		return 2 // Medium priority
+	} else if strings.Contains(text, "!") {
+		return 1 // Low priority
+	}
+	return 0 // No priority
+}
+
+/**
+ * Extract tags from todo text (#tag)
+ */
+func extractTags(text string) []string {
+	words := strings.Fields(text)
+	tags := []string{}
+
+	for _, word := range words {
+		if strings.HasPrefix(word, "#") {
+			tag := strings.TrimPrefix(word, "#")
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+/**
+ * Toggle todo completion status
+ */
+func toggleTodo(id string) bool {
+	index := todoIndex(todos.Peek(), id)
+	if index < 0 {
+		return false
+	}
+	todo := todos.Peek()[index]
+
+	// Change and save the state first; the animation only follows it
+	updateTodo(id, func(todo *Todo) {
+		todo.Completed = !todo.Completed
+	})
+	if todo.Completed {
+		dom.Announce("Marked as not completed: "+todo.Text, dom.Polite)
+	} else {
+		dom.Announce("Marked as completed: "+todo.Text, dom.Polite)
+	}
+
+	// Fade the row out and back in while it renders the new state
+	element := todoList.Element().QuerySelector(fmt.Sprintf("li[data-id='%s']", id))
+	dom.Sequence(
+		element.Preset("fadeOut", 300),
+		element.Preset("fadeIn", 300),
+	).Start()
+
+	return true
+}
+
+/**
+ * Delete a todo
+ */
+func deleteTodo(id string) bool {
+	index := todoIndex(todos.Peek(), id)
+	if index < 0 {
+		return false
+	}
+	dom.Announce("Deleted todo: "+todos.Peek()[index].Text, dom.Polite)
+
+	// Apply delete animation first
+	document := dom.Document()
+	element := document.QuerySelector(fmt.Sprintf("li[data-id='%s']", id))
+	element.ClassList().Add("todo-deleting")
+
+	// Remove the todo after animation
+	window := dom.GetWindow()
+	window.SetTimeout(func() {
+		removeTodos(func(todo Todo) bool {
+			return todo.ID == id
+		})
+	}, 300)
+
+	return true
+}
+
+/**
+ * Edit a todo
+ */
+func editTodo(id string, newText string) bool {
+	if newText == "" {
+		return false
+	}
+
+	// Find and update the todo
+	return updateTodo(id, func(todo *Todo) {
+		todo.Text = processTodoText(newText)
+		todo.Priority = extractPriority(newText)
+		todo.Tags = extractTags(newText)
+	})
+}
+
+/**
+ * Clear completed todos
+ */
+func clearCompleted() int {
+	// Count completed todos
+	completedCount := 0
+	completedIds := []string{}
+
+	for _, todo := range todos.Peek() {
+		if todo.Completed {
+			completedCount++
+			completedIds = append(completedIds, todo.ID)
+		}
+	}
+
+	// Apply animation to all completed todos
+	document := dom.Document()
+	for _, id := range completedIds {
+		element := document.QuerySelector(fmt.Sprintf("li[data-id='%s']", id))
+		element.ClassList().Add("todo-deleting")
+	}
+
+	// Remove completed todos after animation
+	window := dom.GetWindow()
+	window.SetTimeout(func() {
+		removeTodos(func(todo Todo) bool {
+			return todo.Completed
+		})
+	}, 300)
+
+	switch completedCount {
+	case 0:
+	case 1:
+		dom.Announce("Cleared 1 completed todo", dom.Polite)
+	default:
+		dom.Announce("Cleared "+strconv.Itoa(completedCount)+" completed todos", dom.Polite)
+	}
+
+	return completedCount
+}
+
+/**
+ * Toggle all todos completion status
+ */
+func toggleAllTodos() int {
+	list := append([]Todo(nil), todos.Peek()...)
+
+	// Determine if all todos are currently completed
+	allCompleted := true
+	for _, todo := range list {
+		if !todo.Completed {
+			allCompleted = false
+			break
+		}
+	}
+
+	// Toggle all todos in opposite direction
+	changedCount := 0
+	for i := range list {
+		if list[i].Completed == allCompleted {
+			list[i].Completed = !allCompleted
+			changedCount++
+		}
+	}
+
+	// Store the new state right away, then fade every row out and back in
+	// together. The rows are kept across renders, so they must not stay faded
+	todos.Set(list)
+
+	fadeOuts, fadeIns := []dom.Step{}, []dom.Step{}
+	for _, row := range todoList.Element().QuerySelectorAll("li[data-id]") {
+		fadeOuts = append(fadeOuts, row.Preset("fadeOut", 200))
+		fadeIns = append(fadeIns, row.Preset("fadeIn", 200))
+	}
+
+	dom.Sequence(
+		dom.Parallel(fadeOuts...),
+		dom.Parallel(fadeIns...),
+	).Start()
+
+	if changedCount > 0 {
+		if allCompleted {
+			dom.Announce("Marked all todos as not completed", dom.Polite)
+		} else {
+			dom.Announce("Marked all todos as completed", dom.Polite)
+		}
+	}
+
+	return changedCount
+}
+
+/**
+ * Set the current filter by navigating to its route
+ */
+func setFilter(filter string) string {
+	// Validate filter
+	if !validFilter(filter) {
+		filter = "all"
+	}
+
+	router.Navigate(filterPath(filter))
+	return filter
+}
+
+/**
+ * Report whether filter is one of the known filters
+ */
+func validFilter(filter string) bool {
+	switch filter {
+	case "all", "active", "completed", "priority":
+		return true
+	}
+	return strings.HasPrefix(filter, "tag:") && len(filter) > len("tag:")
+}
+
+/**
+ * Return the route path showing filter
+ */
+func filterPath(filter string) string {
+	if filter == "all" {
+		return "/"
+	}
+	if tag, ok := strings.CutPrefix(filter, "tag:"); ok {
+		return "/tag/" + url.PathEscape(tag)
+	}
+	return "/" + filter
+}
+
+/**
+ * Switch the list to filter. Todos shown under both filters slide to their
+ * new places; the ones that appear play their own entry animation
+ */
+func showFilter(filter string) {
+	if filter == currentFilter.Peek() {
+		return
+	}
+
+	dom.Flip(todoList.Element(), dom.FlipOptions{Duration: 200}, func() {
+		currentFilter.Set(filter)
+	}).Start()
+}
+
+/**
+ * Create the router mapping the address to the current filter. Hash routes
+ * are used because the server only serves static files
+ */
+func setupRouter() {
+	router = dom.NewRouter(dom.HashMode).
+		Handle("/", func(dom.Params) { showFilter("all") }).
+		Handle("/active", func(dom.Params) { showFilter("active") }).
+		Handle("/completed", func(dom.Params) { showFilter("completed") }).
+		Handle("/priority", func(dom.Params) { showFilter("priority") }).
+		Handle("/tag/:name", func(params dom.Params) { showFilter("tag:" + params["name"]) }).
+		NotFound(func(string) { router.Replace("/") })
+
+	// Without a route in the address, show the filter used last time
+	if router.Path() == "/" && currentFilter.Peek() != "all" && validFilter(currentFilter.Peek()) {
+		router.Replace(filterPath(currentFilter.Peek()))
+	}
+
+	router.Start()
+}
+
+/**
+ * Format a todo for editing (with priority and tags)
+ */
+func formatTodoForEdit(todo Todo) string {
+	editValue := todo.Text
+
+	// Add priority markers
+	if todo.Priority == 3 {
+		editValue = "!!! " + editValue
+	} else if todo.Priority == 2 {
+		editValue = "!! " + editValue
+	} else if todo.Priority == 1 {
+		editValue = "! " + editValue
+	}
+
+	// Add tags
+	for _, tag := range todo.Tags {
+		editValue += " #" + tag
+	}
+
+	return editValue
+}
+
+/**
+ * Filter todos, keeping only the ones shown under filter
+ */
+func filterTodos(list []Todo, filter string) []Todo {
+	visible := []Todo{}
+	for _, todo := range list {
+		if filter == "active" && todo.Completed {
+			continue
+		}
+		if filter == "completed" && !todo.Completed {
+			continue
+		}
+		if filter == "priority" && todo.Priority < 1 {
+			continue
+		}
+		if tag, ok := strings.CutPrefix(filter, "tag:"); ok && !hasTag(todo, tag) {
+			continue
+		}
+
+		visible = append(visible, todo)
+	}
+	return visible
+}
+
+/**
+ * Report whether the todo is tagged with tag
+ */
+func hasTag(todo Todo, tag string) bool {
+	for _, t := range todo.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Create the effects that keep storage and the page in sync with the state
+ */
+func setupEffects() {
+	document := dom.Document()
+
+	// Apply and persist the settings
+	dom.NewEffect(func() {
+		prefs := preferences.Get()
+		if err := themes.Set(prefs.Theme, dom.ColorMode(prefs.ColorMode)); err != nil {
+			dom.ReportError(err, "preferences")
+			themes.SetMode(dom.ColorMode(prefs.ColorMode))
+		}
+		dom.SetAnimationSpeed(prefs.AnimationSpeed)
+		dom.SetFontSize(prefs.FontSize)
+		storage.SetJSON(preferencesKey, prefs)
+	})
+
+	// Persist todos and the filter whenever they change
+	dom.NewEffect(func() {
+		saveTodos(todos.Get())
+	})
+	dom.NewEffect(func() {
+		storage.SetItem(filterKey, currentFilter.Get())
+	})
+
+	// Update counter
+	itemsLeft := document.GetElementById("items-left")
+	dom.NewEffect(func() {
+		itemsLeft.SetText(itemsLeftText.Get())
+	})
+
+	// Show/hide clear completed button
+	clearCompletedBtn := document.GetElementById("clear-completed")
+	dom.NewEffect(func() {
+		if hasCompleted.Get() {
+			clearCompletedBtn.Style().Display("inline-block")
+		} else {
+			clearCompletedBtn.Style().Display("none")
+		}
+	})
+
+	// Hand the filtered todos to the list component, which re-renders once
+	// per frame however many changes were made
+	dom.NewEffect(func() {
+		todoList.Props.Todos = visibleTodos.Get()
+		todoList.Invalidate()
+	})
+
+	// Highlight the current filter (not every filter has a button)
+	dom.NewEffect(func() {
+		filterBar.Props.Filter = currentFilter.Get()
+		filterBar.Invalidate()
+	})
+
+	// Show/hide empty state message
+	emptyState := document.GetElementById("empty-state")
+	dom.NewEffect(func() {
+		if len(todos.Get()) == 0 {
+			emptyState.Style().Display("block")
+			emptyState.AnimateWithOptions("fadeIn", 300)
+		} else {
+			emptyState.Style().Display("none")
+		}
+	})
+}
+
+/**
+ * Move the todo at fromIndex among the todos shown to toIndex. Todos hidden
+ * by the filter keep their place relative to the todo it lands next to
+ */
+func moveTodo(fromIndex, toIndex int, id string) {
+	shown := todoList.Props.Todos
+	if fromIndex == toIndex || fromIndex < 0 || fromIndex >= len(shown) || toIndex < 0 || toIndex >= len(shown) || shown[fromIndex].ID != id {
+		return
+	}
+	neighbor := shown[toIndex].ID
+
+	// Take the todo out, then put it before the neighbor when moving up and
+	// after it when moving down
+	list := append([]Todo(nil), todos.Peek()...)
+	from := todoIndex(list, id)
+	if from < 0 {
+		return
+	}
+	todo := list[from]
+	list = append(list[:from], list[from+1:]...)
+
+	to := todoIndex(list, neighbor)
+	if fromIndex < toIndex {
+		to++
+	}
+	list = append(list[:to], append([]Todo{todo}, list[to:]...)...)
+
+	for i := range list {
+		list[i].Position = i + 1
+	}
+
+	// Store the new order and slide every todo that moved from its old place
+	dom.Flip(todoList.Element(), dom.FlipOptions{}, func() {
+		todos.Set(list)
+	}).Start()
+
+	dom.Announce(fmt.Sprintf("Moved %s to position %d of %d", todo.Text, toIndex+1, len(shown)), dom.Polite)
+}
+
+/**
+ * Return the index of the todo with the given ID, or -1
+ */
+func todoIndex(list []Todo, id string) int {
+	for i, todo := range list {
+		if todo.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+/**
+ * Mount the components in place of their static placeholders in index.html
+ */
+func mountComponents() {
+	document := dom.Document()
+
+	todoList = NewTodoList(TodoListProps{
+		OnToggle: func(id string) { toggleTodo(id) },
+		OnDelete: func(id string) { deleteTodo(id) },
+		OnEdit:   editTodo,
+		OnMove:   moveTodo,
+		TagHref: func(tag string) string {
+			return router.Href(filterPath("tag:" + tag))
+		},
+		OnEditMode: func(editing bool) {
+			shortcuts.PopScope("edit")
+			if editing {
+				shortcuts.PushScope("edit")
+			}
+		},
+	})
+	dom.Mount(document.GetElementById("todo-list"), todoList)
+
+	filterBar = NewFilterBar(FilterBarProps{
+		Filter:   currentFilter.Peek(),
+		OnSelect: func(filter string) { setFilter(filter) },
+	})
+	dom.Mount(document.QuerySelector(".filters"), filterBar)
+
+	settingsPanel = NewSettingsPanel(SettingsPanelProps{
+		Themes:  themes.Themes(),
+		OnClose: toggleSettings,
+	})
+	dom.Mount(document.GetElementById("settings-panel"), settingsPanel)
+	form, err := dom.BindForm(settingsPanel.Element(), preferences)
+	if err != nil {
+		// The rest of the app works without the settings panel
+		dom.ReportError(err, "settings form")
+	}
+	settingsForm = form
+	themes.SyncPicker(settingsPanel.Element())
+}
+
+/**
+ * Load user preferences from storage
+ */
+func loadPreferences() {
+	// Load filter preference
+	filter := storage.GetItem(filterKey)
+	if filter != "" {
+		currentFilter.Set(filter)
+	}
+
+	// Load the settings, falling back to the keys of older versions
+	prefs := preferences.Peek()
+	prefs.ColorMode = ""
+	if storage.GetItem(preferencesKey) != "" {
+		storage.GetJSON(preferencesKey, &prefs)
+	} else {
+		if theme := storage.GetItem(themeKey); theme != "" {
+			prefs.Theme = theme
+		}
+		if animSpeed := storage.GetItem(animSpeedKey); animSpeed != "" {
+			prefs.AnimationSpeed = animSpeed
+		}
+		if fontSize := storage.GetItem(fontSizeKey); fontSize != "" {
+			prefs.FontSize = fontSize
+		}
+	}
+	if prefs.ColorMode == "" {
+		prefs.ColorMode = string(dom.SystemMode)
+		if storage.GetItem(darkModeKey) != "" {
+			prefs.ColorMode = string(dom.LightMode)
+			if storage.GetBool(darkModeKey, false) {
+				prefs.ColorMode = string(dom.DarkMode)
+			}
+		}
+	}
+	preferences.Set(prefs)
+}
+
+/**
+ * Make the named theme current and save it with the other settings. Names
+ * that are not registered are refused and leave the settings unchanged
+ */
+func setTheme(name string) error {
+	if _, ok := themes.Lookup(name); !ok {
+		return fmt.Errorf("%w %q", dom.ErrUnknownTheme, name)
+	}
+
+	preferences.Update(func(prefs Preferences) Preferences {
+		prefs.Theme = name
+		return prefs
+	})
+	return nil
+}
+
+/**
+ * Switch to light mode when the page is dark and to dark mode otherwise,
+ * leaving the system setting behind. Returns whether the page is now dark
+ */
+func toggleDarkMode() bool {
+	mode := dom.DarkMode
+	if themes.IsDark() {
+		mode = dom.LightMode
+	}
+
+	preferences.Update(func(prefs Preferences) Preferences {
+		prefs.ColorMode = string(mode)
+		return prefs
+	})
+	return themes.IsDark()
+}
+
+/**
+ * Set up all event listeners
+ */
+func setupEventListeners() {
+	document := dom.Document()
+
+	// Add todo button
+	addButton := document.GetElementById("add-todo")
+	addButton.AddEventListener("click", func() {
+		handleAddTodo()
+	})
+
+	// Enter key on input field
+	newTodoInput := document.GetElementById("new-todo")
+	newTodoInput.AddEventListenerWithEvent("keypress", func(event dom.Event) {
+		if event.Keyboard().Key() == "Enter" {
+			handleAddTodo()
+		}
+	})
+
+	// Clear completed button
+	clearButton := document.GetElementById("clear-completed")
+	clearButton.AddEventListener("click", func() {
+		clearCompleted()
+	})
+
+	// Theme toggle button
+	themeBtn := document.GetElementById("theme-toggle")
+	themeBtn.AddEventListener("click", func() {
+		toggleDarkMode()
+	})
+
+	// Settings button
+	settingsBtn := document.GetElementById("settings-toggle")
+	settingsBtn.AddEventListener("click", func() {
+		toggleSettings()
+	})
+
+	setupShortcuts()
+}
+
+/**
+ * Declare the keyboard shortcuts. The list ones are left out while a todo is
+ * edited, and none of them fire while typing in the new todo field, where
+ * Ctrl+A selects the text as usual
+ */
+func setupShortcuts() {
+	shortcuts = dom.NewShortcutManager()
+	shortcuts.PushScope("list")
+
+	shortcuts.
+		Bind("?", "Show keyboard shortcuts", func(dom.Event) { shortcuts.ToggleHelp() }).
+		Bind("n", "Focus the new todo field", func(dom.Event) {
+			dom.Document().GetElementById("new-todo").Focus()
+		}).
+		Bind("escape", "Close settings", func(dom.Event) {
+			if settingsPanel.Props.Open {
+				toggleSettings()
+			}
+		})
+
+	for _, filter := range []struct{ keys, name string }{
+		{"g a", "all"}, {"g t", "active"}, {"g c", "completed"}, {"g p", "priority"},
+	} {
+		shortcuts.Add(dom.Shortcut{
+			Keys:        filter.keys,
+			Description: "Show " + filter.name + " todos",
+			Group:       "Filters",
+			Handler:     func(dom.Event) { setFilter(filter.name) },
+		})
+	}
+
+	// The list and the edit field handle the keys that have no handler here
+	for _, shortcut := range []dom.Shortcut{
+		{Keys: "mod+a", Description: "Mark all todos as completed or active", Handler: func(dom.Event) { toggleAllTodos() }},
+		{Keys: "down", Description: "Move to the next todo"},
+		{Keys: "up", Description: "Move to the previous todo"},
+		{Keys: "alt+down", Description: "Move the focused todo down"},
+		{Keys: "alt+up", Description: "Move the focused todo up"},
+	} {
+		shortcut.Group, shortcut.Scope = "List", "list"
+		shortcuts.Add(shortcut)
+	}
+	for _, shortcut := range []dom.Shortcut{
+		{Keys: "enter", Description: "Save the todo"},
+		{Keys: "escape", Description: "Cancel editing"},
+	} {
+		shortcut.Group, shortcut.Scope = "Editing", "edit"
+		shortcuts.Add(shortcut)
+	}
+}
+
+/**
+ * Toggle settings panel
+ */
+func toggleSettings() {
+	settingsPanel.SetOpen(!settingsPanel.Props.Open)
+	dom.Document().GetElementById("settings-toggle").SetAttribute("aria-expanded", strconv.FormatBool(settingsPanel.Props.Open))
+}
+
+/**
+ * Add a new todo from the input field
+ */
+func handleAddTodo() bool {
+	document := dom.Document()
+	input := document.GetElementById("new-todo")
+	text := input.GetValue()
+
+	// Trim the text
+	text = strings.TrimSpace(text)
+
+	if text != "" {
+		success := addTodo(text)
+
+		// Clear input field with animation
+		dom.Sequence(
+			input.Preset("fadeOut", 150),
+			dom.Do(func() { input.SetValue("") }),
+			input.Preset("fadeIn", 150),
+		).Then(func() {
+			input.Focus()
+		})
+
+		return success
+	}
+
+	return false
+}
+
+/**
+ * Migrate todo schema between versions
+ */
+func migrateTodoSchema(fromVersion, toVersion int) error {
+	fmt.Printf("Migrating todos from schema version %d to %d\n", fromVersion, toVersion)
+
+	// Get the current todos
+	var oldTodos []map[string]interface{}
+	err := storage.GetJSON(todosKey, &oldTodos)
+	if err != nil {
+		return err
+	}
+
+	// If no todos, nothing to migrate
+	if oldTodos == nil || len(oldTodos) == 0 {
+		return nil
+	}
+
+	// Migrate from version 0 or 1 to version 2
+	if fromVersion < 2 && toVersion >= 2 {
+		newTodos := []Todo{}
+
+		// For each todo, add the new fields. Malformed entries are skipped
+		for i, oldTodo := range oldTodos {
+			id, ok := oldTodo["id"].(string)
+			if !ok || id == "" {
+				continue
+			}
+			text, _ := oldTodo["text"].(string)
+			completed, _ := oldTodo["completed"].(bool)
+			createdAt, _ := oldTodo["createdAt"].(float64)
+
+			newTodo := Todo{
+				ID:        id,
+				Text:      text,
+				Completed: completed,
+				CreatedAt: int64(createdAt),
+				Position:  i,          // Default to current position
+				Priority:  0,          // Default priority
+				Tags:      []string{}, // Default tags
+			}
+
+			newTodos = append(newTodos, newTodo)
+		}
+
+		// Save the migrated todos
+		todos.Set(newTodos)
+		saveTodos(newTodos)
+	}
+
+	fmt.Println("Migration complete")
+	return nil
+}
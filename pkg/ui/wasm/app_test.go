@@ -0,0 +1,325 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorgasm/internal/dom"
+)
+
+// TestMain loads index.html into the in-memory document and starts the app
+// once for every test
+func TestMain(m *testing.M) {
+	html, err := os.ReadFile("../../../static/index.html")
+	if err != nil {
+		panic(err)
+	}
+	page := string(html)
+	body := page[strings.Index(page, "<body>")+len("<body>"):]
+	if end := strings.Index(body, "</body>"); end >= 0 {
+		body = body[:end]
+	}
+	dom.Document().QuerySelector("body").SetHTML(body)
+
+	initialize()
+	os.Exit(m.Run())
+}
+
+// settle runs the timers, animations and frames the last change started
+func settle() {
+	backend := dom.CurrentBackend().(*dom.MemoryBackend)
+	for i := 0; i < 3; i++ {
+		backend.RunTimers()
+		dom.FlushFrame()
+	}
+}
+
+// reset empties the list and shows every todo
+func reset(t *testing.T) {
+	t.Helper()
+	todos.Set([]Todo{})
+	setFilter("all")
+	settle()
+}
+
+// renderedTexts returns the text of the todos shown in the list
+func renderedTexts() []string {
+	texts := []string{}
+	for _, item := range todoList.Element().QuerySelectorAll("li[data-id] .todo-text") {
+		texts = append(texts, item.GetText())
+	}
+	return texts
+}
+
+// add adds todos, one per text, and returns their IDs
+func add(t *testing.T, texts ...string) []string {
+	t.Helper()
+	var ids []string
+	for _, text := range texts {
+		if !addTodo(text) {
+			t.Fatalf("addTodo(%q) failed", text)
+		}
+		list := todos.Peek()
+		ids = append(ids, list[len(list)-1].ID)
+	}
+	settle()
+	return ids
+}
+
+func TestAddTodoRendersItem(t *testing.T) {
+	reset(t)
+	add(t, "Buy milk !! #shop")
+
+	todo := todos.Peek()[0]
+	if todo.Text != "Buy milk" || todo.Priority != 2 || !reflect.DeepEqual(todo.Tags, []string{"shop"}) {
+		t.Errorf("todo = %+v, want text, priority and tag parsed", todo)
+	}
+	if got := renderedTexts(); !reflect.DeepEqual(got, []string{"Buy milk"}) {
+		t.Errorf("rendered %v", got)
+	}
+	if got := dom.Document().GetElementById("items-left").GetText(); !strings.HasPrefix(got, "1 ") {
+		t.Errorf("items left = %q", got)
+	}
+}
+
+func TestAddTodoRejectsEmptyText(t *testing.T) {
+	reset(t)
+	if addTodo("") {
+		t.Error("addTodo accepted empty text")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	reset(t)
+	ids := add(t, "one", "two", "three !")
+	toggleTodo(ids[0])
+	settle()
+
+	for _, test := range []struct {
+		filter string
+		want   []string
+		path   string
+	}{
+		{"active", []string{"two", "three"}, "/active"},
+		{"completed", []string{"one"}, "/completed"},
+		{"priority", []string{"three"}, "/priority"},
+		{"all", []string{"one", "two", "three"}, "/"},
+	} {
+		setFilter(test.filter)
+		settle()
+
+		if got := renderedTexts(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("filter %s rendered %v, want %v", test.filter, got, test.want)
+		}
+		if router.Path() != test.path {
+			t.Errorf("filter %s routed to %s, want %s", test.filter, router.Path(), test.path)
+		}
+	}
+}
+
+func TestUnknownFilterShowsAll(t *testing.T) {
+	reset(t)
+	if got := setFilter("bogus"); got != "all" {
+		t.Errorf("setFilter returned %q, want all", got)
+	}
+}
+
+func TestTodosRoundTripThroughStorage(t *testing.T) {
+	reset(t)
+	ids := add(t, "first #a", "second !!!")
+	toggleTodo(ids[1])
+	settle()
+	want := todos.Peek()
+
+	// The raw localStorage entry holds the list as JSON
+	var saved []Todo
+	if err := json.Unmarshal([]byte(dom.LocalStorage().GetItem(todosKey)), &saved); err != nil {
+		t.Fatalf("stored todos: %v", err)
+	}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("stored %+v, want %+v", saved, want)
+	}
+
+	loadTodos()
+	if got := todos.Peek(); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadTodosSortsByPosition(t *testing.T) {
+	reset(t)
+	saveTodos([]Todo{
+		{ID: "b", Text: "b", Position: 2, Tags: []string{}},
+		{ID: "a", Text: "a", Position: 1, Tags: []string{}},
+	})
+	loadTodos()
+	settle()
+
+	if got := renderedTexts(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("rendered %v, want [a b]", got)
+	}
+}
+
+func TestDeleteAndClearCompleted(t *testing.T) {
+	reset(t)
+	ids := add(t, "one", "two", "three")
+
+	deleteTodo(ids[0])
+	settle()
+	toggleTodo(ids[1])
+	settle()
+	if got := clearCompleted(); got != 1 {
+		t.Errorf("clearCompleted = %d, want 1", got)
+	}
+	settle()
+
+	if got := renderedTexts(); !reflect.DeepEqual(got, []string{"three"}) {
+		t.Errorf("rendered %v, want [three]", got)
+	}
+}
+
+func TestEditTodo(t *testing.T) {
+	reset(t)
+	ids := add(t, "old")
+
+	if editTodo(ids[0], "") {
+		t.Error("editTodo accepted empty text")
+	}
+	if !editTodo(ids[0], "new !!! #work") {
+		t.Fatal("editTodo failed")
+	}
+	settle()
+
+	todo := todos.Peek()[0]
+	if todo.Text != "new" || todo.Priority != 3 || !reflect.DeepEqual(todo.Tags, []string{"work"}) {
+		t.Errorf("todo = %+v", todo)
+	}
+	if got := renderedTexts(); !reflect.DeepEqual(got, []string{"new"}) {
+		t.Errorf("rendered %v", got)
+	}
+}
+
+func TestToggleTodoSavesAtOnce(t *testing.T) {
+	reset(t)
+	ids := add(t, "one")
+
+	toggleTodo(ids[0])
+
+	// No animation has run yet: the state and storage already changed
+	if !todos.Peek()[0].Completed {
+		t.Error("todo not completed right after toggleTodo")
+	}
+	var saved []Todo
+	storage.GetJSON(todosKey, &saved)
+	if len(saved) != 1 || !saved[0].Completed {
+		t.Errorf("stored %+v, want the todo completed", saved)
+	}
+}
+
+func TestToggleAllKeepsChangesMadeDuringTheAnimation(t *testing.T) {
+	reset(t)
+	add(t, "one")
+
+	if got := toggleAllTodos(); got != 1 {
+		t.Errorf("toggleAllTodos = %d, want 1", got)
+	}
+	addTodo("two")
+	settle()
+
+	list := todos.Peek()
+	if len(list) != 2 || !list[0].Completed || list[1].Completed {
+		t.Errorf("todos = %+v, want one completed and two active", list)
+	}
+}
+
+func TestListReportsNodesAddedByOtherScripts(t *testing.T) {
+	reset(t)
+	add(t, "one")
+	var reports []dom.ErrorReport
+	t.Cleanup(dom.AddReporter(dom.ReporterFunc(func(report dom.ErrorReport) {
+		reports = append(reports, report)
+	})))
+
+	foreign := dom.Document().CreateElement("div")
+	todoList.Element().AppendChild(foreign)
+	settle()
+	add(t, "two")
+
+	if !foreign.El.Get("isConnected").Bool() {
+		t.Error("foreign node removed from the list")
+	}
+	if len(reports) != 1 || reports[0].Source != "todo list" {
+		t.Errorf("reports = %+v, want the foreign node", reports)
+	}
+	if got := renderedTexts(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("rendered %v, want [one two]", got)
+	}
+}
+
+func TestSetThemeRefusesUnknownNames(t *testing.T) {
+	t.Cleanup(func() { setTheme("blue") })
+
+	if err := setTheme("pink"); !errors.Is(err, dom.ErrUnknownTheme) {
+		t.Errorf("err = %v, want ErrUnknownTheme", err)
+	}
+	if got := preferences.Peek().Theme; got != "blue" {
+		t.Errorf("stored theme %q after an unknown name", got)
+	}
+
+	if err := setTheme("green"); err != nil {
+		t.Fatal(err)
+	}
+	var saved Preferences
+	storage.GetJSON(preferencesKey, &saved)
+	if themes.Current() != "green" || saved.Theme != "green" {
+		t.Errorf("current %s, stored %s, want green", themes.Current(), saved.Theme)
+	}
+}
+
+func TestUnknownStoredThemeIsReported(t *testing.T) {
+	saved := preferences.Peek()
+	t.Cleanup(func() { preferences.Set(saved) })
+	var reports []dom.ErrorReport
+	t.Cleanup(dom.AddReporter(dom.ReporterFunc(func(report dom.ErrorReport) {
+		reports = append(reports, report)
+	})))
+
+	// Such as a theme saved by a version that had it
+	preferences.Update(func(prefs Preferences) Preferences {
+		prefs.Theme = "pink"
+		prefs.ColorMode = string(dom.DarkMode)
+		return prefs
+	})
+
+	if len(reports) != 1 || !errors.Is(reports[0].Err, dom.ErrUnknownTheme) {
+		t.Errorf("reports = %+v, want the unknown theme", reports)
+	}
+	if themes.Mode() != dom.DarkMode {
+		t.Errorf("mode %s, want the stored mode applied anyway", themes.Mode())
+	}
+}
+
+func TestTodoListUnmountStopsArrowNavigation(t *testing.T) {
+	placeholder := dom.Document().CreateElement("ul")
+	dom.Document().QuerySelector("body").AppendChild(placeholder)
+	list := NewTodoList(TodoListProps{Todos: []Todo{{ID: "a", Text: "one"}}})
+	element := dom.Mount(placeholder, list)
+	t.Cleanup(func() { dom.Unmount(list) })
+
+	// The hook alone, as run before the list's DOM goes away
+	list.Unmount()
+	row := dom.Document().CreateElement("li").SetAttribute("data-id", "b")
+	element.AppendChild(row)
+	settle()
+
+	if row.HasAttribute("tabindex") {
+		t.Error("rows still managed after the list unmounted")
+	}
+}
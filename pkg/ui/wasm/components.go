@@ -0,0 +1,628 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"gorgasm/internal/dom"
+)
+
+// TodoItemProps configures a TodoItem
+type TodoItemProps struct {
+	Todo    Todo // Todo to display
+	Index   int  // Position in the list, used to stagger the entry animation
+	Editing bool // Whether the edit input is shown
+	Reveal  bool // Scroll the row into view once it is in the document
+	Movable bool // Whether the row shows a drag handle and can be moved with the keyboard
+
+	TagHref func(tag string) string // Link to the todos with a tag
+}
+
+// TodoItem renders a single row of the todo list
+type TodoItem struct {
+	dom.Base[TodoItemProps]
+}
+
+// TodoListProps configures a TodoList
+type TodoListProps struct {
+	Todos      []Todo                                  // Todos to display, already filtered
+	OnToggle   func(id string)                         // Checkbox toggled
+	OnDelete   func(id string)                         // Delete button clicked
+	OnEdit     func(id, text string) bool              // Edit submitted, returns false to keep editing
+	OnMove     func(fromIndex, toIndex int, id string) // Todo dragged or moved with Alt+Up/Down, indices into Todos
+	TagHref    func(tag string) string                 // Link to the todos with a tag
+	OnEditMode func(editing bool)                      // Edit mode entered or left
+}
+
+// TodoList renders the todo list and owns which todo is being edited
+type TodoList struct {
+	dom.Base[TodoListProps]
+
+	editing string                    // ID of the todo being edited
+	reveal  string                    // ID of a todo to scroll into view on the next render
+	limit   int                       // Number of todos rendered so far
+	more    *dom.IntersectionObserver // Renders more todos when the end of the list shows
+
+	roving   *dom.RovingTabindex // Arrow key navigation between the rows
+	sortable *dom.Sortable       // Drag and drop and Alt+arrow reordering
+}
+
+// todoPageSize is the number of todos the list renders at a time. Long lists
+// render the next page when the user scrolls near the end
+const todoPageSize = 50
+
+// FilterBarProps configures a FilterBar
+type FilterBarProps struct {
+	Filter   string              // Active filter
+	OnSelect func(filter string) // Filter button clicked
+}
+
+// FilterBar renders the filter buttons in the footer
+type FilterBar struct {
+	dom.Base[FilterBarProps]
+}
+
+// SettingsPanelProps configures a SettingsPanel
+type SettingsPanelProps struct {
+	Open    bool        // Whether the panel is shown
+	Themes  []dom.Theme // Color themes offered as swatches
+	OnClose func()
+}
+
+// SettingsPanel renders the slide-out settings panel
+type SettingsPanel struct {
+	dom.Base[SettingsPanelProps]
+
+	wide bool           // Panel is wide enough to show the sections side by side
+	trap *dom.FocusTrap // Keeps focus inside the panel while it is open
+}
+
+// settingsWideWidth is the panel width from which sections go side by side
+const settingsWideWidth = 600
+
+// filterButtons lists the filters that have a button, in display order
+var filterButtons = []struct{ filter, label string }{
+	{"all", "All"},
+	{"active", "Active"},
+	{"completed", "Completed"},
+}
+
+// colorModes lists the color modes offered in the settings panel
+var colorModes = []struct {
+	mode  dom.ColorMode
+	label string
+}{
+	{dom.LightMode, "Light"},
+	{dom.DarkMode, "Dark"},
+	{dom.SystemMode, "System"},
+}
+
+/**
+ * Create a todo item component
+ */
+func NewTodoItem(props TodoItemProps) *TodoItem {
+	item := &TodoItem{}
+	item.Props = props
+	return item
+}
+
+/**
+ * Describe the todo row
+ */
+func (t *TodoItem) Render() *dom.VNode {
+	todo := t.Props.Todo
+	editing := t.Props.Editing
+
+	// Tags, if present
+	var tags *dom.VNode
+	if len(todo.Tags) > 0 {
+		tags = dom.H("div", dom.Attrs{"class": "todo-tags"})
+		for _, tag := range todo.Tags {
+			if t.Props.TagHref != nil {
+				tags.Children = append(tags.Children, dom.H("a", dom.Attrs{"class": "todo-tag", "href": t.Props.TagHref(tag)}, dom.Text("#"+tag)))
+			} else {
+				tags.Children = append(tags.Children, dom.H("span", dom.Attrs{"class": "todo-tag"}, dom.Text("#"+tag)))
+			}
+		}
+	}
+
+	// The drag handle, only when the list can be reordered
+	var handle *dom.VNode
+	movable := dom.Attrs{}
+	if t.Props.Movable {
+		handle = dom.H("span", dom.Attrs{"class": "drag-handle", "aria-hidden": "true"}, dom.Text("⠿"))
+		movable["aria-keyshortcuts"] = "Alt+ArrowUp Alt+ArrowDown"
+	}
+
+	// The text and delete button are hidden while editing
+	hidden := dom.Attrs{}
+	if editing {
+		hidden["style"] = "display: none"
+	}
+
+	item := dom.H("li",
+		dom.Class("completed", todo.Completed),
+		dom.Class(fmt.Sprintf("priority-%d", todo.Priority), todo.Priority > 0),
+		dom.Attrs{
+			"data-id":       todo.ID,
+			"data-position": strconv.Itoa(todo.Position),
+		},
+
+		movable,
+
+		// Grip for dragging
+		handle,
+
+		// Checkbox with custom styling
+		dom.H("input",
+			dom.Attrs{"type": "checkbox", "class": "toggle", "data-id": todo.ID},
+			dom.Props{"checked": todo.Completed},
+		),
+
+		// Text and tags
+		dom.H("div", dom.Attrs{"class": "text-container"},
+			dom.H("span", dom.Attrs{"class": "todo-text"}, hidden, dom.Text(todo.Text)),
+			tags,
+		),
+
+		// Edit and delete buttons
+		dom.H("div", dom.Attrs{"class": "button-container"},
+			dom.H("button", dom.Attrs{"class": "edit", "data-id": todo.ID}, dom.Text("✎")),
+			dom.H("button", dom.Attrs{"class": "delete", "data-id": todo.ID}, hidden, dom.Text("×")),
+		),
+	)
+
+	if editing {
+		item.Children = append(item.Children, editTodoNodes(todo)...)
+	}
+
+	return item
+}
+
+/**
+ * Play the staggered entry animation once the row is in the document
+ */
+func (t *TodoItem) Mount() {
+	element := t.Element()
+
+	if t.Props.Reveal {
+		element.ScrollIntoViewWithOptions(dom.ScrollIntoViewOptions{Behavior: "smooth", Block: "nearest"})
+	}
+
+	delay := t.Props.Index * 50 // staggered delay
+	if delay > 500 {            // cap maximum delay
+		delay = 500
+	}
+
+	window := dom.GetWindow()
+	window.SetTimeout(func() {
+		element.AnimateWithOptions("slideIn", 300)
+	}, delay)
+}
+
+/**
+ * Describe the edit input and hint shown while a todo is being edited
+ */
+func editTodoNodes(todo Todo) []*dom.VNode {
+	return []*dom.VNode{
+		dom.H("input",
+			dom.Attrs{"type": "text", "class": "edit-todo", "data-id": todo.ID},
+			dom.Props{"value": formatTodoForEdit(todo)},
+			dom.OnCreate(func(element dom.Element) {
+				// Focus the input and make sure it can be seen
+				element.Focus()
+				element.ScrollIntoViewWithOptions(dom.ScrollIntoViewOptions{Behavior: "smooth", Block: "nearest"})
+			}),
+		),
+
+		// Add a hint about editing
+		dom.H("small", dom.Attrs{"class": "edit-hint"},
+			dom.Text("Press Enter to save, Esc to cancel. Use ! for priority, #tag for tags"),
+		),
+	}
+}
+
+/**
+ * Create a todo list component
+ */
+func NewTodoList(props TodoListProps) *TodoList {
+	list := &TodoList{limit: todoPageSize}
+	list.Props = props
+	return list
+}
+
+/**
+ * Describe the list with one TodoItem per todo
+ */
+func (t *TodoList) Render() *dom.VNode {
+	// Render enough pages to include the todo to reveal
+	reveal := t.reveal
+	t.reveal = ""
+	for i, todo := range t.Props.Todos {
+		if todo.ID == reveal && i >= t.limit {
+			t.limit = (i/todoPageSize + 1) * todoPageSize
+		}
+	}
+
+	todos := t.Props.Todos
+	if len(todos) > t.limit {
+		todos = todos[:t.limit]
+	}
+
+	items := make([]*dom.VNode, 0, len(todos)+1)
+	for i, todo := range todos {
+		items = append(items, dom.ComponentNode(todo.ID, NewTodoItem(TodoItemProps{
+			Todo:    todo,
+			Index:   i + 1,
+			Editing: todo.ID == t.editing,
+			Reveal:  todo.ID == reveal,
+			Movable: t.Props.OnMove != nil,
+			TagHref: t.Props.TagHref,
+		})))
+	}
+
+	// Sentinel that loads the next page once it scrolls into view. Its key
+	// changes with every page so each page gets a fresh observation
+	if len(t.Props.Todos) > t.limit {
+		items = append(items, dom.H("li",
+			dom.Key("more-"+strconv.Itoa(t.limit)),
+			dom.Attrs{"class": "todo-sentinel", "aria-hidden": "true"},
+			dom.OnCreate(func(element dom.Element) {
+				t.more.Observe(element)
+			}),
+		))
+	}
+
+	return dom.H("ul", dom.Attrs{"id": "todo-list"}, dom.Children(items))
+}
+
+/**
+ * Start editing a todo
+ */
+func (t *TodoList) StartEdit(id string) {
+	t.editing = id
+	t.Update()
+	t.editModeChanged()
+}
+
+/**
+ * Leave edit mode without saving
+ */
+func (t *TodoList) CancelEdit() {
+	t.editing = ""
+	t.Update()
+	t.editModeChanged()
+}
+
+/**
+ * Scroll a todo into view once it has been rendered. Todos hidden by the
+ * current filter are ignored
+ */
+func (t *TodoList) Reveal(id string) {
+	t.reveal = id
+	t.Invalidate()
+}
+
+/**
+ * Return the ID of the todo being edited, if any
+ */
+func (t *TodoList) Editing() string {
+	return t.editing
+}
+
+/**
+ * Save the edit input of a todo and leave edit mode if it was accepted
+ */
+func (t *TodoList) saveEdit(id, text string) {
+	t.editing = ""
+	if t.Props.OnEdit != nil && !t.Props.OnEdit(id, text) {
+		t.editing = id
+		return
+	}
+	t.Invalidate()
+	t.editModeChanged()
+}
+
+/**
+ * Tell the owner whether a todo is being edited
+ */
+func (t *TodoList) editModeChanged() {
+	if t.Props.OnEditMode != nil {
+		t.Props.OnEditMode(t.editing != "")
+	}
+}
+
+/**
+ * Set up delegated listeners on the list. Each event type gets a single
+ * listener regardless of how many todos are rendered
+ */
+func (t *TodoList) Mount() {
+	todoList := t.Element()
+
+	// Render the next page when the sentinel gets near the viewport
+	t.more = dom.NewIntersectionObserver(func(entries []dom.IntersectionEntry, observer *dom.IntersectionObserver) {
+		for _, entry := range entries {
+			if entry.IsIntersecting {
+				observer.Unobserve(entry.Target)
+				t.limit += todoPageSize
+				t.Invalidate()
+			}
+		}
+	}, dom.IntersectionObserverOptions{RootMargin: "200px 0px"})
+	t.Scope().OnRelease(t.more.Disconnect)
+
+	// The sentinel of the first render was created before the observer
+	if sentinel := todoList.QuerySelector(".todo-sentinel"); sentinel.Exists() {
+		t.more.Observe(sentinel)
+	}
+
+	// The list owns its rows. Nodes added by other scripts, such as browser
+	// extensions, can throw off the keyed patching and the row indices used
+	// for reordering. They are not ours to delete, so they are reported
+	watcher := dom.NewMutationObserver(func(records []dom.MutationRecord, _ *dom.MutationObserver) {
+		for _, record := range records {
+			for _, node := range record.AddedNodes {
+				if node.El.Get("nodeType").Int() != 1 || node.HasAttribute("data-id") || node.Matches(".todo-sentinel, .sortable-placeholder") {
+					continue
+				}
+				dom.ReportError(fmt.Errorf("<%s> added by another script", node.El.Get("localName").String()), "todo list")
+			}
+		}
+	})
+	watcher.Observe(todoList, dom.MutationObserverOptions{ChildList: true})
+	t.Scope().OnRelease(watcher.Disconnect)
+
+	todoList.On("change", ".toggle", func(checkbox dom.Element, _ dom.Event) {
+		if t.Props.OnToggle != nil {
+			t.Props.OnToggle(checkbox.GetAttribute("data-id"))
+		}
+	})
+
+	todoList.On("click", ".delete", func(button dom.Element, _ dom.Event) {
+		if t.Props.OnDelete != nil {
+			t.Props.OnDelete(button.GetAttribute("data-id"))
+		}
+	})
+
+	todoList.On("click", ".edit", func(button dom.Element, _ dom.Event) {
+		t.StartEdit(button.GetAttribute("data-id"))
+	})
+
+	// Double click on text to edit
+	todoList.On("dblclick", ".text-container", func(container dom.Element, _ dom.Event) {
+		t.StartEdit(container.Closest("li").GetAttribute("data-id"))
+	})
+
+	// Edit input
+	todoList.On("keydown", ".edit-todo", func(input dom.Element, event dom.Event) {
+		key := event.Keyboard().Key()
+
+		if key == "Enter" {
+			// Save changes
+			t.saveEdit(input.GetAttribute("data-id"), input.GetValue())
+		} else if key == "Escape" {
+			// Cancel edit
+			t.CancelEdit()
+		}
+	})
+
+	todoList.On("blur", ".edit-todo", func(input dom.Element, _ dom.Event) {
+		// Save on blur if not already canceled
+		id := input.GetAttribute("data-id")
+		if t.editing == id {
+			t.saveEdit(id, input.GetValue())
+		}
+	})
+
+	// Make the list a single tab stop whose rows are reached with the arrow
+	// keys, Home and End
+	t.roving = dom.NewRovingTabindex(todoList, "li[data-id]", dom.RovingOptions{})
+
+	// Drag todos by their handle with a mouse, pen or finger, or move the
+	// focused todo with Alt+Up and Alt+Down
+	t.sortable = dom.NewSortable(todoList, "li[data-id]", dom.SortableOptions{
+		Handle: ".drag-handle",
+		OnSort: func(fromIndex, toIndex int, id string) {
+			if t.Props.OnMove != nil {
+				t.Props.OnMove(fromIndex, toIndex, id)
+			}
+		},
+	})
+}
+
+/**
+ * Stop the arrow key navigation and the sorting set up in Mount
+ */
+func (t *TodoList) Unmount() {
+	t.roving.Release()
+	t.sortable.Release()
+}
+
+/**
+ * Create a filter bar component
+ */
+func NewFilterBar(props FilterBarProps) *FilterBar {
+	bar := &FilterBar{}
+	bar.Props = props
+	return bar
+}
+
+/**
+ * Describe the filter buttons, highlighting the active one
+ */
+func (f *FilterBar) Render() *dom.VNode {
+	buttons := make([]*dom.VNode, 0, len(filterButtons))
+	for _, button := range filterButtons {
+		filter := button.filter
+
+		buttons = append(buttons, dom.H("button",
+			dom.Key(filter),
+			dom.Attrs{"data-filter": filter},
+			dom.Class("active", filter == f.Props.Filter),
+			dom.On("click", func(dom.Event) {
+				if f.Props.OnSelect != nil {
+					f.Props.OnSelect(filter)
+				}
+			}),
+			dom.Text(button.label),
+		))
+	}
+
+	return dom.H("div", dom.Attrs{"class": "filters"}, dom.Children(buttons))
+}
+
+/**
+ * Create a settings panel component
+ */
+func NewSettingsPanel(props SettingsPanelProps) *SettingsPanel {
+	panel := &SettingsPanel{}
+	panel.Props = props
+	return panel
+}
+
+/**
+ * Lay the sections out side by side whenever the panel is wide, which is the
+ * case when it takes the full width of a small screen
+ */
+func (s *SettingsPanel) Mount() {
+	observer := dom.NewResizeObserver(func(entries []dom.ResizeEntry, _ *dom.ResizeObserver) {
+		for _, entry := range entries {
+			wide := entry.Width >= settingsWideWidth
+			if wide != s.wide {
+				s.wide = wide
+				s.Invalidate()
+			}
+		}
+	})
+	observer.Observe(s.Element())
+	s.Scope().OnRelease(observer.Disconnect)
+
+	s.trap = dom.NewFocusTrap(s.Element())
+}
+
+/**
+ * Open or close the panel. While it is open, focus stays inside it; once
+ * closed, focus goes back to where it was
+ */
+func (s *SettingsPanel) SetOpen(open bool) {
+	s.Props.Open = open
+	s.Update()
+
+	if open {
+		s.trap.Activate()
+	} else {
+		s.trap.Deactivate()
+	}
+}
+
+/**
+ * Describe the settings panel. The controls are bound to the preferences
+ * with dom.BindForm, so they are rendered without values or handlers
+ */
+func (s *SettingsPanel) Render() *dom.VNode {
+	props := s.Props
+
+	// Theme swatches, each a radio button of the theme group. The theme
+	// registry marks the current one through data-theme
+	themeOptions := make([]*dom.VNode, 0, len(props.Themes))
+	for _, theme := range props.Themes {
+		label := theme.Label
+		if label == "" {
+			label = theme.Name
+		}
+		themeOptions = append(themeOptions, dom.H("label",
+			dom.Key(theme.Name),
+			dom.Attrs{
+				"class":      "theme-option theme-" + theme.Name,
+				"style":      "background-color: " + theme.Swatch + ";",
+				"title":      label,
+				"data-theme": theme.Name,
+			},
+			dom.H("input", dom.Attrs{"type": "radio", "name": "theme", "value": theme.Name}),
+		))
+	}
+
+	// Light, dark or following the system
+	modeOptions := make([]*dom.VNode, 0, len(colorModes))
+	for _, option := range colorModes {
+		modeOptions = append(modeOptions, dom.H("label",
+			dom.Key(string(option.mode)),
+			dom.Attrs{"class": "mode-option", "data-color-mode": string(option.mode)},
+			dom.H("input", dom.Attrs{"type": "radio", "name": "color-mode", "value": string(option.mode)}),
+			dom.Text(option.label),
+		))
+	}
+
+	animationSpeed := settingsSelect("animation-speed", [][2]string{
+		{"faster", "Faster"},
+		{"normal", "Normal"},
+		{"slower", "Slower"},
+		{"none", "No Animations"},
+	})
+
+	fontSize := settingsSelect("font-size", [][2]string{
+		{"small", "Small"},
+		{"medium", "Medium"},
+		{"large", "Large"},
+	})
+
+	// A closed panel is off screen, so its controls must not be reachable
+	closed := dom.Attrs{}
+	if !props.Open {
+		closed["inert"] = ""
+		closed["aria-hidden"] = "true"
+	}
+
+	return dom.H("div",
+		dom.Attrs{
+			"class":           "settings-panel",
+			"id":              "settings-panel",
+			"role":            "dialog",
+			"aria-modal":      "true",
+			"aria-labelledby": "settings-title",
+		},
+		closed,
+		dom.Class("open", props.Open),
+		dom.Class("settings-wide", s.wide),
+
+		dom.H("button",
+			dom.Attrs{"class": "settings-close", "id": "settings-close", "aria-label": "Close settings"},
+			dom.On("click", func(dom.Event) {
+				if s.Props.OnClose != nil {
+					s.Props.OnClose()
+				}
+			}),
+			dom.Text("×"),
+		),
+		dom.H("h3", dom.Attrs{"id": "settings-title"}, dom.Text("Settings")),
+
+		settingsSection("Themes", "theme", dom.H("div", dom.Attrs{"class": "theme-options"}, dom.Children(themeOptions))),
+		settingsSection("Appearance", "color-mode", dom.H("div", dom.Attrs{"class": "mode-options"}, dom.Children(modeOptions))),
+		settingsSection("Animation Speed", "animation-speed", animationSpeed),
+		settingsSection("Font Size", "font-size", fontSize),
+	)
+}
+
+/**
+ * Describe a titled section of the settings panel with a slot for the
+ * validation message of the named control
+ */
+func settingsSection(title, name string, content *dom.VNode) *dom.VNode {
+	return dom.H("div", dom.Attrs{"class": "settings-section"},
+		dom.H("h4", dom.Text(title)),
+		content,
+		dom.H("small", dom.Attrs{"class": "field-error", "data-error-for": name, "role": "alert"}),
+	)
+}
+
+/**
+ * Describe a select element with the given value/label options
+ */
+func settingsSelect(name string, options [][2]string) *dom.VNode {
+	children := make([]*dom.VNode, 0, len(options))
+	for _, option := range options {
+		children = append(children, dom.H("option", dom.Key(option[0]), dom.Attrs{"value": option[0]}, dom.Text(option[1])))
+	}
+
+	return dom.H("select", dom.Attrs{"id": name, "name": name}, dom.Children(children))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"gorgasm/internal/dom"
+)
+
+// TodoListElement backs the <go-todo-list> tag, which shows the todos on
+// pages that don't use the app's own markup:
+//
+//	<go-todo-list filter="active"></go-todo-list>
+//
+// The filter attribute takes the same values as the filter buttons. Before
+// a todo is toggled or deleted the element dispatches a "todo-toggle" or
+// "todo-delete" CustomEvent whose detail holds the todo's id; calling
+// preventDefault on it keeps the todo as it is
+type TodoListElement struct {
+	dom.ElementBase
+
+	filter *dom.Signal[string] // Todos shown, as in currentFilter
+}
+
+// todoListElementStyles style the list inside the shadow root, where the
+// page's stylesheet does not reach
+const todoListElementStyles = `
+:host { display: block; font-family: inherit; }
+ul { list-style: none; margin: 0; padding: 0; }
+li { display: flex; align-items: center; gap: 0.5rem; padding: 0.5rem 0; border-bottom: 1px solid rgba(0, 0, 0, 0.1); }
+li.completed .todo-text { text-decoration: line-through; opacity: 0.6; }
+.text-container { flex: 1; }
+.todo-tag { margin-right: 0.25rem; font-size: 0.8em; opacity: 0.7; }
+button { border: none; background: none; cursor: pointer; font-size: 1rem; }
+.edit-hint { display: block; font-size: 0.75em; opacity: 0.7; }
+`
+
+/**
+ * Register the custom elements exported by the app
+ */
+func defineElements() {
+	err := dom.DefineElement("go-todo-list", dom.ElementOptions{
+		Observed: []string{"filter"},
+		Shadow:   "open",
+		Styles:   todoListElementStyles,
+	}, func() dom.CustomElement {
+		return &TodoListElement{filter: dom.NewSignal("all")}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+/**
+ * Render the todo list into the shadow root and keep it in step with the todos
+ */
+func (e *TodoListElement) Connected() {
+	list := NewTodoList(TodoListProps{
+		OnToggle: func(id string) {
+			if e.Dispatch("todo-toggle", map[string]interface{}{"id": id}) {
+				toggleTodo(id)
+			}
+		},
+		OnDelete: func(id string) {
+			if e.Dispatch("todo-delete", map[string]interface{}{"id": id}) {
+				deleteTodo(id)
+			}
+		},
+		OnEdit: editTodo,
+	})
+	e.MountComponent(list)
+
+	effect := dom.NewEffect(func() {
+		list.Props.Todos = filterTodos(todos.Get(), e.filter.Get())
+		list.Invalidate()
+	})
+	e.Scope().OnRelease(effect.Stop)
+}
+
+/**
+ * Follow the filter attribute, falling back to all todos for unknown values
+ */
+func (e *TodoListElement) AttributeChanged(name, _, newValue string) {
+	if name != "filter" {
+		return
+	}
+
+	if !validFilter(newValue) {
+		newValue = "all"
+	}
+	e.filter.Set(newValue)
+}
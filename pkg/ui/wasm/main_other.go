@@ -0,0 +1,19 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+/**
+ * Main function outside the browser. The application logic in this package
+ * builds everywhere so it can be tested against the in-memory document, but
+ * the client itself only runs as WebAssembly
+ */
+func main() {
+	fmt.Fprintln(os.Stderr, "the WebAssembly client must be built with GOOS=js GOARCH=wasm")
+	os.Exit(1)
+}
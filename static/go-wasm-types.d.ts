@@ -9,56 +9,68 @@ declare class Go {
 }
 
 /**
- * Register exported functions for direct calling
+ * Reload the todos from storage
  * @returns {void}
  */
-declare function toggleTodo(): void;
+declare function loadTodos(): void;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Add a todo; "!" sets the priority and "#tag" adds a tag
+ * @param {string} text Text of the todo
+ * @returns {boolean}
  */
-declare function clearCompleted(): void;
+declare function addTodo(text: string): boolean;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Delete a todo
+ * @param {string} id ID of the todo
+ * @returns {boolean}
  */
-declare function toggleAllTodos(): void;
+declare function deleteTodo(id: string): boolean;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Complete every todo, or reopen them all when all are completed
+ * @returns {number}
  */
-declare function toggleDarkMode(): void;
+declare function toggleAllTodos(): number;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Switch between the light and dark themes
+ * @returns {boolean}
  */
-declare function loadTodos(): void;
+declare function toggleDarkMode(): boolean;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Change the color theme. Rejects names that are not registered
+ * @param {string} theme Name of the theme
+ * @returns {Promise<string>}
  */
-declare function addTodo(): void;
+declare function setTheme(theme: string): Promise<string>;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Choose light or dark mode, or follow the system setting
+ * @param {string} mode "light", "dark" or "system"
+ * @returns {boolean}
  */
-declare function setTheme(): void;
+declare function setColorMode(mode: string): boolean;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Toggle the completion status of a todo
+ * @param {string} id ID of the todo
+ * @returns {boolean}
  */
-declare function deleteTodo(): void;
+declare function toggleTodo(id: string): boolean;
 
 /**
- * Register exported functions for direct calling
- * @returns {void}
+ * Delete every completed todo
+ * @returns {number}
+ */
+declare function clearCompleted(): number;
+
+/**
+ * Show the todos matching a filter
+ * @param {string} filter "all", "active", "completed", "priority" or "tag:<name>"
+ * @returns {string}
  */
-declare function setFilter(): void;
+declare function setFilter(filter: string): string;
 